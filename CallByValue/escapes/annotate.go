@@ -0,0 +1,166 @@
+package escapes
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Annotate writes src with the notes for its lines appended as trailing
+// comments, "// [kind] text; [kind] text". With flow, each escape
+// explanation follows its line as comment lines of its own.
+//
+// The result is meant for reading, not compiling: a note appended to a
+// line inside a raw string literal ends up in the string.
+func Annotate(w io.Writer, src []byte, notes []Note, flow bool) error {
+	byLine := map[int][]Note{}
+	for _, n := range notes {
+		byLine[n.Line] = append(byLine[n.Line], n)
+	}
+
+	bw := bufio.NewWriter(w)
+	sc := bufio.NewScanner(bytes.NewReader(src))
+	sc.Buffer(nil, 1<<20)
+	for line := 1; sc.Scan(); line++ {
+		text := sc.Text()
+		ns := byLine[line]
+		if len(ns) == 0 {
+			fmt.Fprintln(bw, text)
+			continue
+		}
+		var parts []string
+		for _, n := range ns {
+			parts = append(parts, fmt.Sprintf("[%s] %s", n.Kind, n.Short()))
+		}
+		fmt.Fprintf(bw, "%s // %s\n", text, strings.Join(parts, "; "))
+		if !flow {
+			continue
+		}
+		indent := text[:len(text)-len(strings.TrimLeft(text, " \t"))]
+		for _, n := range ns {
+			for _, f := range n.Flow {
+				fmt.Fprintf(bw, "%s//   %s\n", indent, f)
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// File is one source file with its notes, as WriteHTML shows it.
+type File struct {
+	Name  string // as displayed
+	Src   []byte
+	Notes []Note
+}
+
+// ReadFiles reads every file under base that has notes, naming each
+// relative to base. Notes about other files are dropped: they come from
+// generic code in the standard library, instantiated by the package.
+func ReadFiles(notes []Note, base string) ([]File, error) {
+	names, byFile := ByFile(notes)
+	var files []File
+	for _, name := range names {
+		rel, err := filepath.Rel(base, name)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		src, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, File{Name: rel, Src: src, Notes: byFile[name]})
+	}
+	return files, nil
+}
+
+// WriteHTML writes a single-file HTML page showing each file with its
+// notes beside the lines they are about, colored by kind, and escape
+// explanations folded under each note.
+func WriteHTML(w io.Writer, title string, files []File) error {
+	type note struct {
+		Kind Kind
+		Text string
+		Full string
+		Flow []string
+	}
+	type line struct {
+		N     int
+		Code  string
+		Notes []note
+	}
+	type count struct {
+		Kind Kind
+		N    int
+	}
+	type file struct {
+		Name   string
+		Counts []count
+		Lines  []line
+	}
+	data := struct {
+		Title string
+		Files []file
+	}{Title: title}
+
+	for _, f := range files {
+		byLine := map[int][]note{}
+		counts := make([]int, len(kindNames))
+		for _, n := range f.Notes {
+			byLine[n.Line] = append(byLine[n.Line], note{n.Kind, n.Short(), n.Text, n.Flow})
+			counts[n.Kind]++
+		}
+		df := file{Name: f.Name}
+		for k, c := range counts {
+			if c > 0 {
+				df.Counts = append(df.Counts, count{Kind(k), c})
+			}
+		}
+		for i, code := range strings.Split(strings.TrimSuffix(string(f.Src), "\n"), "\n") {
+			df.Lines = append(df.Lines, line{i + 1, code, byLine[i+1]})
+		}
+		data.Files = append(data.Files, df)
+	}
+	return htmlTemplate.Execute(w, data)
+}
+
+var htmlTemplate = template.Must(template.New("escapes").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; font-size: 13px; }
+td { vertical-align: top; padding: 0 8px; }
+td.n { color: #999; text-align: right; user-select: none; }
+td.code { font-family: monospace; white-space: pre; tab-size: 4; }
+tr.noted td.code { background: #fafae8; }
+.note { font-family: monospace; white-space: nowrap; margin: 0; }
+.note summary { cursor: pointer; }
+.note .flow { color: #555; padding-left: 2em; white-space: pre; }
+.count { display: inline-block; margin-right: 1em; }
+.heap { color: #b00020; } .leak { color: #b35c00; } .noescape { color: #1b7a2f; }
+.inline { color: #1f5fb0; } .noinline { color: #7a1fb0; } .other { color: #555; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{range .Files}}
+<h2>{{.Name}}</h2>
+<p>{{range .Counts}}<span class="count {{.Kind}}">{{.Kind}}: {{.N}}</span>{{end}}</p>
+<table>
+{{range .Lines}}<tr{{if .Notes}} class="noted"{{end}}><td class="n">{{.N}}</td><td class="code">{{.Code}}</td><td>{{range .Notes}}{{if .Flow}}<details class="note {{.Kind}}"><summary title="{{.Full}}">{{.Text}}</summary><div class="flow">{{range .Flow}}{{.}}
+{{end}}</div></details>{{else}}<p class="note {{.Kind}}" title="{{.Full}}">{{.Text}}</p>{{end}}{{end}}</td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,120 @@
+// Command escapes shows the compiler's escape analysis and inlining
+// decisions next to the source lines they are about.
+//
+//	go run ./CallByValue/escapes/cmd/escapes ./CallByValue
+//
+// builds the packages with -gcflags=-m=2 and prints each file that has
+// notes, with the notes appended to its lines as comments:
+//
+//	func setPtr(pp **int) { // [inline] can inline setPtr with cost 10; [noescape] pp does not escape
+//		x := 10 // [heap] moved to heap: x
+//
+// Flags:
+//
+//	-flow        also print why each value escapes
+//	-kinds list  only show these kinds: heap, noescape, leak, inline, noinline, other
+//	-o dir       write annotated copies under dir instead of printing them
+//	-html file   write an HTML view instead, with explanations folded under each note
+//	-dir dir     run go build in dir (default: the current directory)
+//
+// The parser's and annotator's tests run with go test ./CallByValue/escapes.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"Lets-GO/CallByValue/escapes"
+)
+
+func main() {
+	flow := flag.Bool("flow", false, "also print why each value escapes")
+	kindList := flag.String("kinds", "", "comma-separated kinds to show (default all)")
+	outDir := flag.String("o", "", "write annotated copies under this directory")
+	htmlOut := flag.String("html", "", "write an HTML view to this file")
+	dir := flag.String("dir", ".", "directory to run go build in")
+	flag.Parse()
+
+	if err := run(*dir, flag.Args(), *kindList, *flow, *outDir, *htmlOut); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(dir string, patterns []string, kindList string, flow bool, outDir, htmlOut string) error {
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	var kinds []escapes.Kind
+	if kindList != "" {
+		var err error
+		if kinds, err = escapes.ParseKinds(kindList); err != nil {
+			return err
+		}
+	}
+
+	notes, err := escapes.Run(dir, patterns...)
+	if err != nil {
+		return err
+	}
+	base, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	files, err := escapes.ReadFiles(escapes.Filter(notes, kinds), base)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case htmlOut != "":
+		f, err := os.Create(htmlOut)
+		if err != nil {
+			return err
+		}
+		title := "Escape analysis: " + strings.Join(patterns, " ")
+		if err := escapes.WriteHTML(f, title, files); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+
+	case outDir != "":
+		for _, file := range files {
+			name := filepath.Join(outDir, file.Name)
+			if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+				return err
+			}
+			f, err := os.Create(name)
+			if err != nil {
+				return err
+			}
+			if err := escapes.Annotate(f, file.Src, file.Notes, flow); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+			fmt.Println("wrote", name)
+		}
+		return nil
+
+	default:
+		for i, file := range files {
+			if len(files) > 1 {
+				if i > 0 {
+					fmt.Println()
+				}
+				fmt.Printf("==> %s <==\n", file.Name)
+			}
+			if err := escapes.Annotate(os.Stdout, file.Src, file.Notes, flow); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,251 @@
+// Package escapes collects the compiler's escape analysis and inlining
+// decisions for a package and lays them next to the source lines they are
+// about.
+//
+// CallByValue's setPtr takes the address of a local:
+//
+//	func setPtr(pp **int) {
+//		x := 10
+//		*pp = &x
+//	}
+//
+// x outlives the call, so the compiler allocates it on the heap. Nothing in
+// the source says so; go build -gcflags=-m=2 does:
+//
+//	./main.go:13:2: x escapes to heap in setPtr:
+//	./main.go:13:2:   flow: {heap} ← &x:
+//	./main.go:13:2:     from &x (address-of) at ./main.go:14:8
+//	./main.go:13:2:     from *pp = &x (assign) at ./main.go:14:6
+//	./main.go:13:2: moved to heap: x
+//
+// Run builds a package with those flags and Parse turns the output into
+// Notes. Annotate and WriteHTML put the notes back into the source.
+package escapes
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Kind classifies a compiler note.
+type Kind int
+
+const (
+	Other    Kind = iota
+	Heap          // moved to heap, escapes to heap
+	NoEscape      // does not escape
+	Leak          // leaking param: the value flows to a result or the heap
+	Inline        // can inline, inlining call to
+	NoInline      // cannot inline
+)
+
+var kindNames = [...]string{
+	Other:    "other",
+	Heap:     "heap",
+	NoEscape: "noescape",
+	Leak:     "leak",
+	Inline:   "inline",
+	NoInline: "noinline",
+}
+
+func (k Kind) String() string { return kindNames[k] }
+
+// ParseKinds parses a comma-separated list of kind names such as
+// "heap,leak".
+func ParseKinds(s string) ([]Kind, error) {
+	var kinds []Kind
+	for f := range strings.SplitSeq(s, ",") {
+		i := slices.Index(kindNames[:], strings.TrimSpace(f))
+		if i < 0 {
+			return nil, fmt.Errorf("unknown note kind %q (want one of %s)", f, strings.Join(kindNames[:], ", "))
+		}
+		kinds = append(kinds, Kind(i))
+	}
+	return kinds, nil
+}
+
+// Note is one compiler diagnostic about a source position.
+type Note struct {
+	File      string // absolute path
+	Line, Col int
+	Kind      Kind
+	Text      string   // the compiler's message
+	Flow      []string // the -m=2 explanation of why, if any
+}
+
+// Short returns Text without the function body the compiler appends to
+// "can inline" notes.
+func (n Note) Short() string {
+	text, _, _ := strings.Cut(n.Text, " as: ")
+	return text
+}
+
+func classify(text string) Kind {
+	switch {
+	case strings.HasPrefix(text, "moved to heap:"), strings.Contains(text, "escapes to heap"):
+		return Heap
+	case strings.HasSuffix(text, "does not escape"):
+		return NoEscape
+	case strings.HasPrefix(text, "leaking param"), strings.Contains(text, " leaks to "):
+		return Leak
+	case strings.HasPrefix(text, "can inline"), strings.HasPrefix(text, "inlining call to"):
+		return Inline
+	case strings.HasPrefix(text, "cannot inline"):
+		return NoInline
+	}
+	return Other
+}
+
+var noteLine = regexp.MustCompile(`^(.+\.go):(\d+):(\d+): (.*)$`)
+
+// Parse reads go build -gcflags=-m=2 output. Relative file names are
+// resolved against dir, the directory go build ran in.
+//
+// With -m=2 the compiler explains an escape in indented lines under a
+// header ending in a colon ("x escapes to heap in setPtr:"), then states
+// the verdict ("moved to heap: x") at the same position. Parse attaches the
+// explanation to the verdict as Flow and drops the header. Exact duplicates,
+// which the compiler prints for code inlined more than once, are dropped
+// too. Notes are sorted by file and position.
+func Parse(r io.Reader, dir string) ([]Note, error) {
+	var notes []Note
+	var header *Note // the last note that ended in a colon
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		m := noteLine.FindStringSubmatch(sc.Text())
+		if m == nil {
+			continue // "# package" lines and anything else
+		}
+		line, _ := strconv.Atoi(m[2])
+		col, _ := strconv.Atoi(m[3])
+		file := m[1]
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		text := m[4]
+
+		if strings.HasPrefix(text, "  ") && header != nil {
+			header.Flow = append(header.Flow, text[2:]) // keep the nesting below "flow:"
+			continue
+		}
+		n := Note{File: file, Line: line, Col: col, Kind: classify(text), Text: text}
+		notes = append(notes, n)
+		header = nil
+		if strings.HasSuffix(text, ":") {
+			header = &notes[len(notes)-1]
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return merge(notes), nil
+}
+
+// merge moves each header's flow to the verdict at the same position.
+func merge(notes []Note) []Note {
+	var out []Note
+	pending := map[[3]any][]string{}
+	for _, n := range notes {
+		at := [3]any{n.File, n.Line, n.Col}
+		if strings.HasSuffix(n.Text, ":") && len(n.Flow) > 0 {
+			pending[at] = append(pending[at], n.Flow...)
+			continue
+		}
+		if flow, ok := pending[at]; ok && n.Kind != Other {
+			n.Flow = append(flow, n.Flow...)
+			delete(pending, at)
+		}
+		out = append(out, n)
+	}
+	// Headers without a verdict are kept as notes of their own.
+	for _, n := range notes {
+		at := [3]any{n.File, n.Line, n.Col}
+		if flow, ok := pending[at]; ok && strings.HasSuffix(n.Text, ":") {
+			n.Text = strings.TrimSuffix(n.Text, ":")
+			n.Flow = flow
+			out = append(out, n)
+			delete(pending, at)
+		}
+	}
+
+	slices.SortStableFunc(out, func(a, b Note) int {
+		if c := strings.Compare(a.File, b.File); c != 0 {
+			return c
+		}
+		if a.Line != b.Line {
+			return a.Line - b.Line
+		}
+		return a.Col - b.Col
+	})
+	return slices.CompactFunc(out, func(a, b Note) bool {
+		return a.File == b.File && a.Line == b.Line && a.Col == b.Col && a.Text == b.Text
+	})
+}
+
+// Run builds the packages matching patterns in dir with
+// -gcflags=-m=2 and returns the compiler's notes. The binary is discarded.
+// The go command replays compiler output from its build cache, so running
+// twice does not compile twice.
+func Run(dir string, patterns ...string) ([]Note, error) {
+	args := append([]string{"build", "-gcflags=-m=2", "-o", os.DevNull}, patterns...)
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("go %s: %v\n%s", strings.Join(args, " "), err, lastLines(out.String(), 20))
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(&out, abs)
+}
+
+// lastLines returns the last n lines of s, where a failed build reports
+// its errors.
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Filter returns the notes of the given kinds; no kinds means all.
+func Filter(notes []Note, kinds []Kind) []Note {
+	if len(kinds) == 0 {
+		return notes
+	}
+	var out []Note
+	for _, n := range notes {
+		if slices.Contains(kinds, n.Kind) {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// ByFile groups notes by file, in file name order.
+func ByFile(notes []Note) (files []string, byFile map[string][]Note) {
+	byFile = map[string][]Note{}
+	for _, n := range notes {
+		if _, ok := byFile[n.File]; !ok {
+			files = append(files, n.File)
+		}
+		byFile[n.File] = append(byFile[n.File], n)
+	}
+	slices.Sort(files)
+	return files, byFile
+}
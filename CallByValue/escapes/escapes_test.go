@@ -0,0 +1,105 @@
+package escapes
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/callbyvalue.golden")
+
+// testdata/callbyvalue.m2 was recorded with
+//
+//	(cd testdata/callbyvalue && go build -gcflags=-m=2 -o /dev/null . > ../callbyvalue.m2 2>&1)
+//
+// and must annotate testdata/callbyvalue/main.go as callbyvalue.golden.
+func TestGolden(t *testing.T) {
+	out, err := os.Open(filepath.Join("testdata", "callbyvalue.m2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	notes, err := Parse(out, filepath.Join("testdata", "callbyvalue"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := os.ReadFile(filepath.Join("testdata", "callbyvalue", "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got bytes.Buffer
+	if err := Annotate(&got, src, notes, true); err != nil {
+		t.Fatal(err)
+	}
+
+	golden := filepath.Join("testdata", "callbyvalue.golden")
+	if *update {
+		if err := os.WriteFile(golden, got.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Bytes(), want) {
+		t.Errorf("annotated main.go differs from %s:\n--- got\n%s--- want\n%s", golden, got.Bytes(), want)
+	}
+}
+
+// Building the fixture with the installed toolchain must still report
+// setPtr's x as moved to heap with an explanation, and failedReassign's px
+// as not escaping. The wording of other notes changes between Go releases
+// and is not checked.
+func TestLive(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go build")
+	}
+	dir := filepath.Join("testdata", "callbyvalue")
+	notes, err := Run(dir, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(src), "\n")
+	lineOf := func(fn, code string) int {
+		in := false
+		for i, l := range lines {
+			if strings.HasPrefix(l, "func "+fn+"(") {
+				in = true
+			}
+			if in && strings.TrimSpace(l) == code {
+				return i + 1
+			}
+		}
+		t.Fatalf("no line %q in %s", code, fn)
+		return 0
+	}
+
+	tests := []struct {
+		line int
+		kind Kind
+		text string
+		flow bool
+	}{
+		{lineOf("setPtr", "x := 10"), Heap, "moved to heap: x", true},
+		{lineOf("failedReassign", "func failedReassign(px *int) {"), NoEscape, "px does not escape", false},
+	}
+	for _, tt := range tests {
+		found := false
+		for _, n := range notes {
+			if n.Line == tt.line && n.Kind == tt.kind && n.Text == tt.text && (len(n.Flow) > 0) == tt.flow {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("no %s note %q on line %d of the fixture", tt.kind, tt.text, tt.line)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Command callbyvalue holds the CallByValue lesson's functions for the
+// escapes tests. main uses println instead of fmt so the compiler's
+// notes stay about the lesson.
+package main
+
+func failedUpdateNil(g *int) {
+	x := 10
+	g = &x
+}
+
+func setPtr(pp **int) {
+	x := 10
+	*pp = &x
+}
+
+func failedReassign(px *int) {
+	x2 := 20
+	px = &x2
+}
+
+func updateValue(px *int) {
+	*px = 20
+}
+
+func modMap(m map[int]string) {
+	m[2] = "hello"
+	m[3] = "goodbye"
+	delete(m, 1)
+}
+
+func modSliceNoReturn(s []int) {
+	for i := range s {
+		s[i] *= 2
+	}
+	s = append(s, 99)
+}
+
+func modSliceReturn(s []int) []int {
+	for i := range s {
+		s[i] *= 2
+	}
+	s = append(s, 99)
+	return s
+}
+
+func main() {
+	var f *int
+	failedUpdateNil(f)
+	setPtr(&f)
+	println(*f)
+
+	x := 10
+	failedReassign(&x)
+	updateValue(&x)
+	println(x)
+
+	m := map[int]string{1: "first", 2: "second"}
+	modMap(m)
+	println(len(m))
+
+	s := make([]int, 3, 5)
+	modSliceNoReturn(s)
+	s = modSliceReturn(s)
+	println(len(s))
+}
@@ -0,0 +1,27 @@
+// Command lostappend reports appends to slice parameters that the caller
+// never sees.
+//
+// Run it on its own:
+//
+//	go run ./CallByValue/lostappend/cmd/lostappend ./...
+//
+// or as a vet tool:
+//
+//	go build -o lostappend ./CallByValue/lostappend/cmd/lostappend
+//	go vet -vettool=$(pwd)/lostappend ./...
+//
+// On this repository it reports modSliceNoReturn in CallByValue, which
+// loses its append on purpose.
+//
+// The analyzer's tests run with go test ./CallByValue/lostappend.
+package main
+
+import (
+	"Lets-GO/CallByValue/lostappend"
+
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(lostappend.Analyzer)
+}
@@ -0,0 +1,218 @@
+// Package lostappend defines an analyzer that reports appends to slice
+// parameters whose result never reaches the caller.
+//
+// modSliceNoReturn in the CallByValue lesson shows the bug:
+//
+//	func modSliceNoReturn(s []int) {
+//		s = append(s, 99) // the caller's s keeps its old length
+//	}
+//
+// A slice parameter is a copy of the caller's slice header. Assigning the
+// result of append to it changes only the copy: the caller sees neither the
+// new length nor, when append had to grow, the new array. The pass reports
+// s = append(...) on a slice parameter or receiver when s is not read
+// afterwards, so the result is not returned, not stored through a pointer
+// and not used in any other way.
+//
+// For functions it suggests returning the slice. Adding a result changes
+// the function's type, which breaks uses of it as a value and callers in
+// other packages, so the fix is offered only for unexported functions that
+// are only ever called directly; the calls in the package, which ignore
+// the result, still compile. Taking a *[]T is only described, as it
+// changes every call site. For methods it suggests a pointer receiver.
+package lostappend
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"Lets-GO/CallByValue/paramuse"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const doc = `report appends to slice parameters that the caller never sees
+
+A slice parameter holds a copy of the caller's slice header, so
+s = append(s, v) updates only the copy. Unless s is returned, stored
+through a pointer or otherwise used afterwards, the append is lost. The
+pass reports such appends in functions and in methods with a slice
+receiver, and suggests returning the slice, taking a *[]T, or using a
+pointer receiver.`
+
+// Analyzer reports appends to slice parameters and receivers whose result
+// is lost.
+var Analyzer = &analysis.Analyzer{
+	Name:     "lostappend",
+	Doc:      doc,
+	URL:      "https://go.dev/blog/slices-intro#passing-slices-to-functions",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// Suggested fix messages. They are fixed strings so that golden files can
+// name them.
+const (
+	fixReturn = "Return the updated slice"
+	fixRecv   = "Use a pointer receiver"
+)
+
+func run(pass *analysis.Pass) (any, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	calls := paramuse.CollectCalls(pass, insp)
+	nodeFilter := []ast.Node{(*ast.FuncDecl)(nil)}
+	insp.Preorder(nodeFilter, func(n ast.Node) {
+		fd := n.(*ast.FuncDecl)
+		if fd.Body == nil {
+			return
+		}
+		if fd.Recv != nil {
+			for _, p := range params(pass, fd.Recv) {
+				checkParam(pass, fd, p, true, false)
+			}
+		}
+		// A method may satisfy an interface, which its result would break.
+		fn, _ := pass.TypesInfo.Defs[fd.Name].(*types.Func)
+		local := fd.Recv == nil && calls.Local(fn)
+		for _, p := range params(pass, fd.Type.Params) {
+			checkParam(pass, fd, p, false, local)
+		}
+	})
+	return nil, nil
+}
+
+// param is a named parameter or receiver of slice type.
+type param struct {
+	id    *ast.Ident
+	v     *types.Var
+	field *ast.Field // the declaration, possibly of several names
+}
+
+func params(pass *analysis.Pass, fl *ast.FieldList) []param {
+	var ps []param
+	for _, field := range fl.List {
+		if _, ok := field.Type.(*ast.Ellipsis); ok {
+			continue // ...T holds the caller's arguments, not a slice of theirs
+		}
+		for _, id := range field.Names {
+			v, ok := pass.TypesInfo.Defs[id].(*types.Var)
+			if !ok || id.Name == "_" {
+				continue
+			}
+			if _, ok := v.Type().Underlying().(*types.Slice); ok {
+				ps = append(ps, param{id, v, field})
+			}
+		}
+	}
+	return ps
+}
+
+// checkParam reports lost appends to p. local says whether every use of
+// the function is a direct call in this package, so that its result type
+// can change.
+func checkParam(pass *analysis.Pass, fd *ast.FuncDecl, p param, recv, local bool) {
+	uses, ok := paramuse.Find(pass.TypesInfo, fd.Body, p.v)
+	if !ok {
+		return
+	}
+	for _, u := range uses {
+		as, ok := paramuse.Assignment(u)
+		if !ok {
+			continue
+		}
+		call, ok := ast.Unparen(as.Rhs[0]).(*ast.CallExpr)
+		if !ok || !isBuiltin(pass, call.Fun, "append") {
+			continue
+		}
+		if !paramuse.ReadAfter(uses, u, as) {
+			report(pass, fd, p, recv, local, as, uses)
+		}
+	}
+}
+
+func report(pass *analysis.Pass, fd *ast.FuncDecl, p param, recv, local bool, as *ast.AssignStmt, uses []paramuse.Use) {
+	name := p.id.Name
+	typ := types.ExprString(p.field.Type)
+	d := analysis.Diagnostic{
+		Pos: as.Pos(),
+		End: as.End(),
+	}
+	if recv {
+		d.Message = fmt.Sprintf("result of append to receiver %s is lost: %s is a copy of the caller's %s, so the caller never sees the new length; use a pointer receiver (*%s)",
+			name, name, typ, typ)
+		if edits, ok := paramuse.DerefEdits(p.field, uses); ok {
+			d.SuggestedFixes = []analysis.SuggestedFix{{Message: fixRecv, TextEdits: edits}}
+		}
+	} else {
+		d.Message = fmt.Sprintf("result of append to parameter %s is lost: %s is a copy of the caller's slice header, so the caller never sees the new length; return %s, or take *%s and pass the slice's address at every call site",
+			name, name, name, typ)
+		if fix, ok := returnFix(pass, fd, p); ok && local {
+			d.SuggestedFixes = append(d.SuggestedFixes, fix)
+		}
+	}
+	pass.Report(d)
+}
+
+// returnFix adds the slice as the function's result. It is only possible
+// for functions without results, whose body spans several lines, and only
+// offered for local ones.
+func returnFix(pass *analysis.Pass, fd *ast.FuncDecl, p param) (analysis.SuggestedFix, bool) {
+	if fd.Type.Results != nil {
+		return analysis.SuggestedFix{}, false
+	}
+	fset := pass.Fset
+	if fset.Position(fd.Body.Lbrace).Line == fset.Position(fd.Body.Rbrace).Line {
+		return analysis.SuggestedFix{}, false
+	}
+
+	name := p.id.Name
+	edits := []analysis.TextEdit{{
+		Pos:     fd.Type.Params.End(),
+		End:     fd.Type.Params.End(),
+		NewText: []byte(" " + types.ExprString(p.field.Type)),
+	}}
+	ast.Inspect(fd.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			edits = append(edits, analysis.TextEdit{Pos: n.End(), End: n.End(), NewText: []byte(" " + name)})
+		}
+		return true
+	})
+	if last := lastStmt(fd.Body); last == nil || !isReturn(last) {
+		indent := strings.Repeat("\t", fset.Position(fd.Body.Rbrace).Column)
+		edits = append(edits, analysis.TextEdit{
+			Pos:     fd.Body.Rbrace,
+			End:     fd.Body.Rbrace,
+			NewText: []byte(indent + "return " + name + "\n"),
+		})
+	}
+	return analysis.SuggestedFix{Message: fixReturn, TextEdits: edits}, true
+}
+
+func lastStmt(b *ast.BlockStmt) ast.Stmt {
+	if len(b.List) == 0 {
+		return nil
+	}
+	return b.List[len(b.List)-1]
+}
+
+func isReturn(s ast.Stmt) bool {
+	_, ok := s.(*ast.ReturnStmt)
+	return ok
+}
+
+func isBuiltin(pass *analysis.Pass, fun ast.Expr, name string) bool {
+	id, ok := ast.Unparen(fun).(*ast.Ident)
+	if !ok {
+		return false
+	}
+	b, ok := pass.TypesInfo.Uses[id].(*types.Builtin)
+	return ok && b.Name() == name
+}
@@ -0,0 +1,13 @@
+package lostappend_test
+
+import (
+	"testing"
+
+	"Lets-GO/CallByValue/lostappend"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), lostappend.Analyzer, "lostappend")
+}
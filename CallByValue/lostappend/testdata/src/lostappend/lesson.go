@@ -0,0 +1,26 @@
+package lostappend
+
+// modSliceNoReturn from the CallByValue lesson: the caller's len stays 3.
+func modSliceNoReturn(s []int) {
+	for i := range s {
+		s[i] *= 2
+	}
+	s = append(s, 99) // want `result of append to parameter s is lost: s is a copy of the caller's slice header, so the caller never sees the new length; return s, or take \*\[\]int and pass the slice's address at every call site`
+}
+
+// modSliceReturn is the lesson's fix.
+func modSliceReturn(s []int) []int {
+	for i := range s {
+		s[i] *= 2
+	}
+	s = append(s, 99)
+	return s
+}
+
+// An early return gets the slice too.
+func addPositive(s []int, v int) {
+	if v <= 0 {
+		return
+	}
+	s = append(s, v) // want `result of append to parameter s is lost`
+}
@@ -0,0 +1,35 @@
+package lostappend
+
+type Stack []int
+
+// Push appends to a copy of the caller's Stack.
+func (s Stack) Push(v int) {
+	s = append(s, v) // want `result of append to receiver s is lost: s is a copy of the caller's Stack, so the caller never sees the new length; use a pointer receiver \(\*Stack\)`
+}
+
+// Top only reads: a value receiver is fine.
+func (s Stack) Top() int {
+	return s[len(s)-1]
+}
+
+// PushAll already has a pointer receiver.
+func (s *Stack) PushAll(vs []int) {
+	*s = append(*s, vs...)
+}
+
+// Grow reads s through an index expression after the append.
+func (s Stack) Grow(v int) int {
+	s = append(s, v)
+	return s[len(s)-1]
+}
+
+type Words []string
+
+// Add appends in a loop and never reads the result.
+func (w Words) Add(text []string) {
+	for _, t := range text {
+		if t != "" {
+			w = append(w, t) // want `result of append to receiver w is lost`
+		}
+	}
+}
@@ -0,0 +1,7 @@
+package lostappend
+
+// a and b share one declaration. Only a is reported, and the return fix
+// leaves b's type alone.
+func two(a, b []int) {
+	a = append(a, b...) // want `result of append to parameter a is lost`
+}
@@ -0,0 +1,37 @@
+package lostappend
+
+// Exported may be used as a value in other packages: reported, no fix.
+func Exported(s []int) {
+	s = append(s, 1) // want `result of append to parameter s is lost`
+}
+
+// callback is used as a func([]int): a result would change its type.
+func callback(s []int) {
+	s = append(s, 1) // want `result of append to parameter s is lost`
+}
+
+var _ func([]int) = callback
+
+// each is passed to apply.
+func each(s []int) {
+	s = append(s, 1) // want `result of append to parameter s is lost`
+}
+
+func apply(f func([]int)) { f(nil) }
+
+func useApply() { apply(each) }
+
+type logger struct{}
+
+// write is a method: a result could break an interface it satisfies.
+func (logger) write(p []byte) {
+	p = append(p, '\n') // want `result of append to parameter p is lost`
+}
+
+// called is only ever called directly, so it gets the return fix; see
+// reach.go.golden.
+func called(s []int) {
+	s = append(s, 1) // want `result of append to parameter s is lost`
+}
+
+func callCalled() { called(nil) }
@@ -0,0 +1,44 @@
+package lostappend
+
+import "fmt"
+
+// Storing the result through a pointer reaches the caller.
+func appendTo(dst *[]int, s []int) {
+	s = append(s, 1)
+	*dst = s
+}
+
+// Using the result locally is fine.
+func sum(s []int, extra int) int {
+	s = append(s, extra)
+	total := 0
+	for _, v := range s {
+		total += v
+	}
+	return total
+}
+
+// Inside a loop the next iteration reads s.
+func chunks(s []int, n int) {
+	for i := 0; i < n; i++ {
+		fmt.Println(len(s))
+		s = append(s, i)
+	}
+}
+
+// A closure may read s after the append.
+func deferred(s []int) {
+	defer func() { fmt.Println(s) }()
+	s = append(s, 1)
+}
+
+// Variadic parameters are the caller's arguments, not a slice of theirs.
+func variadic(vs ...int) {
+	vs = append(vs, 0)
+}
+
+// A function with results gets no fix: taking a *[]int changes its callers.
+func withResult(s []int) error {
+	s = append(s, 1) // want `result of append to parameter s is lost`
+	return nil
+}
@@ -1,6 +1,10 @@
 package main
 
-import "fmt"
+import (
+	"flag"
+	"fmt"
+	"os"
+)
 
 // 1) Passing a nil *int: you cannot make the caller's pointer non-nil
 func failedUpdateNil(g *int) {
@@ -9,6 +13,11 @@ func failedUpdateNil(g *int) {
 }
 
 // To change the caller's pointer variable, pass **int (pointer-to-pointer)
+//
+// x outlives the call through *pp, so the compiler allocates it on the heap
+// ("moved to heap: x"). See every such decision in this file with:
+//
+//	go run ./CallByValue/escapes/cmd/escapes -flow ./CallByValue
 func setPtr(pp **int) {
 	x := 10
 	*pp = &x // updates the caller's pointer
@@ -21,6 +30,11 @@ func failedReassign(px *int) {
 }
 
 // Dereferencing DOES update the caller's value
+//
+// Whether passing a pointer is also faster than passing a copy depends on
+// the size of the value, on inlining and on escapes. Measure it with:
+//
+//	go test -run '^$' -bench Pass ./CallByValue/passbench | go run ./CallByValue/passbench/cmd/passbench
 func updateValue(px *int) {
 	*px = 20
 }
@@ -50,6 +64,19 @@ func modSliceReturn(s []int) []int {
 }
 
 func main() {
+	viz := flag.String("viz", "", "draw memory step by step instead of running the lesson: text, step or svg")
+	call := flag.String("call", "", "viz: only calls whose name contains this")
+	out := flag.String("out", "viz", "viz svg: output directory")
+	flag.Parse()
+
+	if *viz != "" {
+		if err := runViz(*viz, *call, *out); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	fmt.Println("=== Pointer: nil cannot be made non-nil via *T parameter ===")
 	var f *int // nil
 	failedUpdateNil(f)
@@ -0,0 +1,117 @@
+package memviz
+
+// A LessonCall re-runs one call of the CallByValue lesson on a Machine,
+// stepping after each statement.
+type LessonCall struct {
+	Name string
+	Run  func(m *Machine)
+}
+
+// Lesson holds the lesson's calls in the order its main makes them.
+//
+// Where a local lives follows the compiler's escape analysis (see
+// go run ./CallByValue/escapes/cmd/escapes ./CallByValue): setPtr's x is
+// moved to the heap, failedUpdateNil's x and main's x are not.
+var Lesson = []LessonCall{
+	{"failedUpdateNil", lessonFailedUpdateNil},
+	{"setPtr", lessonSetPtr},
+	{"updateValue", lessonUpdateValue},
+	{"modMap", lessonModMap},
+	{"modSliceNoReturn", lessonModSliceNoReturn},
+	{"modSliceReturn", lessonModSliceReturn},
+}
+
+func lessonFailedUpdateNil(m *Machine) {
+	f := m.Var("f", Nil("*int"))
+	m.Step("var f *int // nil")
+
+	g := m.Call("failedUpdateNil", Param{Name: "g", Val: f.Val})[0]
+	m.Step("failedUpdateNil(f): g is a copy of f")
+	x := m.Var("x", Int(10))
+	m.Step("x := 10")
+	m.Set(g, AddrOf(x))
+	m.Step("g = &x // only updates the local copy of the pointer")
+	m.Return()
+	m.Step("return: f is still nil")
+}
+
+func lessonSetPtr(m *Machine) {
+	f := m.Var("f", Nil("*int"))
+	m.Step("var f *int // nil")
+
+	pp := m.Call("setPtr", Param{Name: "pp", Val: AddrOf(f)})[0]
+	m.Step("setPtr(&f): pp points to main's f")
+	x := m.MovedVar("x", Int(10))
+	m.Step("x := 10 // x outlives the call, so it is allocated on the heap")
+	m.Set(m.Deref(pp), AddrOf(x))
+	m.Step("*pp = &x // updates the caller's pointer")
+	m.Return()
+	m.Step("return: f points to x, which outlives setPtr's frame")
+}
+
+func lessonUpdateValue(m *Machine) {
+	x := m.Var("x", Int(10))
+	m.Step("x := 10")
+
+	px := m.Call("updateValue", Param{Name: "px", Val: AddrOf(x)})[0]
+	m.Step("updateValue(&x): px points to main's x")
+	m.Set(m.Deref(px), Int(20))
+	m.Step("*px = 20 // writes main's x through the pointer")
+	m.Return()
+	m.Step("return: x is 20")
+}
+
+func lessonModMap(m *Machine) {
+	mv := m.NewMap("map[int]string",
+		Entry{Key: "1", Val: Str("first")},
+		Entry{Key: "2", Val: Str("second")})
+	mm := m.Var("m", mv)
+	m.Step(`m := map[int]string{1: "first", 2: "second"}`)
+
+	pm := m.Call("modMap", Param{Name: "m", Val: mm.Val})[0]
+	m.Step("modMap(m): the parameter is a copy of the map value, a reference to the same table")
+	m.MapSet(pm.Val, "2", Str("hello"))
+	m.Step(`m[2] = "hello"`)
+	m.MapSet(pm.Val, "3", Str("goodbye"))
+	m.Step(`m[3] = "goodbye"`)
+	m.MapDelete(pm.Val, "1")
+	m.Step("delete(m, 1)")
+	m.Return()
+	m.Step("return: main's m sees every change")
+}
+
+func lessonModSliceNoReturn(m *Machine) {
+	s := m.Var("s", m.MakeSlice("int", 3, 5, Int(0), Int(1), Int(2), Int(3)))
+	m.Step("s := make([]int, 3, 5); s[0], s[1], s[2] = 1, 2, 3")
+
+	ps := m.Call("modSliceNoReturn", Param{Name: "s", Val: s.Val})[0]
+	m.Step("modSliceNoReturn(s): the parameter is a copy of the slice header")
+	doubleAll(m, ps)
+	m.Step("for i := range s { s[i] *= 2 } // writes the shared array")
+	m.Set(ps, m.Append(ps.Val, Int(99)))
+	m.Step("s = append(s, 99) // fits in the capacity: writes [3], changes the local len")
+	m.Return()
+	m.Step("return: main's s still has len 3; 99 is in the array, beyond its length")
+}
+
+func lessonModSliceReturn(m *Machine) {
+	s2 := m.Var("s2", m.MakeSlice("int", 3, 3, Int(0), Int(1), Int(2), Int(3)))
+	m.Step("s2 := []int{1, 2, 3}")
+
+	ps := m.Call("modSliceReturn", Param{Name: "s", Val: s2.Val})[0]
+	m.Step("modSliceReturn(s2): the parameter is a copy of the slice header")
+	doubleAll(m, ps)
+	m.Step("for i := range s { s[i] *= 2 } // writes the shared array")
+	m.Set(ps, m.Append(ps.Val, Int(99)))
+	m.Step("s = append(s, 99) // no room: copies to a new array")
+	m.Set(s2, m.Return(ps.Val)[0])
+	m.Step("return s; s2 = modSliceReturn(s2): main takes the new header, the old array is garbage")
+}
+
+// doubleAll models for i := range s { s[i] *= 2 }.
+func doubleAll(m *Machine, s *Cell) {
+	for i := range s.Val.Len {
+		c := m.Index(s.Val, i)
+		m.Set(c, Int(2*IntValue(c.Val)))
+	}
+}
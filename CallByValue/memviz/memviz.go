@@ -0,0 +1,325 @@
+// Package memviz models the stack frames and heap objects of a small Go
+// program, so that its memory can be drawn step by step.
+//
+// The model is driven by hand: a re-implementation of the code under study
+// calls Machine methods for each statement (declare a variable, call a
+// function, assign, append) and Step after each statement it wants drawn.
+// Every step records which variables and cells the statements since the
+// previous step wrote, so the drawings can highlight them.
+//
+//	m := memviz.New()
+//	f := m.Var("f", memviz.Nil("*int"))
+//	m.Step("var f *int")
+//	g := m.Call("failedUpdateNil", memviz.Param{Name: "g", Val: f.Val})[0]
+//	x := m.Var("x", memviz.Int(10))
+//	m.Set(g, memviz.AddrOf(x))
+//	m.Step("g = &x")
+//
+// WriteText and WriteSVG draw the steps.
+package memviz
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"runtime"
+	"slices"
+	"strconv"
+
+	"Lets-GO/Capacity/growth"
+)
+
+// Value is the value of a variable or heap cell.
+type Value struct {
+	Type string // Go type, such as "*int" or "[]int"
+	Text string // how a non-reference value prints, such as "10"
+
+	// Pointers point to a cell, maps to a map object, slices to the cell
+	// of their backing array at which they start. A nil pointer, map or
+	// slice has neither.
+	Ptr *Cell
+	Obj *Object
+	Len int // slices only
+	Cap int // slices only
+}
+
+// Int returns an int value.
+func Int(n int) Value { return Value{Type: "int", Text: strconv.Itoa(n)} }
+
+// Str returns a string value.
+func Str(s string) Value { return Value{Type: "string", Text: strconv.Quote(s)} }
+
+// Nil returns the nil value of a pointer, map or slice type.
+func Nil(typ string) Value { return Value{Type: typ, Text: "nil"} }
+
+// AddrOf returns a pointer to c.
+func AddrOf(c *Cell) Value { return Value{Type: "*" + c.Val.Type, Ptr: c} }
+
+// IntValue returns the int held by an Int value.
+func IntValue(v Value) int {
+	n, err := strconv.Atoi(v.Text)
+	if err != nil {
+		panic("memviz: not an int: " + v.Text)
+	}
+	return n
+}
+
+// IsNil reports whether v is a nil pointer, map or slice.
+func (v Value) IsNil() bool { return v.Ptr == nil && v.Obj == nil && v.Text == "nil" }
+
+// Cell is a variable in a frame or a slot of a heap object.
+type Cell struct {
+	Name string
+	Val  Value
+
+	id    string // what arrows point at: "main.f", "#1", "#2[0]"
+	moved *Cell  // for a frame variable moved to the heap, its heap cell
+}
+
+// Frame is one function call on the stack.
+type Frame struct {
+	Func  string
+	Cells []*Cell
+}
+
+// Object is a heap allocation: a variable moved to the heap, a map's hash
+// table or a slice's backing array.
+type Object struct {
+	ID    int
+	Type  string // "int", "map[int]string", "[5]int"
+	Note  string // why it is on the heap
+	Array bool   // draw the cells in a row
+	Cells []*Cell
+
+	deleted []string // map keys deleted since the last step
+	zero    Value    // backing arrays: the zero value of the elements
+}
+
+// Machine is the modeled program: a stack of frames, main first, and the
+// heap.
+type Machine struct {
+	Frames []*Frame
+	Heap   []*Object
+	Steps  []Step
+
+	changed map[*Cell]bool
+	nextID  int
+}
+
+// New returns a Machine running main with no variables.
+func New() *Machine {
+	return &Machine{Frames: []*Frame{{Func: "main"}}, changed: map[*Cell]bool{}}
+}
+
+func (m *Machine) top() *Frame { return m.Frames[len(m.Frames)-1] }
+
+func (m *Machine) alloc(typ, note string, array bool) *Object {
+	m.nextID++
+	o := &Object{ID: m.nextID, Type: typ, Note: note, Array: array}
+	m.Heap = append(m.Heap, o)
+	return o
+}
+
+// Var declares a variable in the current function.
+func (m *Machine) Var(name string, v Value) *Cell {
+	f := m.top()
+	c := &Cell{Name: name, Val: v, id: f.Func + "." + name}
+	f.Cells = append(f.Cells, c)
+	m.changed[c] = true
+	return c
+}
+
+// MovedVar declares a variable that escape analysis moves to the heap. The
+// frame keeps a note of it; the returned cell, the one to take the address
+// of, is on the heap.
+func (m *Machine) MovedVar(name string, v Value) *Cell {
+	o := m.alloc(v.Type, "moved to heap: "+name, false)
+	hc := &Cell{Name: name, Val: v, id: "#" + strconv.Itoa(o.ID)}
+	o.Cells = []*Cell{hc}
+	f := m.top()
+	f.Cells = append(f.Cells, &Cell{Name: name, Val: v, id: f.Func + "." + name, moved: hc})
+	m.changed[hc] = true
+	return hc
+}
+
+// Param is an argument of Call.
+type Param struct {
+	Name string
+	Val  Value
+}
+
+// Call enters fn, copying each argument into a parameter of the new frame,
+// and returns the parameters.
+func (m *Machine) Call(fn string, params ...Param) []*Cell {
+	m.Frames = append(m.Frames, &Frame{Func: fn})
+	var cells []*Cell
+	for _, p := range params {
+		cells = append(cells, m.Var(p.Name, p.Val))
+	}
+	return cells
+}
+
+// Return leaves the current function. Its results are handed back as
+// values for the caller to store.
+func (m *Machine) Return(results ...Value) []Value {
+	if len(m.Frames) == 1 {
+		panic("memviz: return from main")
+	}
+	m.Frames = m.Frames[:len(m.Frames)-1]
+	return results
+}
+
+// Set assigns v to c.
+func (m *Machine) Set(c *Cell, v Value) {
+	c.Val = v
+	m.changed[c] = true
+}
+
+// Deref returns the cell p points to.
+func (m *Machine) Deref(p *Cell) *Cell {
+	if p.Val.Ptr == nil {
+		panic("memviz: nil pointer dereference of " + p.Name)
+	}
+	return p.Val.Ptr
+}
+
+// ---- maps ----
+
+// Entry is a key and value for NewMap.
+type Entry struct {
+	Key string
+	Val Value
+}
+
+// NewMap allocates a map with the given entries and returns the map value,
+// a reference to its hash table.
+func (m *Machine) NewMap(typ string, entries ...Entry) Value {
+	o := m.alloc(typ, "hash table", false)
+	v := Value{Type: typ, Obj: o}
+	for _, e := range entries {
+		m.MapSet(v, e.Key, e.Val)
+	}
+	return v
+}
+
+// MapSet stores key: val in the map mv.
+func (m *Machine) MapSet(mv Value, key string, val Value) {
+	o := mv.Obj
+	for _, c := range o.Cells {
+		if c.Name == key {
+			m.Set(c, val)
+			return
+		}
+	}
+	c := &Cell{Name: key, Val: val, id: fmt.Sprintf("#%d[%s]", o.ID, key)}
+	o.Cells = append(o.Cells, c)
+	slices.SortFunc(o.Cells, func(a, b *Cell) int { return compareKeys(a.Name, b.Name) })
+	m.changed[c] = true
+}
+
+// MapDelete removes key from the map mv.
+func (m *Machine) MapDelete(mv Value, key string) {
+	o := mv.Obj
+	o.Cells = slices.DeleteFunc(o.Cells, func(c *Cell) bool { return c.Name == key })
+	o.deleted = append(o.deleted, key)
+}
+
+// compareKeys orders numeric keys numerically and others as strings.
+func compareKeys(a, b string) int {
+	x, errA := strconv.Atoi(a)
+	y, errB := strconv.Atoi(b)
+	if errA == nil && errB == nil {
+		return x - y
+	}
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// ---- slices ----
+
+// MakeSlice allocates a backing array of capacity cap, holding elems
+// followed by zero values of elemZero, and returns a slice of length len.
+func (m *Machine) MakeSlice(elemType string, length, capacity int, elemZero Value, elems ...Value) Value {
+	o := m.alloc(fmt.Sprintf("[%d]%s", capacity, elemType), "backing array", true)
+	o.zero = elemZero
+	for i := range capacity {
+		v := elemZero
+		if i < len(elems) {
+			v = elems[i]
+		}
+		c := &Cell{Name: strconv.Itoa(i), Val: v, id: fmt.Sprintf("#%d[%d]", o.ID, i)}
+		o.Cells = append(o.Cells, c)
+		m.changed[c] = true
+	}
+	return Value{Type: "[]" + elemType, Ptr: o.Cells[0], Obj: o, Len: length, Cap: capacity}
+}
+
+// Index returns the cell of element i of slice s.
+func (m *Machine) Index(s Value, i int) *Cell {
+	if i < 0 || i >= s.Len {
+		panic(fmt.Sprintf("memviz: index %d out of range [0:%d]", i, s.Len))
+	}
+	return s.Obj.Cells[slices.Index(s.Obj.Cells, s.Ptr)+i]
+}
+
+// Append models append(s, vals...): it writes into s's backing array when
+// the capacity allows, and otherwise allocates a new array, sized the way
+// the runtime sizes it for s's element type, and copies s into it. Growing
+// panics unless the element type is built from predeclared types, such as
+// int, string or *int, since only those have a known size.
+func (m *Machine) Append(s Value, vals ...Value) Value {
+	newLen := s.Len + len(vals)
+	if newLen <= s.Cap {
+		start := slices.Index(s.Obj.Cells, s.Ptr)
+		for i, v := range vals {
+			m.Set(s.Obj.Cells[start+s.Len+i], v)
+		}
+		s.Len = newLen
+		return s
+	}
+
+	elemType := s.Type[len("[]"):]
+	size, ptrs := elemLayout(elemType)
+	newCap := growth.Grow(s.Cap, newLen, size, ptrs)
+	var elems []Value
+	for i := range s.Len {
+		elems = append(elems, m.Index(s, i).Val)
+	}
+	elems = append(elems, vals...)
+	return m.MakeSlice(elemType, newLen, newCap, s.Obj.zero, elems...)
+}
+
+// elemLayout returns the size of the type named by elemType on this
+// platform, and whether it holds pointers, which together decide how the
+// runtime rounds a grown array.
+func elemLayout(elemType string) (size uintptr, ptrs bool) {
+	tv, err := types.Eval(token.NewFileSet(), nil, token.NoPos, elemType)
+	if err != nil || !tv.IsType() {
+		panic(fmt.Sprintf("memviz: cannot size element type %s: %v", elemType, err))
+	}
+	return uintptr(types.SizesFor("gc", runtime.GOARCH).Sizeof(tv.Type)), hasPointers(tv.Type)
+}
+
+// hasPointers reports whether a value of type t holds pointers, as
+// growth.HasPointers does for a reflect.Type.
+func hasPointers(t types.Type) bool {
+	switch t := t.Underlying().(type) {
+	case *types.Basic:
+		return t.Kind() == types.String || t.Kind() == types.UnsafePointer
+	case *types.Array:
+		return t.Len() > 0 && hasPointers(t.Elem())
+	case *types.Struct:
+		for f := range t.Fields() {
+			if hasPointers(f.Type()) {
+				return true
+			}
+		}
+		return false
+	}
+	return true // pointers, slices, maps, channels, funcs and interfaces
+}
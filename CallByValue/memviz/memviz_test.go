@@ -0,0 +1,181 @@
+package memviz
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"Lets-GO/Capacity/growth"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/lesson.golden")
+
+// texts returns the Text of each cell of o.
+func texts(o *Object) []string {
+	var ts []string
+	for _, c := range o.Cells {
+		ts = append(ts, c.Val.Text)
+	}
+	return ts
+}
+
+func TestAppend(t *testing.T) {
+	tests := []struct {
+		name      string
+		make      func(m *Machine) Value
+		val       Value
+		size      uintptr
+		ptrs      bool
+		grows     bool
+		wantCells []string
+	}{
+		{
+			name:      "in place",
+			make:      func(m *Machine) Value { return m.MakeSlice("int", 3, 5, Int(0), Int(1), Int(2), Int(3)) },
+			val:       Int(99),
+			wantCells: []string{"1", "2", "3", "99", "0"},
+		},
+		{
+			name:      "int grows",
+			make:      func(m *Machine) Value { return m.MakeSlice("int", 3, 3, Int(0), Int(1), Int(2), Int(3)) },
+			val:       Int(99),
+			size:      8,
+			grows:     true,
+			wantCells: []string{"1", "2", "3", "99", "0", "0"},
+		},
+		{
+			name:      "string grows",
+			make:      func(m *Machine) Value { return m.MakeSlice("string", 1, 1, Str(""), Str("a")) },
+			val:       Str("b"),
+			size:      16,
+			ptrs:      true,
+			grows:     true,
+			wantCells: []string{`"a"`, `"b"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New()
+			s := tt.make(m)
+			got := m.Append(s, tt.val)
+
+			if got.Len != s.Len+1 {
+				t.Errorf("len = %d, want %d", got.Len, s.Len+1)
+			}
+			if !tt.grows {
+				if got.Obj != s.Obj || got.Cap != s.Cap || len(m.Heap) != 1 {
+					t.Errorf("append within capacity allocated: cap %d, %d heap objects", got.Cap, len(m.Heap))
+				}
+			} else {
+				wantCap := growth.Grow(s.Cap, s.Len+1, tt.size, tt.ptrs)
+				if got.Obj == s.Obj || len(m.Heap) != 2 {
+					t.Fatalf("append beyond capacity did not allocate: %d heap objects", len(m.Heap))
+				}
+				if got.Cap != wantCap || len(got.Obj.Cells) != wantCap {
+					t.Errorf("cap = %d with %d cells, want %d", got.Cap, len(got.Obj.Cells), wantCap)
+				}
+				if got.Obj.Type != fmt.Sprintf("[%d]%s", wantCap, s.Type[len("[]"):]) {
+					t.Errorf("array type = %s", got.Obj.Type)
+				}
+			}
+			if got := texts(got.Obj); !slices.Equal(got, tt.wantCells) {
+				t.Errorf("array = %q, want %q", got, tt.wantCells)
+			}
+		})
+	}
+}
+
+func TestIndex(t *testing.T) {
+	m := New()
+	s := m.MakeSlice("int", 2, 4, Int(0), Int(1), Int(2))
+	if got := m.Index(s, 1).Val.Text; got != "2" {
+		t.Errorf("s[1] = %s, want 2", got)
+	}
+	for _, i := range []int{-1, 2, 3} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("s[%d] with len 2 did not panic", i)
+				}
+			}()
+			m.Index(s, i)
+		}()
+	}
+}
+
+// TestReachable checks that after modSliceReturn main holds only the new
+// array, and that the step draws the old one as garbage.
+func TestReachable(t *testing.T) {
+	m := New()
+	lessonModSliceReturn(m)
+	if len(m.Heap) != 2 {
+		t.Fatalf("%d heap objects, want the old and the new array", len(m.Heap))
+	}
+	reachable := m.reachable()
+	if reachable[m.Heap[0]] || !reachable[m.Heap[1]] {
+		t.Errorf("reachable: old array %t, new array %t; want false, true", reachable[m.Heap[0]], reachable[m.Heap[1]])
+	}
+	last := m.Steps[len(m.Steps)-1]
+	if !last.Heap[0].Unreachable || last.Heap[1].Unreachable {
+		t.Errorf("last step: old array unreachable %t, new array %t; want true, false",
+			last.Heap[0].Unreachable, last.Heap[1].Unreachable)
+	}
+}
+
+func TestMapDelete(t *testing.T) {
+	m := New()
+	mv := m.NewMap("map[int]string", Entry{"1", Str("first")}, Entry{"2", Str("second")})
+	m.Var("m", mv)
+	m.Step("m := ...")
+	m.MapDelete(mv, "1")
+	m.Step("delete(m, 1)")
+	m.Step("next")
+
+	del, next := m.Steps[1].Heap[0], m.Steps[2].Heap[0]
+	if !slices.Equal(del.Deleted, []string{"1"}) {
+		t.Errorf("Deleted = %q, want [1]", del.Deleted)
+	}
+	if len(del.Cells) != 1 || del.Cells[0].Name != "2" {
+		t.Errorf("cells after delete = %v, want only 2", del.Cells)
+	}
+	if next.Deleted != nil {
+		t.Errorf("Deleted one step later = %q, want none", next.Deleted)
+	}
+}
+
+// TestGolden compares WriteText for every step of Lesson, which
+// go run ./CallByValue -viz text prints, with testdata/lesson.golden.
+// After an intended change, rewrite it with
+// go test ./CallByValue/memviz -update.
+func TestGolden(t *testing.T) {
+	var got bytes.Buffer
+	for _, c := range Lesson {
+		m := New()
+		c.Run(m)
+		fmt.Fprintf(&got, "=== %s ===\n\n", c.Name)
+		for i := range m.Steps {
+			if err := WriteText(&got, m.Steps, i); err != nil {
+				t.Fatal(err)
+			}
+			got.WriteString("\n")
+		}
+	}
+
+	golden := filepath.Join("testdata", "lesson.golden")
+	if *update {
+		if err := os.WriteFile(golden, got.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Bytes(), want) {
+		t.Errorf("drawings differ from %s:\n--- got\n%s--- want\n%s", golden, got.Bytes(), want)
+	}
+}
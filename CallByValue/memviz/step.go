@@ -0,0 +1,124 @@
+package memviz
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Step is a snapshot of the machine, ready to draw.
+type Step struct {
+	Title  string // the statement just executed
+	Frames []FrameView
+	Heap   []ObjectView
+}
+
+// FrameView is a frame in a Step.
+type FrameView struct {
+	Func  string
+	Cells []CellView
+}
+
+// ObjectView is a heap object in a Step.
+type ObjectView struct {
+	ID          string // "#1"
+	Type        string
+	Note        string
+	Array       bool
+	Unreachable bool     // nothing on the stack leads here any more
+	Deleted     []string // map keys deleted by the step
+	Cells       []CellView
+}
+
+// CellView is a variable or heap cell in a Step.
+type CellView struct {
+	ID      string // what arrows point at
+	Name    string
+	Type    string
+	Text    string // the value, without the arrow
+	Target  string // the ID this cell points to, or ""
+	Changed bool   // written since the previous step
+}
+
+// Step records a snapshot titled with the statement just executed, and
+// starts collecting changes for the next one.
+func (m *Machine) Step(title string) {
+	s := Step{Title: title}
+	for _, f := range m.Frames {
+		fv := FrameView{Func: f.Func}
+		for _, c := range f.Cells {
+			fv.Cells = append(fv.Cells, m.view(c))
+		}
+		s.Frames = append(s.Frames, fv)
+	}
+
+	reachable := m.reachable()
+	for _, o := range m.Heap {
+		ov := ObjectView{
+			ID:          "#" + strconv.Itoa(o.ID),
+			Type:        o.Type,
+			Note:        o.Note,
+			Array:       o.Array,
+			Unreachable: !reachable[o],
+			Deleted:     o.deleted,
+		}
+		o.deleted = nil
+		for _, c := range o.Cells {
+			ov.Cells = append(ov.Cells, m.view(c))
+		}
+		s.Heap = append(s.Heap, ov)
+	}
+
+	m.Steps = append(m.Steps, s)
+	m.changed = map[*Cell]bool{}
+}
+
+func (m *Machine) view(c *Cell) CellView {
+	v := c.Val
+	cv := CellView{ID: c.id, Name: c.Name, Type: v.Type, Text: v.Text, Changed: m.changed[c]}
+	switch {
+	case c.moved != nil:
+		cv.Text = "moved to heap"
+		cv.Target = c.moved.id
+		cv.Changed = m.changed[c.moved]
+	case v.Obj != nil && v.Ptr != nil: // slice
+		cv.Text = fmt.Sprintf("len=%d cap=%d", v.Len, v.Cap)
+		cv.Target = v.Ptr.id
+	case v.Obj != nil: // map
+		cv.Text = ""
+		cv.Target = "#" + strconv.Itoa(v.Obj.ID)
+	case v.Ptr != nil:
+		cv.Text = ""
+		cv.Target = v.Ptr.id
+	}
+	return cv
+}
+
+// reachable returns the heap objects reachable from the stack.
+func (m *Machine) reachable() map[*Object]bool {
+	owner := map[*Cell]*Object{}
+	for _, o := range m.Heap {
+		for _, c := range o.Cells {
+			owner[c] = o
+		}
+	}
+	seen := map[*Object]bool{}
+	var visit func(c *Cell)
+	visit = func(c *Cell) {
+		targets := []*Object{c.Val.Obj, owner[c.Val.Ptr], owner[c.moved]}
+		for _, o := range targets {
+			if o == nil || seen[o] {
+				continue
+			}
+			seen[o] = true
+			for _, oc := range o.Cells {
+				visit(oc)
+			}
+		}
+	}
+	for _, f := range m.Frames {
+		for _, c := range f.Cells {
+			visit(c)
+		}
+	}
+	return seen
+}
@@ -0,0 +1,248 @@
+package memviz
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// Layout of WriteSVG, in pixels.
+const (
+	margin    = 20
+	titleH    = 40
+	rowH      = 24
+	stackW    = 380
+	heapX     = margin + stackW + 140
+	heapW     = 240
+	cellW     = 44
+	objectGap = 24
+)
+
+// point is where an arrow ends, and the side it arrives from.
+type point struct {
+	x, y int
+	from side
+}
+
+type side int
+
+const (
+	fromLeft  side = iota // heap objects and entries
+	fromRight             // stack variables, next to the arrows' start
+	fromBelow             // array cells
+)
+
+// WriteSVG draws step i of steps as an SVG image: frames on the left, heap
+// objects on the right, pointers as arrows. Variables and cells written by
+// the step are highlighted; heap objects nothing points to any more are
+// dashed.
+func WriteSVG(w io.Writer, steps []Step, i int) error {
+	s := steps[i]
+	var body strings.Builder
+	targets := map[string]point{}
+	type arrow struct {
+		x, y   int
+		target string
+	}
+	var arrows []arrow
+
+	// Stack.
+	y := margin + titleH
+	text(&body, margin, y-6, "label", "stack")
+	for _, f := range s.Frames {
+		top := y
+		rect(&body, margin, y, stackW, rowH, "frame-head", false)
+		text(&body, margin+10, y+17, "func", f.Func)
+		y += rowH
+		for _, c := range f.Cells {
+			if c.Changed {
+				rect(&body, margin, y, stackW, rowH, "changed", false)
+			}
+			text(&body, margin+10, y+17, "name", c.Name)
+			text(&body, margin+80, y+17, "type", c.Type)
+			text(&body, margin+190, y+17, "value", c.Text)
+			targets[c.ID] = point{margin + stackW, y + rowH/2, fromRight}
+			if c.Target != "" {
+				arrows = append(arrows, arrow{margin + stackW - 16, y + rowH/2, c.Target})
+			}
+			y += rowH
+		}
+		if len(f.Cells) == 0 {
+			text(&body, margin+10, y+17, "type", "(no variables)")
+			y += rowH
+		}
+		rect(&body, margin, top, stackW, y-top, "frame", false)
+		y += 8
+	}
+	height := y
+
+	// Heap.
+	y = margin + titleH
+	text(&body, heapX, y-6, "label", "heap")
+	for _, o := range s.Heap {
+		head := fmt.Sprintf("%s %s  %s", o.ID, o.Type, o.Note)
+		if o.Unreachable {
+			head += " (garbage)"
+		}
+		targets[o.ID] = point{heapX, y + rowH/2, fromLeft}
+		top := y
+		width := heapW
+		if o.Array {
+			width = max(heapW, len(o.Cells)*cellW)
+		}
+		rect(&body, heapX, y, width, rowH, "object-head", false)
+		text(&body, heapX+8, y+17, "func", head)
+		y += rowH
+		if o.Array {
+			for j, c := range o.Cells {
+				x := heapX + j*cellW
+				if c.Changed {
+					rect(&body, x, y+14, cellW, rowH, "changed", false)
+				}
+				rect(&body, x, y+14, cellW, rowH, "cell", false)
+				textAnchor(&body, x+cellW/2, y+10, "index", c.Name, "middle")
+				textAnchor(&body, x+cellW/2, y+14+17, "value", c.Text, "middle")
+				targets[c.ID] = point{x + cellW/2, y + 14 + rowH, fromBelow}
+			}
+			y += rowH + 14 + 6
+		} else {
+			for _, c := range o.Cells {
+				if c.Changed {
+					rect(&body, heapX, y, width, rowH, "changed", false)
+				}
+				label := c.Name + ":"
+				if c.Type == o.Type {
+					label = c.Name + " ="
+				}
+				text(&body, heapX+10, y+17, "name", label)
+				text(&body, heapX+60, y+17, "value", c.Text)
+				if _, ok := targets[c.ID]; !ok {
+					targets[c.ID] = point{heapX, y + rowH/2, fromLeft}
+				}
+				y += rowH
+			}
+			for _, k := range o.Deleted {
+				rect(&body, heapX, y, width, rowH, "changed", false)
+				text(&body, heapX+10, y+17, "deleted", k+":")
+				text(&body, heapX+60, y+17, "type", "(deleted)")
+				y += rowH
+			}
+			if len(o.Cells) == 0 && len(o.Deleted) == 0 {
+				text(&body, heapX+10, y+17, "type", "(no entries)")
+				y += rowH
+			}
+		}
+		rect(&body, heapX, top, width, y-top, "object", o.Unreachable)
+		y += objectGap
+	}
+	height = max(height, y) + margin
+
+	// Arrows, drawn last so they sit on top.
+	for _, a := range arrows {
+		t, ok := targets[a.target]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&body, `<circle cx="%d" cy="%d" r="3" class="dot"/>`+"\n", a.x, a.y)
+		c1x, c2x, c2y := a.x+80, t.x-80, t.y
+		switch t.from {
+		case fromRight:
+			c1x, c2x = a.x+110, t.x+110
+		case fromBelow:
+			c2x, c2y = t.x, t.y+60
+		}
+		fmt.Fprintf(&body, `<path d="M%d,%d C%d,%d %d,%d %d,%d" class="arrow" marker-end="url(#head)"/>`+"\n",
+			a.x, a.y, c1x, a.y, c2x, c2y, t.x, t.y)
+	}
+
+	width := heapX + heapW + margin
+	for _, o := range s.Heap {
+		if o.Array {
+			width = max(width, heapX+len(o.Cells)*cellW+margin)
+		}
+	}
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="monospace" font-size="13">
+<defs><marker id="head" viewBox="0 0 10 10" refX="9" refY="5" markerWidth="7" markerHeight="7" orient="auto-start-reverse"><path d="M0,0 L10,5 L0,10 z" fill="#1f5fb0"/></marker></defs>
+<style>
+.frame, .object, .cell { fill: none; stroke: #444; }
+.garbage { fill: none; stroke: #999; stroke-dasharray: 5 4; }
+.frame-head { fill: #dde6f3; } .object-head { fill: #eee3d3; }
+.changed { fill: #fff3b0; }
+.func { font-weight: bold; } .label { fill: #666; font-family: sans-serif; }
+.type, .index { fill: #777; } .deleted { fill: #777; text-decoration: line-through; } .arrow { fill: none; stroke: #1f5fb0; stroke-width: 1.5; } .dot { fill: #1f5fb0; }
+.title { font-family: sans-serif; font-size: 15px; }
+</style>
+<rect width="100%%" height="100%%" fill="white"/>
+`, width, height, width, height)
+	text(w, margin, margin+8, "title", fmt.Sprintf("step %d/%d: %s", i+1, len(steps), s.Title))
+	_, err := io.WriteString(w, body.String()+"</svg>\n")
+	return err
+}
+
+func rect(w io.Writer, x, y, width, height int, class string, garbage bool) {
+	if garbage {
+		class = "garbage"
+	}
+	fmt.Fprintf(w, `<rect x="%d" y="%d" width="%d" height="%d" class="%s"/>`+"\n", x, y, width, height, class)
+}
+
+func text(w io.Writer, x, y int, class, s string) {
+	textAnchor(w, x, y, class, s, "start")
+}
+
+func textAnchor(w io.Writer, x, y int, class, s, anchor string) {
+	if s == "" {
+		return
+	}
+	fmt.Fprintf(w, `<text x="%d" y="%d" class="%s" text-anchor="%s" xml:space="preserve">%s</text>`+"\n", x, y, class, anchor, html.EscapeString(s))
+}
+
+// Page is one step image listed by WriteIndex.
+type Page struct {
+	Call  string // the call the step belongs to
+	File  string // the SVG file, relative to the index
+	Title string
+}
+
+// WriteIndex writes an HTML page showing the step images in order, one
+// section per call.
+func WriteIndex(w io.Writer, title string, pages []Page) error {
+	type section struct {
+		Call  string
+		Pages []Page
+	}
+	var sections []section
+	for _, p := range pages {
+		if len(sections) == 0 || sections[len(sections)-1].Call != p.Call {
+			sections = append(sections, section{Call: p.Call})
+		}
+		last := &sections[len(sections)-1]
+		last.Pages = append(last.Pages, p)
+	}
+	return indexTemplate.Execute(w, struct {
+		Title    string
+		Sections []section
+	}{title, sections})
+}
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 1100px; margin: 2em auto; padding: 0 1em; color: #222; }
+img { display: block; margin: 0 0 1.5em; border: 1px solid #ddd; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{range .Sections}}
+<h2>{{.Call}}</h2>
+{{range .Pages}}<img src="{{.File}}" alt="{{.Title}}">
+{{end}}{{end}}
+</body>
+</html>
+`))
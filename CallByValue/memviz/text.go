@@ -0,0 +1,125 @@
+package memviz
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteText draws step i of steps (counting from 0) as text. Variables and
+// map entries written by the step are marked with "*" in the margin, array
+// cells with "*" under the cell; pointers are drawn as "--> target", where
+// target names a variable (func.name) or a heap object (#n, #n[i]).
+//
+//	step 3/4: g = &x
+//	stack
+//	  main
+//	      f  *int  nil
+//	  failedUpdateNil
+//	    * g  *int  --> failedUpdateNil.x
+//	      x  int   10
+//	heap
+//	  (empty)
+func WriteText(w io.Writer, steps []Step, i int) error {
+	s := steps[i]
+	var b strings.Builder
+	fmt.Fprintf(&b, "step %d/%d: %s\n", i+1, len(steps), s.Title)
+
+	nameW, typeW := 1, 1
+	for _, f := range s.Frames {
+		for _, c := range f.Cells {
+			nameW = max(nameW, len(c.Name))
+			typeW = max(typeW, len(c.Type))
+		}
+	}
+	b.WriteString("stack\n")
+	for _, f := range s.Frames {
+		fmt.Fprintf(&b, "  %s\n", f.Func)
+		if len(f.Cells) == 0 {
+			b.WriteString("      (no variables)\n")
+		}
+		for _, c := range f.Cells {
+			fmt.Fprintf(&b, "    %s %-*s  %-*s  %s\n", mark(c.Changed), nameW, c.Name, typeW, c.Type, valueText(c))
+		}
+	}
+
+	b.WriteString("heap\n")
+	if len(s.Heap) == 0 {
+		b.WriteString("  (empty)\n")
+	}
+	for _, o := range s.Heap {
+		note := o.Note
+		if o.Unreachable {
+			note += ", unreachable: garbage"
+		}
+		fmt.Fprintf(&b, "  %s %s  %s\n", o.ID, o.Type, note)
+		if o.Array {
+			writeArray(&b, o.Cells)
+			continue
+		}
+		for _, c := range o.Cells {
+			if o.Type == c.Type { // a variable moved to the heap
+				fmt.Fprintf(&b, "    %s %s = %s\n", mark(c.Changed), c.Name, valueText(c))
+				continue
+			}
+			fmt.Fprintf(&b, "    %s %s: %s\n", mark(c.Changed), c.Name, valueText(c))
+		}
+		for _, k := range o.Deleted {
+			fmt.Fprintf(&b, "    * %s: (deleted)\n", k)
+		}
+		if len(o.Cells) == 0 && len(o.Deleted) == 0 {
+			b.WriteString("      (no entries)\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func mark(changed bool) string {
+	if changed {
+		return "*"
+	}
+	return " "
+}
+
+func valueText(c CellView) string {
+	switch {
+	case c.Target == "":
+		return c.Text
+	case c.Text == "":
+		return "--> " + c.Target
+	}
+	return c.Text + " --> " + c.Target
+}
+
+// writeArray draws cells in a row, with indexes above and change marks
+// below.
+func writeArray(b *strings.Builder, cells []CellView) {
+	width := 3
+	for _, c := range cells {
+		width = max(width, len(valueText(c))+2)
+	}
+	var idx, border, vals, marks strings.Builder
+	for i, c := range cells {
+		fmt.Fprintf(&idx, "%*d", width+1, i)
+		border.WriteString("+" + strings.Repeat("-", width))
+		fmt.Fprintf(&vals, "|%*s ", width-1, valueText(c))
+		m := " "
+		if c.Changed {
+			m = "*"
+		}
+		fmt.Fprintf(&marks, " %s", center(m, width))
+	}
+	border.WriteString("+")
+	vals.WriteString("|")
+	const indent = "      "
+	fmt.Fprintf(b, "%s%s\n%s%s\n%s%s\n%s%s\n", indent, idx.String()[1:], indent, border.String(), indent, vals.String(), indent, border.String())
+	if strings.TrimSpace(marks.String()) != "" {
+		fmt.Fprintf(b, "%s%s\n", indent, strings.TrimRight(marks.String(), " "))
+	}
+}
+
+func center(s string, width int) string {
+	left := (width - len(s)) / 2
+	return strings.Repeat(" ", left) + s + strings.Repeat(" ", width-len(s)-left)
+}
@@ -0,0 +1,56 @@
+package paramuse
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// Calls holds the arguments of every static call to a function of the
+// package being analyzed, and the functions that are also used as values.
+// A fix that changes a function's parameters or results has to know all
+// of its callers.
+type Calls struct {
+	args    map[*types.Func][][]ast.Expr
+	asValue map[*types.Func]bool
+}
+
+// CollectCalls finds every call in the package.
+func CollectCalls(pass *analysis.Pass, insp *inspector.Inspector) *Calls {
+	c := &Calls{args: map[*types.Func][][]ast.Expr{}, asValue: map[*types.Func]bool{}}
+	callee := map[*ast.Ident]bool{}
+	insp.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		fn := typeutil.StaticCallee(pass.TypesInfo, call)
+		if fn == nil || fn.Pkg() != pass.Pkg {
+			return
+		}
+		c.args[fn] = append(c.args[fn], call.Args)
+		switch fun := ast.Unparen(call.Fun).(type) {
+		case *ast.Ident:
+			callee[fun] = true
+		case *ast.SelectorExpr:
+			callee[fun.Sel] = true
+		}
+	})
+	for id, obj := range pass.TypesInfo.Uses {
+		if fn, ok := obj.(*types.Func); ok && fn.Pkg() == pass.Pkg && !callee[id] {
+			c.asValue[fn] = true
+		}
+	}
+	return c
+}
+
+// Local reports whether every use of fn is a direct call in this package:
+// fn is unexported and never assigned, passed or taken as a method value.
+// A method may also satisfy an interface without being named, which Local
+// cannot see.
+func (c *Calls) Local(fn *types.Func) bool {
+	return fn != nil && !fn.Exported() && !c.asValue[fn]
+}
+
+// Args returns the arguments of every call to fn in the package.
+func (c *Calls) Args(fn *types.Func) [][]ast.Expr { return c.args[fn] }
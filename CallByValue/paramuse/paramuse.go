@@ -0,0 +1,132 @@
+// Package paramuse finds where a function reads and writes one of its
+// parameters. It is shared by the CallByValue analyzers, which all ask the
+// same question: does anything read this parameter after it was assigned?
+package paramuse
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Use is one reference to a parameter in a function body, with the nodes
+// enclosing it, innermost last.
+type Use struct {
+	ID    *ast.Ident
+	Stack []ast.Node
+}
+
+// Parent returns the node directly enclosing the reference.
+func (u Use) Parent() ast.Node {
+	return u.Stack[len(u.Stack)-1]
+}
+
+// Find returns every reference to v in body. ok is false when v is
+// captured by a function literal or has its address taken: then it may be
+// read at any time, and no use can be called the last one.
+func Find(info *types.Info, body *ast.BlockStmt, v *types.Var) (uses []Use, ok bool) {
+	var stack []ast.Node
+	ast.Inspect(body, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		if id, ok := n.(*ast.Ident); ok && info.Uses[id] == v {
+			uses = append(uses, Use{id, append([]ast.Node(nil), stack...)})
+		}
+		stack = append(stack, n)
+		return true
+	})
+
+	for _, u := range uses {
+		for _, n := range u.Stack {
+			if _, ok := n.(*ast.FuncLit); ok {
+				return uses, false
+			}
+		}
+		if un, ok := u.Parent().(*ast.UnaryExpr); ok && un.Op == token.AND {
+			return uses, false
+		}
+	}
+	return uses, true
+}
+
+// Assignment returns the plain single-value assignment "v = expr" that u
+// is the left-hand side of, if any.
+func Assignment(u Use) (*ast.AssignStmt, bool) {
+	as, ok := u.Parent().(*ast.AssignStmt)
+	if !ok || as.Tok != token.ASSIGN || len(as.Lhs) != 1 || len(as.Rhs) != 1 || as.Lhs[0] != u.ID {
+		return nil, false
+	}
+	return as, true
+}
+
+// ReadAfter reports whether the parameter assigned by as (the assignment
+// of use at) is read after it: further down the function or, when as is
+// inside a loop, anywhere else in that loop.
+func ReadAfter(uses []Use, at Use, as *ast.AssignStmt) bool {
+	for _, u := range uses {
+		if isWrite(u) {
+			continue
+		}
+		if u.ID.Pos() > as.End() {
+			return true
+		}
+		if u.ID.Pos() >= as.Pos() && u.ID.End() <= as.End() {
+			continue // the assignment's own right-hand side
+		}
+		for _, n := range at.Stack {
+			switch n.(type) {
+			case *ast.ForStmt, *ast.RangeStmt:
+				if u.ID.Pos() >= n.Pos() && u.ID.End() <= n.End() {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// isWrite reports whether u is the left-hand side of a plain assignment,
+// which writes the parameter without reading it.
+func isWrite(u Use) bool {
+	as, ok := u.Parent().(*ast.AssignStmt)
+	if !ok || as.Tok != token.ASSIGN {
+		return false
+	}
+	for _, l := range as.Lhs {
+		if l == u.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// DerefEdits turns the parameter declared by field from a T into a *T: it
+// adds a star to the type and dereferences every use, with parentheses
+// where a selector, index or call would otherwise bind tighter.
+//
+// ok is false when field declares several names, as in (a, b []int): the
+// star would change all of them, but only one has its uses rewritten.
+// Callers are not edited; they must pass an address themselves.
+func DerefEdits(field *ast.Field, uses []Use) (edits []analysis.TextEdit, ok bool) {
+	if len(field.Names) > 1 {
+		return nil, false
+	}
+	edits = []analysis.TextEdit{{Pos: field.Type.Pos(), End: field.Type.Pos(), NewText: []byte("*")}}
+	for _, u := range uses {
+		text := "*" + u.ID.Name
+		switch parent := u.Parent().(type) {
+		case *ast.IndexExpr, *ast.IndexListExpr, *ast.SliceExpr, *ast.SelectorExpr:
+			text = "(" + text + ")"
+		case *ast.CallExpr:
+			if parent.Fun == u.ID {
+				text = "(" + text + ")"
+			}
+		}
+		edits = append(edits, analysis.TextEdit{Pos: u.ID.Pos(), End: u.ID.End(), NewText: []byte(text)})
+	}
+	return edits, true
+}
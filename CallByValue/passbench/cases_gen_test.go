@@ -0,0 +1,881 @@
+// Code generated by "go run ./gen"; DO NOT EDIT.
+
+package passbench
+
+import "testing"
+
+// sinkSum keeps the callees' results alive.
+var sinkSum uint64
+
+// BenchmarkPass runs every case as a sub-benchmark named by Case.Name,
+// such as BenchmarkPass/pointer/inline/heap/bytes=64.
+func BenchmarkPass(b *testing.B) {
+	cases := []struct {
+		c     Case
+		bench func(*testing.B)
+	}{
+		{Case{Size: 8, Pointer: false, Inline: false, Escape: false}, benchValueS8},
+		{Case{Size: 8, Pointer: true, Inline: false, Escape: false}, benchPointerS8},
+		{Case{Size: 8, Pointer: false, Inline: true, Escape: false}, benchValueInlS8},
+		{Case{Size: 8, Pointer: true, Inline: true, Escape: false}, benchPointerInlS8},
+		{Case{Size: 8, Pointer: false, Inline: false, Escape: true}, benchKeepValueS8},
+		{Case{Size: 8, Pointer: true, Inline: false, Escape: true}, benchKeepPointerS8},
+		{Case{Size: 8, Pointer: false, Inline: true, Escape: true}, benchKeepValueInlS8},
+		{Case{Size: 8, Pointer: true, Inline: true, Escape: true}, benchKeepPointerInlS8},
+		{Case{Size: 16, Pointer: false, Inline: false, Escape: false}, benchValueS16},
+		{Case{Size: 16, Pointer: true, Inline: false, Escape: false}, benchPointerS16},
+		{Case{Size: 16, Pointer: false, Inline: true, Escape: false}, benchValueInlS16},
+		{Case{Size: 16, Pointer: true, Inline: true, Escape: false}, benchPointerInlS16},
+		{Case{Size: 16, Pointer: false, Inline: false, Escape: true}, benchKeepValueS16},
+		{Case{Size: 16, Pointer: true, Inline: false, Escape: true}, benchKeepPointerS16},
+		{Case{Size: 16, Pointer: false, Inline: true, Escape: true}, benchKeepValueInlS16},
+		{Case{Size: 16, Pointer: true, Inline: true, Escape: true}, benchKeepPointerInlS16},
+		{Case{Size: 64, Pointer: false, Inline: false, Escape: false}, benchValueS64},
+		{Case{Size: 64, Pointer: true, Inline: false, Escape: false}, benchPointerS64},
+		{Case{Size: 64, Pointer: false, Inline: true, Escape: false}, benchValueInlS64},
+		{Case{Size: 64, Pointer: true, Inline: true, Escape: false}, benchPointerInlS64},
+		{Case{Size: 64, Pointer: false, Inline: false, Escape: true}, benchKeepValueS64},
+		{Case{Size: 64, Pointer: true, Inline: false, Escape: true}, benchKeepPointerS64},
+		{Case{Size: 64, Pointer: false, Inline: true, Escape: true}, benchKeepValueInlS64},
+		{Case{Size: 64, Pointer: true, Inline: true, Escape: true}, benchKeepPointerInlS64},
+		{Case{Size: 256, Pointer: false, Inline: false, Escape: false}, benchValueS256},
+		{Case{Size: 256, Pointer: true, Inline: false, Escape: false}, benchPointerS256},
+		{Case{Size: 256, Pointer: false, Inline: true, Escape: false}, benchValueInlS256},
+		{Case{Size: 256, Pointer: true, Inline: true, Escape: false}, benchPointerInlS256},
+		{Case{Size: 256, Pointer: false, Inline: false, Escape: true}, benchKeepValueS256},
+		{Case{Size: 256, Pointer: true, Inline: false, Escape: true}, benchKeepPointerS256},
+		{Case{Size: 256, Pointer: false, Inline: true, Escape: true}, benchKeepValueInlS256},
+		{Case{Size: 256, Pointer: true, Inline: true, Escape: true}, benchKeepPointerInlS256},
+		{Case{Size: 1024, Pointer: false, Inline: false, Escape: false}, benchValueS1024},
+		{Case{Size: 1024, Pointer: true, Inline: false, Escape: false}, benchPointerS1024},
+		{Case{Size: 1024, Pointer: false, Inline: true, Escape: false}, benchValueInlS1024},
+		{Case{Size: 1024, Pointer: true, Inline: true, Escape: false}, benchPointerInlS1024},
+		{Case{Size: 1024, Pointer: false, Inline: false, Escape: true}, benchKeepValueS1024},
+		{Case{Size: 1024, Pointer: true, Inline: false, Escape: true}, benchKeepPointerS1024},
+		{Case{Size: 1024, Pointer: false, Inline: true, Escape: true}, benchKeepValueInlS1024},
+		{Case{Size: 1024, Pointer: true, Inline: true, Escape: true}, benchKeepPointerInlS1024},
+		{Case{Size: 4096, Pointer: false, Inline: false, Escape: false}, benchValueS4096},
+		{Case{Size: 4096, Pointer: true, Inline: false, Escape: false}, benchPointerS4096},
+		{Case{Size: 4096, Pointer: false, Inline: true, Escape: false}, benchValueInlS4096},
+		{Case{Size: 4096, Pointer: true, Inline: true, Escape: false}, benchPointerInlS4096},
+		{Case{Size: 4096, Pointer: false, Inline: false, Escape: true}, benchKeepValueS4096},
+		{Case{Size: 4096, Pointer: true, Inline: false, Escape: true}, benchKeepPointerS4096},
+		{Case{Size: 4096, Pointer: false, Inline: true, Escape: true}, benchKeepValueInlS4096},
+		{Case{Size: 4096, Pointer: true, Inline: true, Escape: true}, benchKeepPointerInlS4096},
+	}
+	for _, tc := range cases {
+		b.Run(tc.c.Name(), func(b *testing.B) {
+			b.ReportAllocs()
+			tc.bench(b)
+		})
+	}
+}
+
+// TestCallees checks that every callee reads or keeps the argument it is
+// given, whichever way it is passed.
+func TestCallees(t *testing.T) {
+	t.Run("S8", checkS8)
+	t.Run("S16", checkS16)
+	t.Run("S64", checkS64)
+	t.Run("S256", checkS256)
+	t.Run("S1024", checkS1024)
+	t.Run("S4096", checkS4096)
+}
+
+// ---- 8 bytes ----
+
+// S8 is a 8-byte struct.
+type S8 struct{ a [1]uint64 }
+
+var (
+	keptS8    S8
+	keptPtrS8 *S8
+)
+
+//go:noinline
+func valueS8(s S8) uint64 { return s.a[0] + s.a[len(s.a)-1] }
+
+func valueInlS8(s S8) uint64 { return s.a[0] + s.a[len(s.a)-1] }
+
+//go:noinline
+func pointerS8(p *S8) uint64 { return p.a[0] + p.a[len(p.a)-1] }
+
+func pointerInlS8(p *S8) uint64 { return p.a[0] + p.a[len(p.a)-1] }
+
+//go:noinline
+func keepValueS8(s S8) { keptS8 = s }
+
+func keepValueInlS8(s S8) { keptS8 = s }
+
+//go:noinline
+func keepPointerS8(p *S8) { keptPtrS8 = p }
+
+func keepPointerInlS8(p *S8) { keptPtrS8 = p }
+
+func benchValueS8(b *testing.B) {
+	var s S8
+	var sum uint64
+	for i := 0; b.Loop(); i++ {
+		s.a[0] = uint64(i)
+		sum += valueS8(s)
+	}
+	sinkSum = sum
+}
+
+func benchPointerS8(b *testing.B) {
+	var s S8
+	var sum uint64
+	for i := 0; b.Loop(); i++ {
+		s.a[0] = uint64(i)
+		sum += pointerS8(&s)
+	}
+	sinkSum = sum
+}
+
+func benchValueInlS8(b *testing.B) {
+	var s S8
+	var sum uint64
+	for i := 0; b.Loop(); i++ {
+		s.a[0] = uint64(i)
+		sum += valueInlS8(s)
+	}
+	sinkSum = sum
+}
+
+func benchPointerInlS8(b *testing.B) {
+	var s S8
+	var sum uint64
+	for i := 0; b.Loop(); i++ {
+		s.a[0] = uint64(i)
+		sum += pointerInlS8(&s)
+	}
+	sinkSum = sum
+}
+
+func benchKeepValueS8(b *testing.B) {
+	for i := 0; b.Loop(); i++ {
+		var s S8
+		s.a[0] = uint64(i)
+		keepValueS8(s)
+	}
+}
+
+func benchKeepPointerS8(b *testing.B) {
+	for i := 0; b.Loop(); i++ {
+		var s S8 // escapes: allocated on every iteration
+		s.a[0] = uint64(i)
+		keepPointerS8(&s)
+	}
+}
+
+func benchKeepValueInlS8(b *testing.B) {
+	for i := 0; b.Loop(); i++ {
+		var s S8
+		s.a[0] = uint64(i)
+		keepValueInlS8(s)
+	}
+}
+
+func benchKeepPointerInlS8(b *testing.B) {
+	for i := 0; b.Loop(); i++ {
+		var s S8 // escapes: allocated on every iteration
+		s.a[0] = uint64(i)
+		keepPointerInlS8(&s)
+	}
+}
+
+func checkS8(t *testing.T) {
+	var s S8
+	s.a[0] = 1
+	s.a[len(s.a)-1] += 2
+	want := s.a[0] + s.a[len(s.a)-1]
+	for _, got := range []uint64{valueS8(s), valueInlS8(s), pointerS8(&s), pointerInlS8(&s)} {
+		if got != want {
+			t.Errorf("callee returned %d, want %d", got, want)
+		}
+	}
+	keepValueS8(s)
+	if keptS8 != s {
+		t.Error("keepValueS8 did not keep its argument")
+	}
+	keptS8 = S8{}
+	keepValueInlS8(s)
+	if keptS8 != s {
+		t.Error("keepValueInlS8 did not keep its argument")
+	}
+	keepPointerS8(&s)
+	if keptPtrS8 != &s {
+		t.Error("keepPointerS8 did not keep its argument")
+	}
+	keptPtrS8 = nil
+	keepPointerInlS8(&s)
+	if keptPtrS8 != &s {
+		t.Error("keepPointerInlS8 did not keep its argument")
+	}
+	keptS8, keptPtrS8 = S8{}, nil
+}
+
+// ---- 16 bytes ----
+
+// S16 is a 16-byte struct.
+type S16 struct{ a [2]uint64 }
+
+var (
+	keptS16    S16
+	keptPtrS16 *S16
+)
+
+//go:noinline
+func valueS16(s S16) uint64 { return s.a[0] + s.a[len(s.a)-1] }
+
+func valueInlS16(s S16) uint64 { return s.a[0] + s.a[len(s.a)-1] }
+
+//go:noinline
+func pointerS16(p *S16) uint64 { return p.a[0] + p.a[len(p.a)-1] }
+
+func pointerInlS16(p *S16) uint64 { return p.a[0] + p.a[len(p.a)-1] }
+
+//go:noinline
+func keepValueS16(s S16) { keptS16 = s }
+
+func keepValueInlS16(s S16) { keptS16 = s }
+
+//go:noinline
+func keepPointerS16(p *S16) { keptPtrS16 = p }
+
+func keepPointerInlS16(p *S16) { keptPtrS16 = p }
+
+func benchValueS16(b *testing.B) {
+	var s S16
+	var sum uint64
+	for i := 0; b.Loop(); i++ {
+		s.a[0] = uint64(i)
+		sum += valueS16(s)
+	}
+	sinkSum = sum
+}
+
+func benchPointerS16(b *testing.B) {
+	var s S16
+	var sum uint64
+	for i := 0; b.Loop(); i++ {
+		s.a[0] = uint64(i)
+		sum += pointerS16(&s)
+	}
+	sinkSum = sum
+}
+
+func benchValueInlS16(b *testing.B) {
+	var s S16
+	var sum uint64
+	for i := 0; b.Loop(); i++ {
+		s.a[0] = uint64(i)
+		sum += valueInlS16(s)
+	}
+	sinkSum = sum
+}
+
+func benchPointerInlS16(b *testing.B) {
+	var s S16
+	var sum uint64
+	for i := 0; b.Loop(); i++ {
+		s.a[0] = uint64(i)
+		sum += pointerInlS16(&s)
+	}
+	sinkSum = sum
+}
+
+func benchKeepValueS16(b *testing.B) {
+	for i := 0; b.Loop(); i++ {
+		var s S16
+		s.a[0] = uint64(i)
+		keepValueS16(s)
+	}
+}
+
+func benchKeepPointerS16(b *testing.B) {
+	for i := 0; b.Loop(); i++ {
+		var s S16 // escapes: allocated on every iteration
+		s.a[0] = uint64(i)
+		keepPointerS16(&s)
+	}
+}
+
+func benchKeepValueInlS16(b *testing.B) {
+	for i := 0; b.Loop(); i++ {
+		var s S16
+		s.a[0] = uint64(i)
+		keepValueInlS16(s)
+	}
+}
+
+func benchKeepPointerInlS16(b *testing.B) {
+	for i := 0; b.Loop(); i++ {
+		var s S16 // escapes: allocated on every iteration
+		s.a[0] = uint64(i)
+		keepPointerInlS16(&s)
+	}
+}
+
+func checkS16(t *testing.T) {
+	var s S16
+	s.a[0] = 1
+	s.a[len(s.a)-1] += 2
+	want := s.a[0] + s.a[len(s.a)-1]
+	for _, got := range []uint64{valueS16(s), valueInlS16(s), pointerS16(&s), pointerInlS16(&s)} {
+		if got != want {
+			t.Errorf("callee returned %d, want %d", got, want)
+		}
+	}
+	keepValueS16(s)
+	if keptS16 != s {
+		t.Error("keepValueS16 did not keep its argument")
+	}
+	keptS16 = S16{}
+	keepValueInlS16(s)
+	if keptS16 != s {
+		t.Error("keepValueInlS16 did not keep its argument")
+	}
+	keepPointerS16(&s)
+	if keptPtrS16 != &s {
+		t.Error("keepPointerS16 did not keep its argument")
+	}
+	keptPtrS16 = nil
+	keepPointerInlS16(&s)
+	if keptPtrS16 != &s {
+		t.Error("keepPointerInlS16 did not keep its argument")
+	}
+	keptS16, keptPtrS16 = S16{}, nil
+}
+
+// ---- 64 bytes ----
+
+// S64 is a 64-byte struct.
+type S64 struct{ a [8]uint64 }
+
+var (
+	keptS64    S64
+	keptPtrS64 *S64
+)
+
+//go:noinline
+func valueS64(s S64) uint64 { return s.a[0] + s.a[len(s.a)-1] }
+
+func valueInlS64(s S64) uint64 { return s.a[0] + s.a[len(s.a)-1] }
+
+//go:noinline
+func pointerS64(p *S64) uint64 { return p.a[0] + p.a[len(p.a)-1] }
+
+func pointerInlS64(p *S64) uint64 { return p.a[0] + p.a[len(p.a)-1] }
+
+//go:noinline
+func keepValueS64(s S64) { keptS64 = s }
+
+func keepValueInlS64(s S64) { keptS64 = s }
+
+//go:noinline
+func keepPointerS64(p *S64) { keptPtrS64 = p }
+
+func keepPointerInlS64(p *S64) { keptPtrS64 = p }
+
+func benchValueS64(b *testing.B) {
+	var s S64
+	var sum uint64
+	for i := 0; b.Loop(); i++ {
+		s.a[0] = uint64(i)
+		sum += valueS64(s)
+	}
+	sinkSum = sum
+}
+
+func benchPointerS64(b *testing.B) {
+	var s S64
+	var sum uint64
+	for i := 0; b.Loop(); i++ {
+		s.a[0] = uint64(i)
+		sum += pointerS64(&s)
+	}
+	sinkSum = sum
+}
+
+func benchValueInlS64(b *testing.B) {
+	var s S64
+	var sum uint64
+	for i := 0; b.Loop(); i++ {
+		s.a[0] = uint64(i)
+		sum += valueInlS64(s)
+	}
+	sinkSum = sum
+}
+
+func benchPointerInlS64(b *testing.B) {
+	var s S64
+	var sum uint64
+	for i := 0; b.Loop(); i++ {
+		s.a[0] = uint64(i)
+		sum += pointerInlS64(&s)
+	}
+	sinkSum = sum
+}
+
+func benchKeepValueS64(b *testing.B) {
+	for i := 0; b.Loop(); i++ {
+		var s S64
+		s.a[0] = uint64(i)
+		keepValueS64(s)
+	}
+}
+
+func benchKeepPointerS64(b *testing.B) {
+	for i := 0; b.Loop(); i++ {
+		var s S64 // escapes: allocated on every iteration
+		s.a[0] = uint64(i)
+		keepPointerS64(&s)
+	}
+}
+
+func benchKeepValueInlS64(b *testing.B) {
+	for i := 0; b.Loop(); i++ {
+		var s S64
+		s.a[0] = uint64(i)
+		keepValueInlS64(s)
+	}
+}
+
+func benchKeepPointerInlS64(b *testing.B) {
+	for i := 0; b.Loop(); i++ {
+		var s S64 // escapes: allocated on every iteration
+		s.a[0] = uint64(i)
+		keepPointerInlS64(&s)
+	}
+}
+
+func checkS64(t *testing.T) {
+	var s S64
+	s.a[0] = 1
+	s.a[len(s.a)-1] += 2
+	want := s.a[0] + s.a[len(s.a)-1]
+	for _, got := range []uint64{valueS64(s), valueInlS64(s), pointerS64(&s), pointerInlS64(&s)} {
+		if got != want {
+			t.Errorf("callee returned %d, want %d", got, want)
+		}
+	}
+	keepValueS64(s)
+	if keptS64 != s {
+		t.Error("keepValueS64 did not keep its argument")
+	}
+	keptS64 = S64{}
+	keepValueInlS64(s)
+	if keptS64 != s {
+		t.Error("keepValueInlS64 did not keep its argument")
+	}
+	keepPointerS64(&s)
+	if keptPtrS64 != &s {
+		t.Error("keepPointerS64 did not keep its argument")
+	}
+	keptPtrS64 = nil
+	keepPointerInlS64(&s)
+	if keptPtrS64 != &s {
+		t.Error("keepPointerInlS64 did not keep its argument")
+	}
+	keptS64, keptPtrS64 = S64{}, nil
+}
+
+// ---- 256 bytes ----
+
+// S256 is a 256-byte struct.
+type S256 struct{ a [32]uint64 }
+
+var (
+	keptS256    S256
+	keptPtrS256 *S256
+)
+
+//go:noinline
+func valueS256(s S256) uint64 { return s.a[0] + s.a[len(s.a)-1] }
+
+func valueInlS256(s S256) uint64 { return s.a[0] + s.a[len(s.a)-1] }
+
+//go:noinline
+func pointerS256(p *S256) uint64 { return p.a[0] + p.a[len(p.a)-1] }
+
+func pointerInlS256(p *S256) uint64 { return p.a[0] + p.a[len(p.a)-1] }
+
+//go:noinline
+func keepValueS256(s S256) { keptS256 = s }
+
+func keepValueInlS256(s S256) { keptS256 = s }
+
+//go:noinline
+func keepPointerS256(p *S256) { keptPtrS256 = p }
+
+func keepPointerInlS256(p *S256) { keptPtrS256 = p }
+
+func benchValueS256(b *testing.B) {
+	var s S256
+	var sum uint64
+	for i := 0; b.Loop(); i++ {
+		s.a[0] = uint64(i)
+		sum += valueS256(s)
+	}
+	sinkSum = sum
+}
+
+func benchPointerS256(b *testing.B) {
+	var s S256
+	var sum uint64
+	for i := 0; b.Loop(); i++ {
+		s.a[0] = uint64(i)
+		sum += pointerS256(&s)
+	}
+	sinkSum = sum
+}
+
+func benchValueInlS256(b *testing.B) {
+	var s S256
+	var sum uint64
+	for i := 0; b.Loop(); i++ {
+		s.a[0] = uint64(i)
+		sum += valueInlS256(s)
+	}
+	sinkSum = sum
+}
+
+func benchPointerInlS256(b *testing.B) {
+	var s S256
+	var sum uint64
+	for i := 0; b.Loop(); i++ {
+		s.a[0] = uint64(i)
+		sum += pointerInlS256(&s)
+	}
+	sinkSum = sum
+}
+
+func benchKeepValueS256(b *testing.B) {
+	for i := 0; b.Loop(); i++ {
+		var s S256
+		s.a[0] = uint64(i)
+		keepValueS256(s)
+	}
+}
+
+func benchKeepPointerS256(b *testing.B) {
+	for i := 0; b.Loop(); i++ {
+		var s S256 // escapes: allocated on every iteration
+		s.a[0] = uint64(i)
+		keepPointerS256(&s)
+	}
+}
+
+func benchKeepValueInlS256(b *testing.B) {
+	for i := 0; b.Loop(); i++ {
+		var s S256
+		s.a[0] = uint64(i)
+		keepValueInlS256(s)
+	}
+}
+
+func benchKeepPointerInlS256(b *testing.B) {
+	for i := 0; b.Loop(); i++ {
+		var s S256 // escapes: allocated on every iteration
+		s.a[0] = uint64(i)
+		keepPointerInlS256(&s)
+	}
+}
+
+func checkS256(t *testing.T) {
+	var s S256
+	s.a[0] = 1
+	s.a[len(s.a)-1] += 2
+	want := s.a[0] + s.a[len(s.a)-1]
+	for _, got := range []uint64{valueS256(s), valueInlS256(s), pointerS256(&s), pointerInlS256(&s)} {
+		if got != want {
+			t.Errorf("callee returned %d, want %d", got, want)
+		}
+	}
+	keepValueS256(s)
+	if keptS256 != s {
+		t.Error("keepValueS256 did not keep its argument")
+	}
+	keptS256 = S256{}
+	keepValueInlS256(s)
+	if keptS256 != s {
+		t.Error("keepValueInlS256 did not keep its argument")
+	}
+	keepPointerS256(&s)
+	if keptPtrS256 != &s {
+		t.Error("keepPointerS256 did not keep its argument")
+	}
+	keptPtrS256 = nil
+	keepPointerInlS256(&s)
+	if keptPtrS256 != &s {
+		t.Error("keepPointerInlS256 did not keep its argument")
+	}
+	keptS256, keptPtrS256 = S256{}, nil
+}
+
+// ---- 1024 bytes ----
+
+// S1024 is a 1024-byte struct.
+type S1024 struct{ a [128]uint64 }
+
+var (
+	keptS1024    S1024
+	keptPtrS1024 *S1024
+)
+
+//go:noinline
+func valueS1024(s S1024) uint64 { return s.a[0] + s.a[len(s.a)-1] }
+
+func valueInlS1024(s S1024) uint64 { return s.a[0] + s.a[len(s.a)-1] }
+
+//go:noinline
+func pointerS1024(p *S1024) uint64 { return p.a[0] + p.a[len(p.a)-1] }
+
+func pointerInlS1024(p *S1024) uint64 { return p.a[0] + p.a[len(p.a)-1] }
+
+//go:noinline
+func keepValueS1024(s S1024) { keptS1024 = s }
+
+func keepValueInlS1024(s S1024) { keptS1024 = s }
+
+//go:noinline
+func keepPointerS1024(p *S1024) { keptPtrS1024 = p }
+
+func keepPointerInlS1024(p *S1024) { keptPtrS1024 = p }
+
+func benchValueS1024(b *testing.B) {
+	var s S1024
+	var sum uint64
+	for i := 0; b.Loop(); i++ {
+		s.a[0] = uint64(i)
+		sum += valueS1024(s)
+	}
+	sinkSum = sum
+}
+
+func benchPointerS1024(b *testing.B) {
+	var s S1024
+	var sum uint64
+	for i := 0; b.Loop(); i++ {
+		s.a[0] = uint64(i)
+		sum += pointerS1024(&s)
+	}
+	sinkSum = sum
+}
+
+func benchValueInlS1024(b *testing.B) {
+	var s S1024
+	var sum uint64
+	for i := 0; b.Loop(); i++ {
+		s.a[0] = uint64(i)
+		sum += valueInlS1024(s)
+	}
+	sinkSum = sum
+}
+
+func benchPointerInlS1024(b *testing.B) {
+	var s S1024
+	var sum uint64
+	for i := 0; b.Loop(); i++ {
+		s.a[0] = uint64(i)
+		sum += pointerInlS1024(&s)
+	}
+	sinkSum = sum
+}
+
+func benchKeepValueS1024(b *testing.B) {
+	for i := 0; b.Loop(); i++ {
+		var s S1024
+		s.a[0] = uint64(i)
+		keepValueS1024(s)
+	}
+}
+
+func benchKeepPointerS1024(b *testing.B) {
+	for i := 0; b.Loop(); i++ {
+		var s S1024 // escapes: allocated on every iteration
+		s.a[0] = uint64(i)
+		keepPointerS1024(&s)
+	}
+}
+
+func benchKeepValueInlS1024(b *testing.B) {
+	for i := 0; b.Loop(); i++ {
+		var s S1024
+		s.a[0] = uint64(i)
+		keepValueInlS1024(s)
+	}
+}
+
+func benchKeepPointerInlS1024(b *testing.B) {
+	for i := 0; b.Loop(); i++ {
+		var s S1024 // escapes: allocated on every iteration
+		s.a[0] = uint64(i)
+		keepPointerInlS1024(&s)
+	}
+}
+
+func checkS1024(t *testing.T) {
+	var s S1024
+	s.a[0] = 1
+	s.a[len(s.a)-1] += 2
+	want := s.a[0] + s.a[len(s.a)-1]
+	for _, got := range []uint64{valueS1024(s), valueInlS1024(s), pointerS1024(&s), pointerInlS1024(&s)} {
+		if got != want {
+			t.Errorf("callee returned %d, want %d", got, want)
+		}
+	}
+	keepValueS1024(s)
+	if keptS1024 != s {
+		t.Error("keepValueS1024 did not keep its argument")
+	}
+	keptS1024 = S1024{}
+	keepValueInlS1024(s)
+	if keptS1024 != s {
+		t.Error("keepValueInlS1024 did not keep its argument")
+	}
+	keepPointerS1024(&s)
+	if keptPtrS1024 != &s {
+		t.Error("keepPointerS1024 did not keep its argument")
+	}
+	keptPtrS1024 = nil
+	keepPointerInlS1024(&s)
+	if keptPtrS1024 != &s {
+		t.Error("keepPointerInlS1024 did not keep its argument")
+	}
+	keptS1024, keptPtrS1024 = S1024{}, nil
+}
+
+// ---- 4096 bytes ----
+
+// S4096 is a 4096-byte struct.
+type S4096 struct{ a [512]uint64 }
+
+var (
+	keptS4096    S4096
+	keptPtrS4096 *S4096
+)
+
+//go:noinline
+func valueS4096(s S4096) uint64 { return s.a[0] + s.a[len(s.a)-1] }
+
+func valueInlS4096(s S4096) uint64 { return s.a[0] + s.a[len(s.a)-1] }
+
+//go:noinline
+func pointerS4096(p *S4096) uint64 { return p.a[0] + p.a[len(p.a)-1] }
+
+func pointerInlS4096(p *S4096) uint64 { return p.a[0] + p.a[len(p.a)-1] }
+
+//go:noinline
+func keepValueS4096(s S4096) { keptS4096 = s }
+
+func keepValueInlS4096(s S4096) { keptS4096 = s }
+
+//go:noinline
+func keepPointerS4096(p *S4096) { keptPtrS4096 = p }
+
+func keepPointerInlS4096(p *S4096) { keptPtrS4096 = p }
+
+func benchValueS4096(b *testing.B) {
+	var s S4096
+	var sum uint64
+	for i := 0; b.Loop(); i++ {
+		s.a[0] = uint64(i)
+		sum += valueS4096(s)
+	}
+	sinkSum = sum
+}
+
+func benchPointerS4096(b *testing.B) {
+	var s S4096
+	var sum uint64
+	for i := 0; b.Loop(); i++ {
+		s.a[0] = uint64(i)
+		sum += pointerS4096(&s)
+	}
+	sinkSum = sum
+}
+
+func benchValueInlS4096(b *testing.B) {
+	var s S4096
+	var sum uint64
+	for i := 0; b.Loop(); i++ {
+		s.a[0] = uint64(i)
+		sum += valueInlS4096(s)
+	}
+	sinkSum = sum
+}
+
+func benchPointerInlS4096(b *testing.B) {
+	var s S4096
+	var sum uint64
+	for i := 0; b.Loop(); i++ {
+		s.a[0] = uint64(i)
+		sum += pointerInlS4096(&s)
+	}
+	sinkSum = sum
+}
+
+func benchKeepValueS4096(b *testing.B) {
+	for i := 0; b.Loop(); i++ {
+		var s S4096
+		s.a[0] = uint64(i)
+		keepValueS4096(s)
+	}
+}
+
+func benchKeepPointerS4096(b *testing.B) {
+	for i := 0; b.Loop(); i++ {
+		var s S4096 // escapes: allocated on every iteration
+		s.a[0] = uint64(i)
+		keepPointerS4096(&s)
+	}
+}
+
+func benchKeepValueInlS4096(b *testing.B) {
+	for i := 0; b.Loop(); i++ {
+		var s S4096
+		s.a[0] = uint64(i)
+		keepValueInlS4096(s)
+	}
+}
+
+func benchKeepPointerInlS4096(b *testing.B) {
+	for i := 0; b.Loop(); i++ {
+		var s S4096 // escapes: allocated on every iteration
+		s.a[0] = uint64(i)
+		keepPointerInlS4096(&s)
+	}
+}
+
+func checkS4096(t *testing.T) {
+	var s S4096
+	s.a[0] = 1
+	s.a[len(s.a)-1] += 2
+	want := s.a[0] + s.a[len(s.a)-1]
+	for _, got := range []uint64{valueS4096(s), valueInlS4096(s), pointerS4096(&s), pointerInlS4096(&s)} {
+		if got != want {
+			t.Errorf("callee returned %d, want %d", got, want)
+		}
+	}
+	keepValueS4096(s)
+	if keptS4096 != s {
+		t.Error("keepValueS4096 did not keep its argument")
+	}
+	keptS4096 = S4096{}
+	keepValueInlS4096(s)
+	if keptS4096 != s {
+		t.Error("keepValueInlS4096 did not keep its argument")
+	}
+	keepPointerS4096(&s)
+	if keptPtrS4096 != &s {
+		t.Error("keepPointerS4096 did not keep its argument")
+	}
+	keptPtrS4096 = nil
+	keepPointerInlS4096(&s)
+	if keptPtrS4096 != &s {
+		t.Error("keepPointerInlS4096 did not keep its argument")
+	}
+	keptS4096, keptPtrS4096 = S4096{}, nil
+}
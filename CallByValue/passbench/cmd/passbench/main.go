@@ -0,0 +1,31 @@
+// Command passbench reads the output of package passbench's benchmarks on
+// standard input and prints a table per call shape, comparing passing a
+// struct by value with passing a pointer to it, with the crossover size:
+//
+//	go test -run '^$' -bench Pass ./CallByValue/passbench | go run ./CallByValue/passbench/cmd/passbench
+//	go test -run '^$' -bench 'Pass/.*/noinline/stack' -count 5 ./CallByValue/passbench | go run ./CallByValue/passbench/cmd/passbench
+//
+// Copying is cheap until it is not, and an inlined callee often copies
+// nothing at all. A pointer that makes the caller's value escape costs a
+// heap allocation, which can cost more than copying the struct.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"Lets-GO/CallByValue/passbench"
+)
+
+func main() {
+	results, err := passbench.Parse(os.Stdin)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if len(results) == 0 {
+		fmt.Fprintln(os.Stderr, "passbench: no BenchmarkPass lines on standard input")
+		os.Exit(1)
+	}
+	passbench.Report(os.Stdout, results)
+}
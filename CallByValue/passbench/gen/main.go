@@ -0,0 +1,193 @@
+// Command gen writes the benchmarks of package passbench to
+// cases_gen_test.go, one set of types, callees and sub-benchmarks of
+// BenchmarkPass per struct size:
+//
+//	go generate ./CallByValue/passbench
+//
+// The code is generated rather than generic because the compiler shares
+// one copy of a generic function between types of the same shape and
+// passes a dictionary, which is not what a call with a concrete struct
+// costs, and because an array length cannot be a type parameter.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// sizes are the struct sizes benchmarked, in bytes.
+var sizes = []int{8, 16, 64, 256, 1024, 4096}
+
+// variant is one way of calling: the callee's name prefix says how the
+// argument is passed, whether the callee may be inlined and whether it
+// keeps its argument.
+type variant struct {
+	Callee                  string
+	Pointer, Inline, Escape bool
+}
+
+// Bench returns the name of the benchmark function for v, without the size.
+func (v variant) Bench() string {
+	return "bench" + strings.ToUpper(v.Callee[:1]) + v.Callee[1:]
+}
+
+var variants = []variant{
+	{"value", false, false, false},
+	{"pointer", true, false, false},
+	{"valueInl", false, true, false},
+	{"pointerInl", true, true, false},
+	{"keepValue", false, false, true},
+	{"keepPointer", true, false, true},
+	{"keepValueInl", false, true, true},
+	{"keepPointerInl", true, true, true},
+}
+
+func main() {
+	out := flag.String("o", "cases_gen_test.go", "output file")
+	flag.Parse()
+
+	type size struct {
+		Bytes, Words int
+		Variants     []variant
+	}
+	var data []size
+	for _, n := range sizes {
+		data = append(data, size{n, n / 8, variants})
+	}
+
+	var buf bytes.Buffer
+	if err := casesTemplate.Execute(&buf, data); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+var casesTemplate = template.Must(template.New("cases").Parse(`// Code generated by "go run ./gen"; DO NOT EDIT.
+
+package passbench
+
+import "testing"
+
+// sinkSum keeps the callees' results alive.
+var sinkSum uint64
+
+// BenchmarkPass runs every case as a sub-benchmark named by Case.Name,
+// such as BenchmarkPass/pointer/inline/heap/bytes=64.
+func BenchmarkPass(b *testing.B) {
+	cases := []struct {
+		c     Case
+		bench func(*testing.B)
+	}{
+{{- range $s := .}}{{range .Variants}}
+		{Case{Size: {{$s.Bytes}}, Pointer: {{.Pointer}}, Inline: {{.Inline}}, Escape: {{.Escape}}}, {{.Bench}}S{{$s.Bytes}}},
+{{- end}}{{end}}
+	}
+	for _, tc := range cases {
+		b.Run(tc.c.Name(), func(b *testing.B) {
+			b.ReportAllocs()
+			tc.bench(b)
+		})
+	}
+}
+
+// TestCallees checks that every callee reads or keeps the argument it is
+// given, whichever way it is passed.
+func TestCallees(t *testing.T) {
+{{- range .}}
+	t.Run("S{{.Bytes}}", checkS{{.Bytes}})
+{{- end}}
+}
+{{range $s := .}}
+// ---- {{.Bytes}} bytes ----
+
+// S{{.Bytes}} is a {{.Bytes}}-byte struct.
+type S{{.Bytes}} struct{ a [{{.Words}}]uint64 }
+
+var (
+	keptS{{.Bytes}}    S{{.Bytes}}
+	keptPtrS{{.Bytes}} *S{{.Bytes}}
+)
+
+//go:noinline
+func valueS{{.Bytes}}(s S{{.Bytes}}) uint64 { return s.a[0] + s.a[len(s.a)-1] }
+
+func valueInlS{{.Bytes}}(s S{{.Bytes}}) uint64 { return s.a[0] + s.a[len(s.a)-1] }
+
+//go:noinline
+func pointerS{{.Bytes}}(p *S{{.Bytes}}) uint64 { return p.a[0] + p.a[len(p.a)-1] }
+
+func pointerInlS{{.Bytes}}(p *S{{.Bytes}}) uint64 { return p.a[0] + p.a[len(p.a)-1] }
+
+//go:noinline
+func keepValueS{{.Bytes}}(s S{{.Bytes}}) { keptS{{.Bytes}} = s }
+
+func keepValueInlS{{.Bytes}}(s S{{.Bytes}}) { keptS{{.Bytes}} = s }
+
+//go:noinline
+func keepPointerS{{.Bytes}}(p *S{{.Bytes}}) { keptPtrS{{.Bytes}} = p }
+
+func keepPointerInlS{{.Bytes}}(p *S{{.Bytes}}) { keptPtrS{{.Bytes}} = p }
+{{range .Variants}}
+func {{.Bench}}S{{$s.Bytes}}(b *testing.B) {
+{{- if .Escape}}
+	for i := 0; b.Loop(); i++ {
+		var s S{{$s.Bytes}}{{if .Pointer}} // escapes: allocated on every iteration{{end}}
+		s.a[0] = uint64(i)
+		{{.Callee}}S{{$s.Bytes}}({{if .Pointer}}&{{end}}s)
+	}
+{{- else}}
+	var s S{{$s.Bytes}}
+	var sum uint64
+	for i := 0; b.Loop(); i++ {
+		s.a[0] = uint64(i)
+		sum += {{.Callee}}S{{$s.Bytes}}({{if .Pointer}}&{{end}}s)
+	}
+	sinkSum = sum
+{{- end}}
+}
+{{end}}
+func checkS{{.Bytes}}(t *testing.T) {
+	var s S{{.Bytes}}
+	s.a[0] = 1
+	s.a[len(s.a)-1] += 2
+	want := s.a[0] + s.a[len(s.a)-1]
+	for _, got := range []uint64{valueS{{.Bytes}}(s), valueInlS{{.Bytes}}(s), pointerS{{.Bytes}}(&s), pointerInlS{{.Bytes}}(&s)} {
+		if got != want {
+			t.Errorf("callee returned %d, want %d", got, want)
+		}
+	}
+	keepValueS{{.Bytes}}(s)
+	if keptS{{.Bytes}} != s {
+		t.Error("keepValueS{{.Bytes}} did not keep its argument")
+	}
+	keptS{{.Bytes}} = S{{.Bytes}}{}
+	keepValueInlS{{.Bytes}}(s)
+	if keptS{{.Bytes}} != s {
+		t.Error("keepValueInlS{{.Bytes}} did not keep its argument")
+	}
+	keepPointerS{{.Bytes}}(&s)
+	if keptPtrS{{.Bytes}} != &s {
+		t.Error("keepPointerS{{.Bytes}} did not keep its argument")
+	}
+	keptPtrS{{.Bytes}} = nil
+	keepPointerInlS{{.Bytes}}(&s)
+	if keptPtrS{{.Bytes}} != &s {
+		t.Error("keepPointerInlS{{.Bytes}} did not keep its argument")
+	}
+	keptS{{.Bytes}}, keptPtrS{{.Bytes}} = S{{.Bytes}}{}, nil
+}
+{{end}}`))
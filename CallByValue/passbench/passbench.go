@@ -0,0 +1,199 @@
+// Package passbench measures what passing a struct by value costs compared
+// with passing a pointer to it, for struct sizes from 8 to 4096 bytes.
+//
+// Every size is called four ways, each by value and by pointer:
+//
+//	noinline/stack  a //go:noinline callee reads two words of its argument
+//	inline/stack    the same callee, small enough to be inlined
+//	noinline/heap   a //go:noinline callee keeps its argument in a global
+//	inline/heap     the same, inlined
+//
+// In the stack cases the caller's struct lives in its frame whichever way
+// it is passed: by value the call copies it, by pointer it copies 8 bytes.
+// In the heap cases the callee keeps what it gets, so by value it copies
+// the struct once more into the global, while by pointer the caller's
+// struct escapes and is allocated on the heap on every iteration.
+//
+// S8 holds one word and is passed in a register. The larger structs hold
+// arrays, which the register ABI never assigns to registers, so they are
+// copied through memory; a struct of separate fields up to a few words
+// would still go in registers.
+//
+// The benchmarks are generated into cases_gen_test.go by the gen
+// command; run go generate after changing it. Parse and Report read their
+// output and find the crossover size:
+//
+//	go test -run '^$' -bench Pass ./CallByValue/passbench | go run ./CallByValue/passbench/cmd/passbench
+package passbench
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"Lets-GO/Make/benchsum"
+)
+
+//go:generate go run ./gen
+
+// Case is one benchmark of the matrix.
+type Case struct {
+	Size    int  // struct size in bytes
+	Pointer bool // pass *S rather than S
+	Inline  bool // the callee can be inlined
+	Escape  bool // the callee keeps its argument
+}
+
+// Group names the call shape of c, everything but the size and how the
+// argument is passed: "noinline/stack", "inline/heap" and so on.
+func (c Case) Group() string {
+	g := "noinline"
+	if c.Inline {
+		g = "inline"
+	}
+	if c.Escape {
+		return g + "/heap"
+	}
+	return g + "/stack"
+}
+
+// Name returns c's sub-benchmark name, such as "pointer/inline/heap/bytes=64".
+func (c Case) Name() string {
+	pass := "value"
+	if c.Pointer {
+		pass = "pointer"
+	}
+	return pass + "/" + c.Group() + "/bytes=" + strconv.Itoa(c.Size)
+}
+
+// parseCase is the inverse of Name, given a name without its
+// "BenchmarkPass/" prefix.
+func parseCase(name string) (Case, bool) {
+	parts := strings.Split(name, "/")
+	if len(parts) != 4 || !strings.HasPrefix(parts[3], "bytes=") {
+		return Case{}, false
+	}
+	size, err := strconv.Atoi(strings.TrimPrefix(parts[3], "bytes="))
+	if err != nil {
+		return Case{}, false
+	}
+	c := Case{Size: size, Pointer: parts[0] == "pointer", Inline: parts[1] == "inline", Escape: parts[2] == "heap"}
+	if c.Name() != name {
+		return Case{}, false
+	}
+	return c, true
+}
+
+// Result is the measurement of one case.
+type Result struct {
+	Case
+	NsPerOp     float64
+	AllocsPerOp float64
+}
+
+// Parse reads "go test -bench" output and returns the BenchmarkPass
+// results in the order they first appear, ignoring everything else. When a
+// case appears several times (go test -count), the means are used.
+func Parse(r io.Reader) ([]Result, error) {
+	lines, err := benchsum.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	var results []Result
+	count := map[Case]int{}
+	at := map[Case]int{}
+	for _, l := range lines {
+		name, ok := strings.CutPrefix(l.Column, "Pass/")
+		if !ok {
+			continue
+		}
+		c, ok := parseCase(name + "/" + l.Row)
+		if !ok {
+			continue
+		}
+		i, ok := at[c]
+		if !ok {
+			i = len(results)
+			at[c] = i
+			results = append(results, Result{Case: c})
+		}
+		count[c]++
+		n := float64(count[c])
+		r := &results[i]
+		r.NsPerOp += (l.Values["ns/op"] - r.NsPerOp) / n
+		r.AllocsPerOp += (l.Values["allocs/op"] - r.AllocsPerOp) / n
+	}
+	return results, nil
+}
+
+// noise is the relative difference below which value and pointer count as
+// equally fast.
+const noise = 0.05
+
+// Report writes one table per call shape, comparing value and pointer at
+// every size, and the crossover: the smallest size from which passing a
+// pointer is faster, by more than noise, at every larger size measured.
+func Report(w io.Writer, results []Result) {
+	type pair struct{ value, pointer *Result }
+	var groups []string
+	bySize := map[string]map[int]*pair{}
+	var sizes []int
+	seen := map[int]bool{}
+	for i := range results {
+		r := &results[i]
+		g := r.Group()
+		if bySize[g] == nil {
+			groups = append(groups, g)
+			bySize[g] = map[int]*pair{}
+		}
+		p := bySize[g][r.Size]
+		if p == nil {
+			p = &pair{}
+			bySize[g][r.Size] = p
+		}
+		if r.Pointer {
+			p.pointer = r
+		} else {
+			p.value = r
+		}
+		if !seen[r.Size] {
+			seen[r.Size] = true
+			sizes = append(sizes, r.Size)
+		}
+	}
+
+	for _, g := range groups {
+		fmt.Fprintf(w, "\n%s\n", g)
+		fmt.Fprintf(w, "%8s %12s %12s %10s %14s\n", "bytes", "value ns", "pointer ns", "value/ptr", "ptr allocs/op")
+		crossover := -1 // index into sizes
+		same := true    // within noise at every size
+		for i, size := range sizes {
+			p := bySize[g][size]
+			if p == nil || p.value == nil || p.pointer == nil {
+				continue
+			}
+			ratio := p.value.NsPerOp / p.pointer.NsPerOp
+			fmt.Fprintf(w, "%8d %12.2f %12.2f %9.2fx %14.0f\n", size, p.value.NsPerOp, p.pointer.NsPerOp, ratio, p.pointer.AllocsPerOp)
+			if ratio < 1-noise || ratio > 1+noise {
+				same = false
+			}
+			switch {
+			case ratio <= 1+noise:
+				crossover = -1
+			case crossover < 0:
+				crossover = i
+			}
+		}
+		switch {
+		case same:
+			fmt.Fprintf(w, "crossover: none, value and pointer are within %.0f%% at every size\n", noise*100)
+		case crossover < 0:
+			fmt.Fprintln(w, "crossover: none, passing a pointer is not faster at the largest size measured")
+		case crossover == 0:
+			fmt.Fprintf(w, "crossover: none, passing a pointer is faster at every size from %d bytes\n", sizes[0])
+		default:
+			fmt.Fprintf(w, "crossover: passing a pointer is faster from %d bytes (by value up to %d)\n", sizes[crossover], sizes[crossover-1])
+		}
+	}
+}
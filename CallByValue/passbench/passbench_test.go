@@ -0,0 +1,54 @@
+package passbench
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const sample = `goos: linux
+goarch: amd64
+pkg: Lets-GO/CallByValue/passbench
+BenchmarkPass/value/noinline/stack/bytes=8-8         	1000000000	         1.000 ns/op	       0 B/op	       0 allocs/op
+BenchmarkPass/pointer/noinline/stack/bytes=8-8       	1000000000	         1.000 ns/op	       0 B/op	       0 allocs/op
+BenchmarkPass/value/noinline/stack/bytes=1024-8      	 50000000	        30.00 ns/op	       0 B/op	       0 allocs/op
+BenchmarkPass/pointer/noinline/stack/bytes=1024-8    	500000000	         2.000 ns/op	       0 B/op	       0 allocs/op
+BenchmarkPass/value/noinline/stack/bytes=1024-8      	 50000000	        40.00 ns/op	       0 B/op	       0 allocs/op
+BenchmarkPass/pointer/inline/heap/bytes=8-8          	100000000	        12.00 ns/op	       8 B/op	       1 allocs/op
+BenchmarkOther/n=8-8                                 	100000000	        12.00 ns/op
+PASS
+`
+
+func TestParse(t *testing.T) {
+	results, err := Parse(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Result{
+		{Case{Size: 8}, 1, 0},
+		{Case{Size: 8, Pointer: true}, 1, 0},
+		{Case{Size: 1024}, 35, 0}, // the mean of two runs
+		{Case{Size: 1024, Pointer: true}, 2, 0},
+		{Case{Size: 8, Pointer: true, Inline: true, Escape: true}, 12, 1},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d: %v", len(results), len(want), results)
+	}
+	for i := range want {
+		if results[i] != want[i] {
+			t.Errorf("result %d = %+v, want %+v", i, results[i], want[i])
+		}
+	}
+}
+
+func TestReportCrossover(t *testing.T) {
+	results, err := Parse(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	Report(&out, results)
+	if want := "crossover: passing a pointer is faster from 1024 bytes (by value up to 8)"; !strings.Contains(out.String(), want) {
+		t.Errorf("report lacks %q:\n%s", want, out.String())
+	}
+}
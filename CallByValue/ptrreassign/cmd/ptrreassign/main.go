@@ -0,0 +1,27 @@
+// Command ptrreassign reports assignments to pointer parameters that the
+// caller never sees.
+//
+// Run it on its own:
+//
+//	go run ./CallByValue/ptrreassign/cmd/ptrreassign ./...
+//
+// or as a vet tool:
+//
+//	go build -o ptrreassign ./CallByValue/ptrreassign/cmd/ptrreassign
+//	go vet -vettool=$(pwd)/ptrreassign ./...
+//
+// On this repository it reports failedUpdateNil and failedReassign in
+// CallByValue, which reassign their parameters on purpose.
+//
+// The analyzer's tests run with go test ./CallByValue/ptrreassign.
+package main
+
+import (
+	"Lets-GO/CallByValue/ptrreassign"
+
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(ptrreassign.Analyzer)
+}
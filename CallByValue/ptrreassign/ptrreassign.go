@@ -0,0 +1,188 @@
+// Package ptrreassign defines an analyzer that reports assignments to
+// pointer parameters that the caller never sees.
+//
+// failedUpdateNil and failedReassign in the CallByValue lesson show the
+// bug:
+//
+//	func failedReassign(px *int) {
+//		x2 := 20
+//		px = &x2 // the caller's pointer, and the int it points to, are unchanged
+//	}
+//
+// A pointer parameter is a copy of the caller's pointer. Assigning a new
+// address to it redirects only the copy. The pass reports p = v on a
+// pointer parameter or receiver when p is not used afterwards.
+//
+// There are two ways out: assign through the pointer (*p = x for p = &x),
+// which changes the value the caller points to, or, for parameters, take a
+// **T as setPtr does, which changes the caller's pointer itself. Only the
+// second works when the caller may pass nil, as in failedUpdateNil, but it
+// also changes every call site, so the message only describes it. The first
+// is a suggested fix: for a receiver, which is assumed to be non-nil as
+// methods usually do, and for a parameter only when every call in the
+// package passes &x or new(T) and the function cannot be called from
+// elsewhere.
+package ptrreassign
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"Lets-GO/CallByValue/paramuse"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const doc = `report assignments to pointer parameters that the caller never sees
+
+A pointer parameter holds a copy of the caller's pointer, so p = &x only
+changes where the copy points. Unless p is used afterwards, the assignment
+has no effect. The pass reports such assignments to pointer parameters and
+receivers. Where the pointer is known to be non-nil it suggests assigning
+through it (*p = x); the message also mentions taking a **T, which changes
+the caller's pointer itself but needs every call site edited.`
+
+// Analyzer reports ineffectual assignments to pointer parameters and
+// receivers.
+var Analyzer = &analysis.Analyzer{
+	Name:     "ptrreassign",
+	Doc:      doc,
+	URL:      "https://go.dev/doc/faq#pass_by_value",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// fixDeref is the message of the suggested fix.
+const fixDeref = "Assign through the pointer"
+
+func run(pass *analysis.Pass) (any, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	calls := paramuse.CollectCalls(pass, insp)
+	nodeFilter := []ast.Node{(*ast.FuncDecl)(nil)}
+	insp.Preorder(nodeFilter, func(n ast.Node) {
+		fd := n.(*ast.FuncDecl)
+		if fd.Body == nil {
+			return
+		}
+		if fd.Recv != nil {
+			for _, p := range params(pass, fd.Recv) {
+				checkParam(pass, fd, p, true, true)
+			}
+		}
+		fn, _ := pass.TypesInfo.Defs[fd.Name].(*types.Func)
+		for _, p := range params(pass, fd.Type.Params) {
+			checkParam(pass, fd, p, false, passAddress(pass, calls, fn, p.index))
+		}
+	})
+	return nil, nil
+}
+
+// passAddress reports whether every call of fn passes &x or new(T) as its
+// i-th argument, so that the parameter is never nil. A method may be called
+// through an interface, with arguments the package does not show.
+func passAddress(pass *analysis.Pass, calls *paramuse.Calls, fn *types.Func, i int) bool {
+	if !calls.Local(fn) || fn.Signature().Recv() != nil || len(calls.Args(fn)) == 0 {
+		return false
+	}
+	for _, args := range calls.Args(fn) {
+		if i >= len(args) {
+			return false
+		}
+		switch arg := ast.Unparen(args[i]).(type) {
+		case *ast.UnaryExpr:
+			if arg.Op != token.AND {
+				return false
+			}
+		case *ast.CallExpr:
+			id, _ := ast.Unparen(arg.Fun).(*ast.Ident)
+			if b, ok := pass.TypesInfo.Uses[id].(*types.Builtin); !ok || b.Name() != "new" {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// param is a named parameter or receiver of pointer type.
+type param struct {
+	id    *ast.Ident
+	v     *types.Var
+	field *ast.Field // the declaration, possibly of several names
+	index int        // position in the parameter list
+}
+
+func params(pass *analysis.Pass, fl *ast.FieldList) []param {
+	var ps []param
+	index := 0
+	for _, field := range fl.List {
+		if len(field.Names) == 0 {
+			index++
+		}
+		for _, id := range field.Names {
+			index++
+			v, ok := pass.TypesInfo.Defs[id].(*types.Var)
+			if !ok || id.Name == "_" {
+				continue
+			}
+			if _, ok := v.Type().Underlying().(*types.Pointer); ok {
+				ps = append(ps, param{id, v, field, index - 1})
+			}
+		}
+	}
+	return ps
+}
+
+// checkParam reports lost assignments to p. nonNil says whether p is known
+// never to be nil, so that assigning through it is safe.
+func checkParam(pass *analysis.Pass, fd *ast.FuncDecl, p param, recv, nonNil bool) {
+	uses, ok := paramuse.Find(pass.TypesInfo, fd.Body, p.v)
+	if !ok {
+		return
+	}
+	for _, u := range uses {
+		as, ok := paramuse.Assignment(u)
+		if !ok || paramuse.ReadAfter(uses, u, as) {
+			continue
+		}
+		report(pass, p, recv, nonNil, as)
+	}
+}
+
+func report(pass *analysis.Pass, p param, recv, nonNil bool, as *ast.AssignStmt) {
+	name := p.id.Name
+	typ := types.ExprString(p.field.Type)
+	d := analysis.Diagnostic{
+		Pos: as.Pos(),
+		End: as.End(),
+	}
+	switch {
+	case recv:
+		d.Message = fmt.Sprintf("assignment to receiver %s has no effect on the caller: it changes only the local copy of the pointer; assign through it (*%s = ...)",
+			name, name)
+	case nonNil:
+		d.Message = fmt.Sprintf("assignment to pointer parameter %s has no effect on the caller: it changes only the local copy of the pointer; assign through it (*%s = ...) or take *%s",
+			name, name, typ)
+	default:
+		d.Message = fmt.Sprintf("assignment to pointer parameter %s has no effect on the caller: it changes only the local copy of the pointer; take *%s, or assign through it (*%s = ...) if callers never pass nil",
+			name, typ, name)
+	}
+
+	// p = &x becomes *p = x, which panics if p is nil.
+	if addr, ok := ast.Unparen(as.Rhs[0]).(*ast.UnaryExpr); ok && addr.Op == token.AND && nonNil {
+		d.SuggestedFixes = append(d.SuggestedFixes, analysis.SuggestedFix{
+			Message: fixDeref,
+			TextEdits: []analysis.TextEdit{
+				{Pos: as.Lhs[0].Pos(), End: as.Lhs[0].Pos(), NewText: []byte("*")},
+				{Pos: as.Rhs[0].Pos(), End: addr.X.Pos()},
+			},
+		})
+	}
+	pass.Report(d)
+}
@@ -0,0 +1,13 @@
+package ptrreassign_test
+
+import (
+	"testing"
+
+	"Lets-GO/CallByValue/ptrreassign"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), ptrreassign.Analyzer, "ptrreassign")
+}
@@ -0,0 +1,34 @@
+package ptrreassign
+
+// failedUpdateNil from the CallByValue lesson: the caller's nil stays nil.
+func failedUpdateNil(g *int) {
+	x := 10
+	g = &x // want `assignment to pointer parameter g has no effect on the caller: it changes only the local copy of the pointer; take \*\*int, or assign through it \(\*g = \.\.\.\) if callers never pass nil`
+}
+
+// setPtr is the lesson's fix for a nil pointer.
+func setPtr(pp **int) {
+	x := 10
+	*pp = &x
+}
+
+// failedReassign from the lesson: the caller's int stays unchanged.
+func failedReassign(px *int) {
+	x2 := 20
+	px = &x2 // want `assignment to pointer parameter px has no effect on the caller`
+}
+
+// updateValue is the lesson's fix for a non-nil pointer.
+func updateValue(px *int) {
+	*px = 20
+}
+
+// The calls of the lesson's main: f is nil, &x is not.
+func lesson() {
+	var f *int
+	failedUpdateNil(f)
+	setPtr(&f)
+	x := 10
+	failedReassign(&x)
+	updateValue(&x)
+}
@@ -0,0 +1,16 @@
+package ptrreassign
+
+type Config struct {
+	Name    string
+	Retries int
+}
+
+// Reset points the receiver copy at a new Config; the caller's is kept.
+func (c *Config) Reset() {
+	c = &Config{Retries: 3} // want `assignment to receiver c has no effect on the caller: it changes only the local copy of the pointer; assign through it \(\*c = \.\.\.\)$`
+}
+
+// Clear assigns through the receiver.
+func (c *Config) Clear() {
+	*c = Config{}
+}
@@ -0,0 +1,47 @@
+package ptrreassign
+
+import "fmt"
+
+type node struct {
+	v    int
+	next *node
+}
+
+// Walking a list reassigns n, and the loop reads it again.
+func sum(n *node) int {
+	total := 0
+	for n != nil {
+		total += n.v
+		n = n.next
+	}
+	return total
+}
+
+// A default is used after it is assigned.
+func retries(c *Config) int {
+	if c == nil {
+		c = &Config{Retries: 1}
+	}
+	return c.Retries
+}
+
+// A closure may read p later.
+func later(p *int) func() int {
+	f := func() int { return *p }
+	p = new(int)
+	return f
+}
+
+// Both assignments are lost. There is no address to dereference, so
+// neither gets a fix.
+func drop(p *int, q *int) {
+	fmt.Println(*p)
+	p = q   // want `assignment to pointer parameter p has no effect on the caller`
+	q = nil // want `assignment to pointer parameter q has no effect on the caller`
+}
+
+// p and q share one declaration: a **int would change both, so no fix.
+func pair(p, q *int) {
+	fmt.Println(*p, *q)
+	p = q // want `assignment to pointer parameter p has no effect on the caller`
+}
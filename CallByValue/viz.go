@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"Lets-GO/CallByValue/memviz"
+)
+
+// ----------------------------------------
+// Stepping through memory
+// ----------------------------------------
+//
+// memviz.Lesson re-runs the lesson's calls on a memviz.Machine, one
+// statement at a time, so that every step can be drawn: main's frame, the
+// callee's frame, and the heap objects both can reach. Variables and cells
+// a statement writes are highlighted. go test ./CallByValue/memviz checks
+// the text drawings against a golden file.
+//
+//	go run ./CallByValue -viz text               every step of every call
+//	go run ./CallByValue -viz step               wait for Enter between steps
+//	go run ./CallByValue -viz svg -out viz       one SVG per step, and an index.html
+//	go run ./CallByValue -viz text -call setPtr  just one call
+
+// runViz draws the steps of the calls whose name contains filter: as text,
+// as text one step per Enter, or as SVG files in outDir.
+func runViz(how, filter, outDir string) error {
+	var calls []memviz.LessonCall
+	for _, c := range memviz.Lesson {
+		if strings.Contains(c.Name, filter) {
+			calls = append(calls, c)
+		}
+	}
+	if len(calls) == 0 {
+		return fmt.Errorf("no call matches %q", filter)
+	}
+
+	switch how {
+	case "text", "step":
+		in := bufio.NewReader(os.Stdin)
+		for _, c := range calls {
+			m := memviz.New()
+			c.Run(m)
+			fmt.Printf("=== %s ===\n\n", c.Name)
+			for i := range m.Steps {
+				if err := memviz.WriteText(os.Stdout, m.Steps, i); err != nil {
+					return err
+				}
+				fmt.Println()
+				if how == "step" {
+					fmt.Print("[Enter: next step, q: quit] ")
+					line, err := in.ReadString('\n')
+					if err == io.EOF || strings.TrimSpace(line) == "q" {
+						return nil
+					}
+				}
+			}
+		}
+		return nil
+
+	case "svg":
+		if err := os.MkdirAll(outDir, 0o755); err != nil {
+			return err
+		}
+		var pages []memviz.Page
+		for _, c := range calls {
+			m := memviz.New()
+			c.Run(m)
+			for i := range m.Steps {
+				name := fmt.Sprintf("%s-%02d.svg", c.Name, i+1)
+				f, err := os.Create(filepath.Join(outDir, name))
+				if err != nil {
+					return err
+				}
+				if err := memviz.WriteSVG(f, m.Steps, i); err != nil {
+					f.Close()
+					return err
+				}
+				if err := f.Close(); err != nil {
+					return err
+				}
+				pages = append(pages, memviz.Page{Call: c.Name, File: name, Title: m.Steps[i].Title})
+			}
+		}
+		f, err := os.Create(filepath.Join(outDir, "index.html"))
+		if err != nil {
+			return err
+		}
+		if err := memviz.WriteIndex(f, "CallByValue, step by step", pages); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		fmt.Printf("wrote %d steps to %s\n", len(pages), filepath.Join(outDir, "index.html"))
+		return nil
+	}
+	return fmt.Errorf("unknown -viz %q (want text, step or svg)", how)
+}
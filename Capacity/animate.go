@@ -0,0 +1,279 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"Lets-GO/Capacity/sliceinfo"
+)
+
+// ----------------------------------------
+// Slice growth, animated
+// ----------------------------------------
+//
+// demoAppendGrowth prints one block of text per append. animateGrowth
+// redraws a single frame in place instead, showing every backing array the
+// slice has used as a row of cells:
+//
+//	live     the array the slice points to now: █ used (len), ░ spare (cap)
+//	garbage  arrays abandoned by earlier reallocations. Nothing points to
+//	         them any more; the garbage collector will reclaim them.
+//
+// On the frame of a reallocation, the copied cells are highlighted in the
+// new array.
+//
+// Keys: space play/pause, n or → one step, + and - change speed, q quits.
+// If stdin or stdout is not a terminal, every frame is printed one after
+// another as plain text instead.
+//
+// Run it with: go run ./Capacity -mode=animate [-cells 40]
+
+// animArray is one backing array the slice has used.
+type animArray struct {
+	addr uintptr
+	cap  int
+	used int // elements written while it was live
+}
+
+// animation is the state of the animated slice.
+type animation struct {
+	s      []int
+	total  int         // appends to perform
+	arrays []animArray // oldest first; the last one is live
+	copied int         // elements copied by the last append; -1 if none
+}
+
+// step appends one element and updates the list of arrays.
+func (a *animation) step() {
+	old := sliceinfo.Inspect(a.s)
+	a.s = append(a.s, len(a.s))
+	escape = a.s
+	now := sliceinfo.Inspect(a.s)
+
+	a.copied = -1
+	if now.Data != old.Data {
+		a.arrays = append(a.arrays, animArray{addr: now.Data, cap: now.Cap})
+		a.copied = old.Len
+	}
+	a.arrays[len(a.arrays)-1].used = now.Len
+}
+
+func (a *animation) done() bool { return len(a.s) >= a.total }
+
+// ANSI escape sequences used by render and runInteractive.
+const (
+	ansiReset     = "\x1b[0m"
+	ansiDim       = "\x1b[2m"
+	ansiBold      = "\x1b[1m"
+	ansiBlue      = "\x1b[34m"
+	ansiYellow    = "\x1b[33m"
+	ansiGreen     = "\x1b[32m"
+	ansiHome      = "\x1b[H"
+	ansiClearDown = "\x1b[J"
+	ansiClearLine = "\x1b[K"
+	ansiAltScreen = "\x1b[?1049h"
+	ansiMainScr   = "\x1b[?1049l"
+	ansiHideCur   = "\x1b[?25l"
+	ansiShowCur   = "\x1b[?25h"
+)
+
+// maxGarbageShown bounds the garbage arrays drawn; older ones are counted.
+const maxGarbageShown = 5
+
+// cellsPerLine wraps long arrays.
+const cellsPerLine = 64
+
+// render returns one frame. With color off it uses no escape sequences.
+func (a *animation) render(color bool) string {
+	paint := func(code, s string) string {
+		if !color || s == "" {
+			return s
+		}
+		return code + s + ansiReset
+	}
+
+	var b strings.Builder
+	h := sliceinfo.Inspect(a.s)
+	fmt.Fprintf(&b, "%s\n\n", paint(ansiBold, fmt.Sprintf("append #%d   len=%d cap=%d   data=%#x", len(a.s), h.Len, h.Cap, h.Data)))
+
+	switch {
+	case len(a.s) == 0:
+		b.WriteString("nil slice: no backing array yet\n")
+	case a.copied == 0:
+		b.WriteString(paint(ansiGreen, "first backing array allocated") + "\n")
+	case a.copied > 0:
+		b.WriteString(paint(ansiYellow, fmt.Sprintf("REALLOCATED: cap %d was full, %d elements (%d bytes) copied to a new array",
+			a.arrays[len(a.arrays)-2].cap, a.copied, a.copied*int(h.ElemSize))) + "\n")
+	default:
+		b.WriteString("fits: written in place, no allocation\n")
+	}
+	b.WriteString("\n")
+
+	// The live array. Cells copied by this append are highlighted.
+	if len(a.arrays) > 0 {
+		live := a.arrays[len(a.arrays)-1]
+		var cells []string
+		for i := range live.cap {
+			switch {
+			case i < a.copied:
+				cells = append(cells, paint(ansiYellow, "█"))
+			case i < live.used:
+				cells = append(cells, paint(ansiBlue, "█"))
+			default:
+				cells = append(cells, "░")
+			}
+		}
+		writeCells(&b, fmt.Sprintf("live     %#x cap %-5d", live.addr, live.cap), "", color, cells)
+	}
+
+	// Garbage arrays, newest first.
+	garbage := a.arrays[:max(len(a.arrays)-1, 0)]
+	for i := len(garbage) - 1; i >= max(len(garbage)-maxGarbageShown, 0); i-- {
+		g := garbage[i]
+		cells := make([]string, g.cap)
+		for j := range cells {
+			cells[j] = paint(ansiDim, "▒")
+		}
+		writeCells(&b, fmt.Sprintf("garbage  %#x cap %-5d", g.addr, g.cap), ansiDim, color, cells)
+	}
+	if hidden := len(garbage) - maxGarbageShown; hidden > 0 {
+		fmt.Fprintf(&b, "%s\n", paint(ansiDim, fmt.Sprintf("         (+%d older garbage arrays)", hidden)))
+	}
+
+	total := 0
+	for _, arr := range a.arrays {
+		total += arr.cap * int(h.ElemSize)
+	}
+	fmt.Fprintf(&b, "\nallocated so far: %d bytes in %d arrays; live array: %d bytes, %d of them in use\n",
+		total, len(a.arrays), h.Bytes(), h.Len*int(h.ElemSize))
+	return b.String()
+}
+
+// writeCells writes a label, painted with code if color is on, and a row
+// of cells, wrapping long rows under the first.
+func writeCells(b *strings.Builder, label, code string, color bool, cells []string) {
+	indent := strings.Repeat(" ", len(label)+1)
+	if color && code != "" {
+		label = code + label + ansiReset
+	}
+	b.WriteString(label)
+	for i := 0; i < len(cells); i += cellsPerLine {
+		if i == 0 {
+			b.WriteString(" ")
+		} else {
+			b.WriteString(indent)
+		}
+		b.WriteString(strings.Join(cells[i:min(i+cellsPerLine, len(cells))], ""))
+		b.WriteString("\n")
+	}
+	if len(cells) == 0 {
+		b.WriteString("\n")
+	}
+}
+
+// animateGrowth runs the animation for the given number of appends,
+// interactively if possible.
+func animateGrowth(appends int) error {
+	a := &animation{total: appends, copied: -1}
+	if !isTerminal(int(os.Stdin.Fd())) || !isTerminal(int(os.Stdout.Fd())) {
+		printFrames(os.Stdout, a)
+		return nil
+	}
+	restore, err := makeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		printFrames(os.Stdout, a)
+		return nil
+	}
+	defer restore()
+	return runInteractive(a)
+}
+
+// printFrames is the non-terminal fallback: every frame, in order.
+func printFrames(w io.Writer, a *animation) {
+	for !a.done() {
+		a.step()
+		fmt.Fprintln(w, strings.Repeat("-", 72))
+		fmt.Fprint(w, a.render(false))
+	}
+}
+
+// speeds are the delays between appends while playing, slowest first.
+var speeds = []time.Duration{2 * time.Second, time.Second, 500 * time.Millisecond, 250 * time.Millisecond, 100 * time.Millisecond, 40 * time.Millisecond}
+
+// runInteractive draws frames in place and reacts to keys. The terminal
+// must already be in raw mode.
+func runInteractive(a *animation) error {
+	out := os.Stdout
+	fmt.Fprint(out, ansiAltScreen+ansiHideCur)
+	defer fmt.Fprint(out, ansiShowCur+ansiMainScr)
+
+	keys := make(chan byte)
+	go readKeys(os.Stdin, keys)
+
+	playing, speed := false, 2
+	draw := func() {
+		status := "paused"
+		if playing {
+			status = "playing"
+		}
+		if a.done() {
+			status = "done"
+		}
+		frame := strings.ReplaceAll(a.render(true), "\n", ansiClearLine+"\r\n")
+		fmt.Fprintf(out, "%s%s\r\n[%s, %v per append]  space play/pause  n/→ step  +/- speed  q quit%s%s",
+			ansiHome, frame, status, speeds[speed], ansiClearLine, ansiClearDown)
+	}
+	draw()
+
+	for {
+		var tick <-chan time.Time
+		if playing && !a.done() {
+			tick = time.After(speeds[speed])
+		}
+		select {
+		case k, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			switch k {
+			case 'q', 'Q', 3: // 3 is Ctrl-C in raw mode
+				return nil
+			case ' ':
+				playing = !playing
+			case 'n', 'N', 'C': // 'C' ends the escape sequence of →
+				playing = false
+				if !a.done() {
+					a.step()
+				}
+			case '+', '=':
+				speed = min(speed+1, len(speeds)-1)
+			case '-', '_':
+				speed = max(speed-1, 0)
+			default:
+				continue
+			}
+		case <-tick:
+			a.step()
+		}
+		draw()
+	}
+}
+
+// readKeys sends every byte read from r to keys until a read fails,
+// io.EOF included, and then closes keys.
+func readKeys(r io.Reader, keys chan<- byte) {
+	defer close(keys)
+	buf := make([]byte, 16)
+	for {
+		n, err := r.Read(buf)
+		for _, c := range buf[:n] {
+			keys <- c
+		}
+		if err != nil {
+			return
+		}
+	}
+}
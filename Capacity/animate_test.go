@@ -0,0 +1,29 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadKeysStopsAtEOF(t *testing.T) {
+	keys := make(chan byte)
+	go readKeys(strings.NewReader("n q"), keys)
+
+	var got []byte
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case k, ok := <-keys:
+			if !ok {
+				if string(got) != "n q" {
+					t.Errorf("read %q, want %q", got, "n q")
+				}
+				return
+			}
+			got = append(got, k)
+		case <-timeout:
+			t.Fatal("readKeys did not close keys at end of input")
+		}
+	}
+}
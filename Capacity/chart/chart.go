@@ -0,0 +1,174 @@
+// Package chart draws simple line charts as self-contained SVG, using only
+// the standard library.
+//
+// It is made for the Capacity lesson's growth charts: a handful of series
+// with a few thousand points each, drawn as lines or as steps (capacity
+// jumps, it does not slope). The output has no scripts, fonts or external
+// references, so it can be pasted into slides or opened straight from disk.
+package chart
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Point is one data point.
+type Point struct{ X, Y float64 }
+
+// Series is one line of a chart.
+type Series struct {
+	Name   string
+	Color  string // any SVG color; a palette color is used if empty
+	Points []Point
+	Step   bool // draw as a step function: hold Y until the next X
+	Dashed bool
+}
+
+// Chart is a line chart with linear axes starting at zero.
+type Chart struct {
+	Title          string
+	XLabel, YLabel string
+	Width, Height  int // in pixels; 720×360 if zero
+	Series         []Series
+}
+
+// palette is used for series without a color. The colors stay apart for
+// the common kinds of color blindness.
+var palette = []string{"#0072b2", "#e69f00", "#009e73", "#cc79a7", "#d55e00", "#56b4e9", "#000000"}
+
+// margins around the plot area, in pixels.
+const (
+	marginLeft   = 70
+	marginRight  = 20
+	marginTop    = 40
+	marginBottom = 50
+)
+
+// WriteSVG writes c as a standalone SVG document.
+func (c *Chart) WriteSVG(w io.Writer) error {
+	_, err := io.WriteString(w, c.SVG())
+	return err
+}
+
+// SVG returns c as a standalone SVG document.
+func (c *Chart) SVG() string {
+	width, height := c.Width, c.Height
+	if width == 0 {
+		width = 720
+	}
+	if height == 0 {
+		height = 360
+	}
+	plotW := float64(width - marginLeft - marginRight)
+	plotH := float64(height - marginTop - marginBottom)
+
+	maxX, maxY := 0.0, 0.0
+	for _, s := range c.Series {
+		for _, p := range s.Points {
+			maxX = max(maxX, p.X)
+			maxY = max(maxY, p.Y)
+		}
+	}
+	xTicks := niceTicks(maxX)
+	yTicks := niceTicks(maxY)
+	maxX = xTicks[len(xTicks)-1]
+	maxY = yTicks[len(yTicks)-1]
+
+	px := func(x float64) float64 { return marginLeft + x/maxX*plotW }
+	py := func(y float64) float64 { return marginTop + plotH - y/maxY*plotH }
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12">`+"\n",
+		width, height, width, height)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="white"/>`+"\n", width, height)
+	fmt.Fprintf(&b, `<text x="%d" y="24" font-size="15" font-weight="bold">%s</text>`+"\n", marginLeft, esc(c.Title))
+
+	// Grid and tick labels.
+	for _, t := range yTicks {
+		y := py(t)
+		fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#e0e0e0"/>`+"\n", marginLeft, y, px(maxX), y)
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end" dominant-baseline="middle">%s</text>`+"\n", marginLeft-6, y, formatTick(t))
+	}
+	for _, t := range xTicks {
+		x := px(t)
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%d" x2="%.1f" y2="%.1f" stroke="#e0e0e0"/>`+"\n", x, marginTop, x, py(0))
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="middle">%s</text>`+"\n", x, py(0)+16, formatTick(t))
+	}
+	fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%.1f" height="%.1f" fill="none" stroke="#808080"/>`+"\n", marginLeft, marginTop, plotW, plotH)
+
+	// Axis labels.
+	fmt.Fprintf(&b, `<text x="%.1f" y="%d" text-anchor="middle">%s</text>`+"\n", marginLeft+plotW/2, height-10, esc(c.XLabel))
+	fmt.Fprintf(&b, `<text transform="translate(16 %.1f) rotate(-90)" text-anchor="middle">%s</text>`+"\n", marginTop+plotH/2, esc(c.YLabel))
+
+	// Series.
+	for i, s := range c.Series {
+		color := s.Color
+		if color == "" {
+			color = palette[i%len(palette)]
+		}
+		dash := ""
+		if s.Dashed {
+			dash = ` stroke-dasharray="6 4"`
+		}
+		fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" stroke-width="1.8"%s points="`, esc(color), dash)
+		for j, p := range s.Points {
+			if s.Step && j > 0 {
+				fmt.Fprintf(&b, "%.1f,%.1f ", px(p.X), py(s.Points[j-1].Y))
+			}
+			fmt.Fprintf(&b, "%.1f,%.1f ", px(p.X), py(p.Y))
+		}
+		b.WriteString("\"/>\n")
+
+		// Legend entry, top left inside the plot.
+		ly := marginTop + 14 + 16*i
+		fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="%s" stroke-width="2.5"%s/>`+"\n",
+			marginLeft+10, ly, marginLeft+34, ly, esc(color), dash)
+		fmt.Fprintf(&b, `<text x="%d" y="%d" dominant-baseline="middle">%s</text>`+"\n", marginLeft+40, ly, esc(s.Name))
+	}
+
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// niceTicks returns 0 and evenly spaced round numbers up to at least max:
+// steps of 1, 2 or 5 times a power of ten, about five of them.
+func niceTicks(max float64) []float64 {
+	if max <= 0 {
+		return []float64{0, 1}
+	}
+	raw := max / 5
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := mag
+	for _, f := range []float64{1, 2, 5, 10} {
+		if f*mag >= raw {
+			step = f * mag
+			break
+		}
+	}
+	var ticks []float64
+	for t := 0.0; ; t += step {
+		ticks = append(ticks, t)
+		if t >= max {
+			return ticks
+		}
+	}
+}
+
+// formatTick prints tick values without trailing zeros, using k and M for
+// thousands and millions.
+func formatTick(v float64) string {
+	switch {
+	case v >= 1e6 && math.Mod(v, 1e5) == 0:
+		return strconv.FormatFloat(v/1e6, 'f', -1, 64) + "M"
+	case v >= 1e3 && math.Mod(v, 1e2) == 0:
+		return strconv.FormatFloat(v/1e3, 'f', -1, 64) + "k"
+	default:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+}
+
+func esc(s string) string { return html.EscapeString(s) }
@@ -0,0 +1,60 @@
+package chart
+
+import (
+	"html/template"
+	"io"
+)
+
+// Section is one part of an HTML report: a heading, some text and charts.
+type Section struct {
+	Title  string
+	Text   string
+	Charts []*Chart
+}
+
+// WriteHTML writes a single-file HTML report with every chart inlined as
+// SVG.
+func WriteHTML(w io.Writer, title string, sections []Section) error {
+	type section struct {
+		Title string
+		Text  string
+		SVGs  []template.HTML
+	}
+	data := struct {
+		Title    string
+		Sections []section
+	}{Title: title}
+	for _, s := range sections {
+		sec := section{Title: s.Title, Text: s.Text}
+		for _, c := range s.Charts {
+			// SVG escapes every string it draws, so the markup is safe.
+			sec.SVGs = append(sec.SVGs, template.HTML(c.SVG()))
+		}
+		data.Sections = append(data.Sections, sec)
+	}
+	return reportTemplate.Execute(w, data)
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 1500px; margin: 2em auto; padding: 0 1em; color: #222; }
+.charts { display: flex; flex-wrap: wrap; gap: 12px; }
+.charts svg { border: 1px solid #ddd; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{range .Sections}}
+<h2>{{.Title}}</h2>
+<p>{{.Text}}</p>
+<div class="charts">
+{{range .SVGs}}{{.}}
+{{end}}</div>
+{{end}}
+</body>
+</html>
+`))
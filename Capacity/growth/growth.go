@@ -0,0 +1,149 @@
+// Package growth models how append picks the capacity of a new backing
+// array, exactly as the Go runtime does it (runtime.growslice).
+//
+// Growing a slice happens in two steps:
+//
+//  1. nextslicecap picks a target capacity from the old capacity and the
+//     length needed: double it while the slice is small, then grow by
+//     roughly 25% plus a constant, smoothing the transition at 256
+//     elements. If even doubling is not enough (a bulk append), the needed
+//     length itself is the target.
+//  2. The target is turned into a byte count and rounded up to the
+//     allocator's next size class (or to whole 8 KiB pages above 32 KiB).
+//     Whatever fits into the rounded block becomes the capacity.
+//
+// Step 2 is why the capacity after the same appends differs by element
+// type: three int64s need 24 bytes, a size class of its own, while three
+// 24-byte structs need 72 bytes and get an 80-byte block, room for three
+// and a third.
+//
+// The tables match Go 1.22 and later on 64-bit platforms. The runtime can
+// also start a non-escaping slice in a small stack buffer; the model
+// describes heap-allocated slices, which is what any slice that outlives its
+// function gets.
+package growth
+
+import (
+	"reflect"
+	"slices"
+)
+
+const (
+	threshold    = 256   // nextslicecap switches from 2x to ~1.25x here
+	maxSmallSize = 32768 // larger allocations are rounded to whole pages
+	pageSize     = 8192
+
+	// Small allocations of types with pointers larger than this carry an
+	// 8-byte header describing where the pointers are, which takes space
+	// from the size class (64-bit platforms).
+	minSizeForMallocHeader = 8 * 64
+	mallocHeaderSize       = 8
+)
+
+// SizeClasses are the byte sizes of the runtime's small object size
+// classes (internal/runtime/gc.SizeClassToSize, without class 0).
+var SizeClasses = []uintptr{
+	8, 16, 24, 32, 48, 64, 80, 96, 112, 128, 144, 160, 176, 192, 208, 224,
+	240, 256, 288, 320, 352, 384, 416, 448, 480, 512, 576, 640, 704, 768, 896,
+	1024, 1152, 1280, 1408, 1536, 1792, 2048, 2304, 2688, 3072, 3200, 3456,
+	4096, 4864, 5376, 6144, 6528, 6784, 6912, 8192, 9472, 9728, 10240, 10880,
+	12288, 13568, 14336, 16384, 18432, 19072, 20480, 21760, 24576, 27264,
+	28672, 32768,
+}
+
+// NextCap returns the capacity growslice aims for before size class
+// rounding, when a slice of capacity oldCap must hold newLen elements.
+func NextCap(newLen, oldCap int) int {
+	newCap := oldCap
+	doubleCap := newCap + newCap
+	if newLen > doubleCap {
+		return newLen
+	}
+	if oldCap < threshold {
+		return doubleCap
+	}
+	for newCap < newLen {
+		newCap += (newCap + 3*threshold) >> 2
+	}
+	return newCap
+}
+
+// RoundUpSize returns the size of the block the allocator hands out for a
+// request of size bytes. hasPointers matters because pointerful objects
+// above 512 bytes lose 8 bytes of their block to a malloc header.
+func RoundUpSize(size uintptr, hasPointers bool) uintptr {
+	if size > maxSmallSize-mallocHeaderSize {
+		return (size + pageSize - 1) &^ (pageSize - 1)
+	}
+	header := uintptr(0)
+	if hasPointers && size > minSizeForMallocHeader {
+		header = mallocHeaderSize
+	}
+	// The runtime uses lookup tables; every class above 1024 bytes is a
+	// multiple of 128, so "smallest class that fits" gives the same answer.
+	i, _ := slices.BinarySearch(SizeClasses, size+header)
+	return SizeClasses[i] - header
+}
+
+// Grow returns the capacity append gives a slice with capacity oldCap that
+// must grow to hold newLen elements of elemSize bytes. If newLen fits,
+// oldCap is returned unchanged.
+func Grow(oldCap, newLen int, elemSize uintptr, hasPointers bool) int {
+	if newLen <= oldCap {
+		return oldCap
+	}
+	if elemSize == 0 {
+		return newLen
+	}
+	target := NextCap(newLen, oldCap)
+	return int(RoundUpSize(uintptr(target)*elemSize, hasPointers) / elemSize)
+}
+
+// Model predicts the capacity of one slice as elements are appended.
+type Model struct {
+	ElemSize    uintptr
+	HasPointers bool
+	Len, Cap    int
+}
+
+// Append records an append of n elements and returns the new capacity and
+// whether a new backing array was allocated.
+func (m *Model) Append(n int) (newCap int, grew bool) {
+	m.Len += n
+	if m.Len > m.Cap {
+		m.Cap = Grow(m.Cap, m.Len, m.ElemSize, m.HasPointers)
+		grew = true
+	}
+	return m.Cap, grew
+}
+
+// Wasted returns the unused capacity in bytes.
+func (m *Model) Wasted() uintptr {
+	return uintptr(m.Cap-m.Len) * m.ElemSize
+}
+
+// For returns an empty Model for a slice of T.
+func For[T any]() Model {
+	t := reflect.TypeFor[T]()
+	return Model{ElemSize: t.Size(), HasPointers: HasPointers(t)}
+}
+
+// HasPointers reports whether values of type t contain pointers the
+// garbage collector must scan. Strings, slices, maps, channels, functions
+// and interfaces all do.
+func HasPointers(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.UnsafePointer, reflect.String, reflect.Slice,
+		reflect.Map, reflect.Chan, reflect.Func, reflect.Interface:
+		return true
+	case reflect.Array:
+		return t.Len() > 0 && HasPointers(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if HasPointers(t.Field(i).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package growth_test
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"Lets-GO/Capacity/growth"
+)
+
+// The model is meant to be exact, so the tests hold it to that: they
+// append to real slices of several element types and compare cap() with
+// the model after every single append.
+
+const steps = 5000
+
+// struct24 is a 24-byte element, the same size as a slice header.
+type struct24 struct{ a, b, c int64 }
+
+// ptr24 is a 24-byte element with a pointer in it. Arrays of it above 512
+// bytes pay for a malloc header, which changes the rounding.
+type ptr24 struct {
+	name string
+	n    int64
+}
+
+// escape keeps the slices under test on the heap. A slice that never
+// leaves its function may start in a small stack buffer, which the model
+// does not describe.
+var escape any
+
+func TestModelMatchesRuntime(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(steps, maxBatch int, bulk bool) error
+	}{
+		{"byte", checkModel[byte]},
+		{"int32", checkModel[int32]},
+		{"int64", checkModel[int64]},
+		{"struct24", checkModel[struct24]},
+		{"ptr24", checkModel[ptr24]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+"/one", func(t *testing.T) {
+			if err := tt.run(steps, 1, false); err != nil {
+				t.Error(err)
+			}
+		})
+		t.Run(tt.name+"/bulk", func(t *testing.T) {
+			if err := tt.run(steps, 64, true); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// checkModel appends steps times to a real []T and to a growth.Model and
+// compares their capacities after every append. With bulk set, each append
+// adds a random 1..maxBatch elements instead of maxBatch.
+func checkModel[T any](steps, maxBatch int, bulk bool) error {
+	var s []T
+	model := growth.For[T]()
+	r := rand.New(rand.NewPCG(1, 2))
+	batch := make([]T, maxBatch)
+	defer func() { escape = nil }()
+
+	for step := range steps {
+		n := maxBatch
+		if bulk {
+			n = 1 + r.IntN(maxBatch)
+		}
+		oldCap := cap(s)
+		s = append(s, batch[:n]...)
+		escape = s
+		want, _ := model.Append(n)
+		if cap(s) != want {
+			return fmt.Errorf("step %d: append %d to len=%d cap=%d: runtime cap=%d, model cap=%d",
+				step, n, len(s)-n, oldCap, cap(s), want)
+		}
+	}
+	return nil
+}
@@ -1,11 +1,52 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io"
+	"os"
 	"strings"
+
+	"Lets-GO/Capacity/growth"
+	"Lets-GO/Capacity/sliceinfo"
 )
 
 func main() {
+	mode := flag.String("mode", "demo", "demo, chart (write SVG/HTML growth charts) or animate")
+	traceFormat := flag.String("trace", "", "write the reallocation trace as json or csv instead of running the demo")
+	elem := flag.String("elem", "int", "trace: element type (byte, int32, int, struct24)")
+	steps := flag.Int("steps", 5000, "trace, chart: number of appends")
+	out := flag.String("out", "charts", "chart: output directory")
+	cells := flag.Int("cells", 40, "animate: number of appends to animate")
+	flag.Parse()
+
+	if *steps < 1 {
+		fmt.Fprintln(os.Stderr, "error: -steps must be at least 1")
+		os.Exit(2)
+	}
+
+	var err error
+	switch {
+	case *traceFormat != "":
+		err = writeTrace(os.Stdout, *traceFormat, *elem, *steps)
+	case *mode == "demo":
+		runDemo()
+	case *mode == "chart":
+		err = writeGrowthCharts(*out, *steps)
+	case *mode == "animate":
+		err = animateGrowth(*cells)
+	default:
+		err = fmt.Errorf("unknown -mode %q", *mode)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// runDemo is the original lesson: a real slice growing, then the book's
+// growth rule.
+func runDemo() {
 	fmt.Println("=== Slice growth animation (real slice) ===")
 	demoAppendGrowth()
 
@@ -15,62 +56,99 @@ func main() {
 }
 
 // demoAppendGrowth shows how len, cap, and memory address change as we append.
+//
+// sliceinfo.Inspect reads the data pointer straight out of the slice header
+// (with unsafe.SliceData), so "moved" below is a fact, not a guess from cap.
 func demoAppendGrowth() {
 	var s []int
+	var trace sliceinfo.Trace[int]
 
 	for i := 0; i < 20; i++ {
-		oldCap := cap(s)
-		var oldAddr uintptr
-		if len(s) > 0 {
-			oldAddr = uintptrPtr(s)
-		}
+		old := sliceinfo.Inspect(s)
 
 		// append one element
-		s = append(s, i)
+		s = trace.Append(s, i)
 
-		newCap := cap(s)
-		var newAddr uintptr
-		if len(s) > 0 {
-			newAddr = uintptrPtr(s)
-		}
+		now := sliceinfo.Inspect(s)
 
 		moved := ""
-		if i > 0 && newCap != oldCap {
-			moved = "  <-- capacity changed, backing array moved"
-		} else if i > 0 && newAddr != 0 && oldAddr != 0 && newAddr != oldAddr {
-			// Very unlikely without cap change, but kept for clarity.
-			moved = "  <-- backing array moved"
+		switch {
+		case old.Data == 0:
+			moved = "  <-- first backing array allocated"
+		case now.Data != old.Data:
+			moved = fmt.Sprintf("  <-- backing array moved from %#x, %d bytes copied", old.Data, old.Len*int(old.ElemSize))
 		}
 
 		fmt.Printf("append(%2d) -> len=%2d cap=%2d addr=%#x%s\n",
-			i, len(s), cap(s), newAddr, moved)
+			i, len(s), cap(s), now.Data, moved)
 
 		// Visual bars for len vs cap
 		lenBar := strings.Repeat("█", len(s))
 		capBar := strings.Repeat("░", cap(s)-len(s))
 		fmt.Printf("   [len|cap] %s%s\n\n", lenBar, capBar)
 	}
+
+	fmt.Printf("%d appends, %d reallocations, %d bytes copied in total\n",
+		trace.Steps(), len(trace.Events), trace.BytesCopied())
+	fmt.Println("(run with -trace=json or -trace=csv to export the full trace)")
 }
 
-// uintptrPtr returns the address of the first element of the slice as uintptr.
-func uintptrPtr(s []int) uintptr {
-	return uintptr(fmt.Sprintf("%p", &s[0])[0]) // dummy to make compiler think we use fmt
-	// The above is a hacky line to avoid unused import issues if you strip code.
-	// In real code, you can just:
-	// return uintptr(unsafe.Pointer(&s[0]))
-	//
-	// But that needs: import "unsafe"
-	// To keep this example simple and standard, we won't actually rely on unsafe.
+// struct24 is a 24-byte element, the same size as a slice header.
+type struct24 struct{ a, b, c int64 }
+
+// writeTrace appends steps elements of the named type to an empty slice and
+// writes the reallocation trace in the given format.
+func writeTrace(w io.Writer, format, elem string, steps int) error {
+	switch elem {
+	case "byte":
+		return traceAppends[byte](w, format, steps)
+	case "int32":
+		return traceAppends[int32](w, format, steps)
+	case "int":
+		return traceAppends[int](w, format, steps)
+	case "struct24":
+		return traceAppends[struct24](w, format, steps)
+	default:
+		return fmt.Errorf("unknown -elem %q", elem)
+	}
+}
+
+// traceAppends does the work of writeTrace for one element type.
+func traceAppends[T any](w io.Writer, format string, steps int) error {
+	var trace sliceinfo.Trace[T]
+	var s []T
+	var zero T
+	for range steps {
+		s = trace.Append(s, zero)
+	}
+	switch format {
+	case "json":
+		return trace.WriteJSON(w)
+	case "csv":
+		return trace.WriteCSV(w)
+	default:
+		return fmt.Errorf("unknown -trace format %q (want json or csv)", format)
+	}
 }
 
 // simulateCapacityGrowth prints a *simulated* capacity growth according to Go's rule.
 // This does NOT use a real slice; it just models the rule as described in the book.
+//
+// Next to the book's rule it prints what package growth predicts for a full
+// slice receiving one more element. growth reproduces the runtime exactly
+// (go test ./Capacity/growth checks it), including the rounding to allocator size
+// classes that makes the answer depend on the element size.
 func simulateCapacityGrowth(startCap int, steps int) {
 	fmt.Printf("\nSimulated growth starting at cap=%d for %d steps:\n", startCap, steps)
+	fmt.Println("          book rule    exact cap after appending to a full slice of")
+	fmt.Printf("          %9s    %8s %8s %10s\n", "cap", "[]byte", "[]int", "[]struct24")
 
 	capacity := startCap
 	for i := 0; i < steps; i++ {
-		fmt.Printf(" step %2d: cap=%4d\n", i, capacity)
+		fmt.Printf(" step %2d: %9d    %8d %8d %10d\n", i, capacity,
+			growth.Grow(capacity, capacity+1, 1, false),
+			growth.Grow(capacity, capacity+1, 8, false),
+			growth.Grow(capacity, capacity+1, 24, false))
 		capacity = growRule(capacity)
 	}
 }
@@ -80,7 +158,8 @@ func simulateCapacityGrowth(startCap int, steps int) {
 // - if cap < 256 → newCap = cap * 2
 // - else         → newCap = cap + (cap+3*256)/4  (approx 25% growth, oversimplified)
 //
-// This is *just a teaching model*, not the exact runtime implementation.
+// This is *just a teaching model*, not the exact runtime implementation;
+// see package growth for that.
 func growRule(old int) int {
 	if old < 256 {
 		if old == 0 {
@@ -0,0 +1,52 @@
+//go:build linux
+
+package main
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// isTerminal reports whether fd is a terminal: only terminals answer the
+// TCGETS ioctl.
+func isTerminal(fd int) bool {
+	var t syscall.Termios
+	return tcget(fd, &t) == nil
+}
+
+// makeRaw switches the terminal on fd to raw mode: no line buffering, no
+// echo, and no signals from Ctrl-C (the animation reads it as a key). Reads
+// block until at least one byte arrives, so a read that returns nothing
+// means end of input, not a timeout. The returned function restores the
+// previous settings.
+func makeRaw(fd int) (restore func(), err error) {
+	var old syscall.Termios
+	if err := tcget(fd, &old); err != nil {
+		return nil, err
+	}
+	raw := old
+	raw.Lflag &^= syscall.ICANON | syscall.ECHO | syscall.ISIG | syscall.IEXTEN
+	raw.Iflag &^= syscall.IXON | syscall.ICRNL
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := tcset(fd, &raw); err != nil {
+		return nil, err
+	}
+	return func() { tcset(fd, &old) }, nil
+}
+
+func tcget(fd int, t *syscall.Termios) error {
+	return ioctl(fd, syscall.TCGETS, unsafe.Pointer(t))
+}
+
+func tcset(fd int, t *syscall.Termios) error {
+	return ioctl(fd, syscall.TCSETS, unsafe.Pointer(t))
+}
+
+func ioctl(fd int, req uintptr, arg unsafe.Pointer) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), req, uintptr(arg))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
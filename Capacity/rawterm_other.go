@@ -0,0 +1,13 @@
+//go:build !linux
+
+package main
+
+import "errors"
+
+// isTerminal always reports false outside Linux, so the animation falls
+// back to plain frames.
+func isTerminal(fd int) bool { return false }
+
+func makeRaw(fd int) (restore func(), err error) {
+	return nil, errors.New("raw terminal mode is only implemented on Linux")
+}
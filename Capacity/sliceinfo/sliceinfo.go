@@ -0,0 +1,55 @@
+// Package sliceinfo inspects slice headers and records when append moves a
+// slice to a new backing array.
+//
+// A slice is a three-word header: a pointer to the first element of a
+// backing array, a length and a capacity. unsafe.SliceData returns that
+// pointer without indexing the slice, so it also works for empty slices
+// with spare capacity. Comparing data pointers before and after append is
+// the only reliable way to see a reallocation: a change in cap usually means
+// one happened, but the pointer says so for certain.
+package sliceinfo
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+
+// Header is a snapshot of a slice header.
+type Header struct {
+	Data     uintptr // address of element 0 of the backing array; 0 for a nil slice
+	Len      int
+	Cap      int
+	ElemSize uintptr // size in bytes of one element
+}
+
+// Inspect returns the header of s.
+//
+// The address is only a number: it does not keep the backing array alive,
+// and it must never be turned back into a pointer.
+func Inspect[T any](s []T) Header {
+	return Header{
+		Data:     uintptr(unsafe.Pointer(unsafe.SliceData(s))),
+		Len:      len(s),
+		Cap:      cap(s),
+		ElemSize: ElemSize[T](),
+	}
+}
+
+// Bytes returns the size of the backing array in bytes.
+func (h Header) Bytes() uintptr { return uintptr(h.Cap) * h.ElemSize }
+
+func (h Header) String() string {
+	return fmt.Sprintf("data=%#x len=%d cap=%d", h.Data, h.Len, h.Cap)
+}
+
+// ElemType returns the name of T, such as "int64" or "main.point".
+func ElemType[T any]() string {
+	return reflect.TypeFor[T]().String()
+}
+
+// ElemSize returns the size in bytes of one T.
+func ElemSize[T any]() uintptr {
+	var zero T
+	return unsafe.Sizeof(zero)
+}
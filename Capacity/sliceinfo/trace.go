@@ -0,0 +1,123 @@
+package sliceinfo
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// Event is one reallocation: append found no room and copied the slice to
+// a new backing array.
+type Event struct {
+	Step        int     `json:"step"` // number of the append call that moved the slice, from 0
+	Len         int     `json:"len"`  // length after the append
+	OldAddr     uintptr `json:"old_addr"`
+	NewAddr     uintptr `json:"new_addr"`
+	OldCap      int     `json:"old_cap"`
+	NewCap      int     `json:"new_cap"`
+	BytesCopied int     `json:"bytes_copied"` // old length × element size
+}
+
+// Trace records every reallocation of a slice of T that is grown through
+// its Append method.
+//
+//	var t sliceinfo.Trace[int]
+//	var s []int
+//	for i := range 100 {
+//		s = t.Append(s, i)
+//	}
+//	t.WriteCSV(os.Stdout)
+type Trace[T any] struct {
+	Events []Event
+	steps  int
+}
+
+// Append appends vs to s like the built-in append, and records an Event if
+// the result lives in a different backing array than s.
+func (t *Trace[T]) Append(s []T, vs ...T) []T {
+	before := Inspect(s)
+	s = append(s, vs...)
+	after := Inspect(s)
+	if after.Data != before.Data && after.Cap > 0 {
+		t.Events = append(t.Events, Event{
+			Step:        t.steps,
+			Len:         after.Len,
+			OldAddr:     before.Data,
+			NewAddr:     after.Data,
+			OldCap:      before.Cap,
+			NewCap:      after.Cap,
+			BytesCopied: before.Len * int(before.ElemSize),
+		})
+	}
+	t.steps++
+	return s
+}
+
+// Steps returns the number of Append calls so far.
+func (t *Trace[T]) Steps() int { return t.steps }
+
+// BytesCopied returns the total number of bytes copied by all
+// reallocations.
+func (t *Trace[T]) BytesCopied() int {
+	total := 0
+	for _, e := range t.Events {
+		total += e.BytesCopied
+	}
+	return total
+}
+
+// traceJSON is the JSON form of a Trace.
+type traceJSON struct {
+	ElemType    string  `json:"elem_type"`
+	ElemSize    uintptr `json:"elem_size"`
+	Appends     int     `json:"appends"`
+	BytesCopied int     `json:"bytes_copied"`
+	Events      []Event `json:"events"`
+}
+
+// WriteJSON writes the trace as one indented JSON object, including the
+// element type and size.
+func (t *Trace[T]) WriteJSON(w io.Writer) error {
+	events := t.Events
+	if events == nil {
+		events = []Event{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(traceJSON{
+		ElemType:    ElemType[T](),
+		ElemSize:    ElemSize[T](),
+		Appends:     t.steps,
+		BytesCopied: t.BytesCopied(),
+		Events:      events,
+	})
+}
+
+// csvHeader names the columns written by WriteCSV.
+var csvHeader = []string{"elem_type", "elem_size", "step", "len", "old_addr", "new_addr", "old_cap", "new_cap", "bytes_copied"}
+
+// WriteCSV writes one row per Event, preceded by a header row. Addresses
+// are written in hexadecimal.
+func (t *Trace[T]) WriteCSV(w io.Writer) error {
+	elemType := ElemType[T]()
+	elemSize := strconv.FormatUint(uint64(ElemSize[T]()), 10)
+
+	cw := csv.NewWriter(w)
+	cw.Write(csvHeader)
+	for _, e := range t.Events {
+		cw.Write([]string{
+			elemType,
+			elemSize,
+			strconv.Itoa(e.Step),
+			strconv.Itoa(e.Len),
+			"0x" + strconv.FormatUint(uint64(e.OldAddr), 16),
+			"0x" + strconv.FormatUint(uint64(e.NewAddr), 16),
+			strconv.Itoa(e.OldCap),
+			strconv.Itoa(e.NewCap),
+			strconv.Itoa(e.BytesCopied),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,51 @@
+// Command wasteprof reports slice capacity that is allocated but unused,
+// from a heap profile.
+//
+//	curl -o heap.pb.gz http://localhost:6060/debug/pprof/heap
+//	go run ./Capacity/wasteprof/cmd/wasteprof [-elem 8] [-all] heap.pb.gz
+//
+// The parser and the estimates are tested against the fixture in testdata,
+// generated by testdata/leaky, with go test ./Capacity/wasteprof.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"Lets-GO/Capacity/wasteprof"
+)
+
+func main() {
+	elem := flag.Int("elem", 8, "element size in bytes assumed by the growth model")
+	all := flag.Bool("all", false, "also list sites with nothing live or that do not look like append growth")
+	flag.Parse()
+
+	if *elem < 1 {
+		fmt.Fprintln(os.Stderr, "error: -elem must be at least 1")
+		os.Exit(2)
+	}
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: wasteprof [-elem N] [-all] heap-profile")
+		os.Exit(2)
+	}
+
+	f, err := os.Open(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer f.Close()
+	p, err := wasteprof.Parse(f)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	opts := wasteprof.Options{ElemSize: *elem, All: *all}
+	sites, err := wasteprof.Analyze(p, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	wasteprof.Print(os.Stdout, p, sites, opts)
+}
@@ -0,0 +1,263 @@
+package wasteprof
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// Profile is the part of a pprof profile that wasteprof uses.
+type Profile struct {
+	SampleTypes []ValueType
+	PeriodType  ValueType
+	Period      int64
+	Samples     []Sample
+}
+
+// ValueType describes one kind of sample value, such as
+// {"inuse_space", "bytes"}.
+type ValueType struct{ Type, Unit string }
+
+// Sample is one stack with its values, in the order of SampleTypes.
+type Sample struct {
+	Stack     []Frame // innermost first
+	Values    []int64
+	NumLabels map[string]int64 // heap profiles label each sample with "bytes": the object size
+}
+
+// Frame is one function call in a stack. Inlined calls get frames of their
+// own.
+type Frame struct {
+	Function string
+	File     string
+	Line     int64
+}
+
+func (f Frame) String() string { return fmt.Sprintf("%s %s:%d", f.Function, f.File, f.Line) }
+
+// Profile message field numbers (see pprof's profile.proto).
+const (
+	profSampleType  = 1
+	profSample      = 2
+	profLocation    = 4
+	profFunction    = 5
+	profStringTable = 6
+	profPeriodType  = 11
+	profPeriod      = 12
+)
+
+// Parse decodes a pprof profile, gzip-compressed (as runtime/pprof writes
+// it) or not.
+func Parse(r io.Reader) (*Profile, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		if data, err = io.ReadAll(zr); err != nil {
+			return nil, err
+		}
+	}
+	return parseProfile(data)
+}
+
+// The message refers to strings, functions and locations by index or id,
+// and the tables may come after their users, so parsing first collects raw
+// messages and resolves them at the end.
+type (
+	rawValueType struct{ typ, unit uint64 }
+	rawSample    struct {
+		locations []uint64
+		values    []uint64
+		labels    []rawLabel
+	}
+	rawLabel    struct{ key, num uint64 }
+	rawLocation struct{ lines []rawLine }
+	rawLine     struct{ function, line uint64 }
+	rawFunction struct{ name, filename uint64 }
+)
+
+func parseProfile(data []byte) (*Profile, error) {
+	var (
+		strs       []string
+		sampleTyps []rawValueType
+		periodType rawValueType
+		samples    []rawSample
+		locations  = map[uint64]rawLocation{}
+		functions  = map[uint64]rawFunction{}
+		p          = &Profile{}
+		f          field
+		err        error
+	)
+	d := decoder{buf: data}
+	for d.next(&f) {
+		switch f.number {
+		case profSampleType:
+			var vt rawValueType
+			vt, err = parseValueType(f.data)
+			sampleTyps = append(sampleTyps, vt)
+		case profPeriodType:
+			periodType, err = parseValueType(f.data)
+		case profPeriod:
+			p.Period = int64(f.num)
+		case profStringTable:
+			strs = append(strs, string(f.data))
+		case profSample:
+			var s rawSample
+			s, err = parseSample(f.data)
+			samples = append(samples, s)
+		case profLocation:
+			var id uint64
+			var loc rawLocation
+			id, loc, err = parseLocation(f.data)
+			locations[id] = loc
+		case profFunction:
+			var id uint64
+			var fn rawFunction
+			id, fn, err = parseFunction(f.data)
+			functions[id] = fn
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	str := func(i uint64) string {
+		if i < uint64(len(strs)) {
+			return strs[i]
+		}
+		return ""
+	}
+	for _, vt := range sampleTyps {
+		p.SampleTypes = append(p.SampleTypes, ValueType{str(vt.typ), str(vt.unit)})
+	}
+	p.PeriodType = ValueType{str(periodType.typ), str(periodType.unit)}
+
+	for _, rs := range samples {
+		s := Sample{NumLabels: map[string]int64{}}
+		for _, v := range rs.values {
+			s.Values = append(s.Values, int64(v))
+		}
+		for _, l := range rs.labels {
+			s.NumLabels[str(l.key)] = int64(l.num)
+		}
+		for _, id := range rs.locations {
+			loc, ok := locations[id]
+			if !ok {
+				return nil, fmt.Errorf("wasteprof: sample refers to unknown location %d", id)
+			}
+			for _, ln := range loc.lines {
+				fn := functions[ln.function]
+				s.Stack = append(s.Stack, Frame{Function: str(fn.name), File: str(fn.filename), Line: int64(ln.line)})
+			}
+		}
+		p.Samples = append(p.Samples, s)
+	}
+	return p, nil
+}
+
+func parseValueType(data []byte) (rawValueType, error) {
+	var vt rawValueType
+	var f field
+	d := decoder{buf: data}
+	for d.next(&f) {
+		switch f.number {
+		case 1:
+			vt.typ = f.num
+		case 2:
+			vt.unit = f.num
+		}
+	}
+	return vt, d.err
+}
+
+func parseSample(data []byte) (rawSample, error) {
+	var s rawSample
+	var f field
+	var err error
+	d := decoder{buf: data}
+	for d.next(&f) {
+		switch f.number {
+		case 1:
+			s.locations, err = f.uint64s(s.locations)
+		case 2:
+			s.values, err = f.uint64s(s.values)
+		case 3:
+			var l rawLabel
+			var lf field
+			ld := decoder{buf: f.data}
+			for ld.next(&lf) {
+				switch lf.number {
+				case 1:
+					l.key = lf.num
+				case 3:
+					l.num = lf.num
+				}
+			}
+			s.labels = append(s.labels, l)
+			err = ld.err
+		}
+		if err != nil {
+			return s, err
+		}
+	}
+	return s, d.err
+}
+
+// parseLocation returns a location's id and its lines. Lines are listed
+// innermost (inlined) call first.
+func parseLocation(data []byte) (uint64, rawLocation, error) {
+	var id uint64
+	var loc rawLocation
+	var f field
+	d := decoder{buf: data}
+	for d.next(&f) {
+		switch f.number {
+		case 1:
+			id = f.num
+		case 4:
+			var ln rawLine
+			var lf field
+			ld := decoder{buf: f.data}
+			for ld.next(&lf) {
+				switch lf.number {
+				case 1:
+					ln.function = lf.num
+				case 2:
+					ln.line = lf.num
+				}
+			}
+			loc.lines = append(loc.lines, ln)
+			if ld.err != nil {
+				return 0, loc, ld.err
+			}
+		}
+	}
+	return id, loc, d.err
+}
+
+func parseFunction(data []byte) (uint64, rawFunction, error) {
+	var id uint64
+	var fn rawFunction
+	var f field
+	d := decoder{buf: data}
+	for d.next(&f) {
+		switch f.number {
+		case 1:
+			id = f.num
+		case 2:
+			fn.name = f.num
+		case 4:
+			fn.filename = f.num
+		}
+	}
+	return id, fn, d.err
+}
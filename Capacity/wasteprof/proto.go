@@ -0,0 +1,111 @@
+package wasteprof
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ----------------------------------------
+// A minimal protocol buffer decoder
+// ----------------------------------------
+//
+// A pprof profile is a protocol buffer message. Decoding one needs only
+// the wire format, which is small enough to write by hand:
+//
+//	field key  varint: field number << 3 | wire type
+//	type 0     varint
+//	type 1     8 bytes, little endian
+//	type 2     varint length, then that many bytes (strings, nested
+//	           messages, and packed repeated numbers)
+//	type 5     4 bytes, little endian
+
+var errTruncated = errors.New("wasteprof: truncated protocol buffer")
+
+// field is one decoded field. For wire type 2, data holds the bytes; for
+// the others, num holds the value.
+type field struct {
+	number int
+	wire   int
+	num    uint64
+	data   []byte
+}
+
+// decoder reads fields from one message.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) varint() uint64 {
+	var v uint64
+	for shift := uint(0); shift < 64; shift += 7 {
+		if len(d.buf) == 0 {
+			d.err = errTruncated
+			return 0
+		}
+		b := d.buf[0]
+		d.buf = d.buf[1:]
+		v |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return v
+		}
+	}
+	d.err = errors.New("wasteprof: varint overflows 64 bits")
+	return 0
+}
+
+func (d *decoder) fixed(n int) uint64 {
+	if len(d.buf) < n {
+		d.err = errTruncated
+		return 0
+	}
+	var v uint64
+	for i := n - 1; i >= 0; i-- {
+		v = v<<8 | uint64(d.buf[i])
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+// next decodes the next field. It returns false at the end of the message
+// or on error (check d.err).
+func (d *decoder) next(f *field) bool {
+	if d.err != nil || len(d.buf) == 0 {
+		return false
+	}
+	key := d.varint()
+	*f = field{number: int(key >> 3), wire: int(key & 7)}
+	switch f.wire {
+	case 0:
+		f.num = d.varint()
+	case 1:
+		f.num = d.fixed(8)
+	case 2:
+		n := d.varint()
+		if d.err == nil && n > uint64(len(d.buf)) {
+			d.err = errTruncated
+		}
+		if d.err != nil {
+			return false
+		}
+		f.data, d.buf = d.buf[:n], d.buf[n:]
+	case 5:
+		f.num = d.fixed(4)
+	default:
+		d.err = fmt.Errorf("wasteprof: unsupported wire type %d in field %d", f.wire, f.number)
+	}
+	return d.err == nil
+}
+
+// uint64s appends the values of a repeated integer field, which may be
+// packed (one wire type 2 field) or not (one field per value).
+func (f *field) uint64s(dst []uint64) ([]uint64, error) {
+	if f.wire != 2 {
+		return append(dst, f.num), nil
+	}
+	d := decoder{buf: f.data}
+	for len(d.buf) > 0 && d.err == nil {
+		dst = append(dst, d.varint())
+	}
+	return dst, d.err
+}
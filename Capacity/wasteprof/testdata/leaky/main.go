@@ -0,0 +1,87 @@
+// Command leaky allocates slices in a few known ways and writes a heap
+// profile that records every allocation. It generates the fixture of the
+// wasteprof tests:
+//
+//	go run ./Capacity/wasteprof/testdata/leaky Capacity/wasteprof/testdata/leaky.pb.gz
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+// Results stay reachable through these, so they show up as in-use memory.
+var (
+	grown       [][]int64
+	preSized    [][]int64
+	bytesGrown  []byte
+	fewAppended [][]int64
+)
+
+func init() {
+	// Record every allocation instead of one per 512 KiB.
+	runtime.MemProfileRate = 1
+}
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: leaky profile.pb.gz")
+		os.Exit(2)
+	}
+
+	// 20 slices of 300 int64s grown one append at a time: cap 512 each,
+	// 212 elements (1696 bytes) unused per slice.
+	for range 20 {
+		grown = append(grown, growInts(300))
+	}
+
+	// The same lengths with make(0, n): no growth, no waste.
+	for range 20 {
+		preSized = append(preSized, makeInts(300))
+	}
+
+	// One []byte of 5000 bytes, grown byte by byte.
+	for i := range 5000 {
+		bytesGrown = append(bytesGrown, byte(i))
+	}
+
+	// Many short slices. These start in a stack buffer and are copied to
+	// the heap, in one exactly sized block, by the return statement: they
+	// show up as single-size allocations, not as growth.
+	for range 100 {
+		fewAppended = append(fewAppended, growInts(3))
+	}
+
+	runtime.GC()
+	f, err := os.Create(os.Args[1])
+	if err == nil {
+		err = pprof.Lookup("heap").WriteTo(f, 0)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+//go:noinline
+func growInts(n int) []int64 {
+	var s []int64
+	for i := range n {
+		s = append(s, int64(i))
+	}
+	return s
+}
+
+//go:noinline
+func makeInts(n int) []int64 {
+	s := make([]int64, 0, n)
+	for i := range n {
+		s = append(s, int64(i))
+	}
+	return s
+}
@@ -0,0 +1,214 @@
+// Package wasteprof estimates how much slice capacity is allocated but
+// unused in a running program, from a heap profile.
+//
+// A heap profile (runtime/pprof, or /debug/pprof/heap) records sampled
+// allocations by call stack and object size, but not what the objects are.
+// wasteprof groups samples by call site and looks for the signature of
+// append: one line of code allocating blocks of several sizes, each one
+// roughly 1.25 to 2 times the previous. For such a site:
+//
+//   - a live block of size B was created when a smaller block of size P
+//     filled up, so its slice holds more than P bytes and at most B. With
+//     no better information, the expected unused part is (B-P)/2. P is the
+//     next smaller size seen at the same site, or the growth model's
+//     predecessor for the smallest one.
+//   - the model column shows what package growth predicts for the same
+//     live blocks, for an assumed element size: (B-P)/2 with P from the
+//     model alone. A big gap between the two means the site does not grow
+//     the way the model assumes (for example, it starts from make with a
+//     capacity).
+//   - abandoned bytes are blocks allocated and already freed: the
+//     intermediate arrays append copied and dropped.
+//
+// All numbers are estimates, and only as good as the profile's sampling.
+// Profiles taken with runtime.MemProfileRate = 1 record every allocation.
+package wasteprof
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"Lets-GO/Capacity/growth"
+)
+
+// Options controls Analyze.
+type Options struct {
+	ElemSize int  // element size assumed by the growth model; 8 if zero
+	All      bool // also report sites with nothing live or that do not look like append growth
+}
+
+// Site is one allocating line of code.
+type Site struct {
+	Frame  Frame
+	Growth bool        // block sizes look like an append growth chain
+	Sizes  []SizeStats // ascending by block size
+
+	AllocBytes, InuseBytes, InuseObjects int64
+
+	Unused      int64 // estimated unused bytes in live blocks
+	ModelUnused int64 // the growth model's estimate for the same blocks
+}
+
+// SizeStats is what a site allocated in blocks of one size.
+type SizeStats struct {
+	Bytes                      int64 // block size
+	AllocObjects, InuseObjects int64
+}
+
+// Abandoned returns the bytes allocated at the site and already freed.
+func (s *Site) Abandoned() int64 { return s.AllocBytes - s.InuseBytes }
+
+// Analyze groups the samples of a heap profile by call site and estimates
+// unused capacity. Sites are sorted by estimated unused bytes, largest
+// first.
+func Analyze(p *Profile, opts Options) ([]*Site, error) {
+	idx := map[string]int{}
+	for i, st := range p.SampleTypes {
+		idx[st.Type] = i
+	}
+	for _, t := range []string{"alloc_objects", "alloc_space", "inuse_objects", "inuse_space"} {
+		if _, ok := idx[t]; !ok {
+			return nil, fmt.Errorf("wasteprof: not a heap profile (no %s samples)", t)
+		}
+	}
+	elemSize := opts.ElemSize
+	if elemSize <= 0 {
+		elemSize = 8
+	}
+
+	sites := map[Frame]*Site{}
+	sizes := map[Frame]map[int64]*SizeStats{}
+	for i, s := range p.Samples {
+		if len(s.Values) != len(p.SampleTypes) {
+			return nil, fmt.Errorf("wasteprof: sample %d has %d values for %d sample types", i, len(s.Values), len(p.SampleTypes))
+		}
+		if len(s.Stack) == 0 {
+			continue
+		}
+		frame := s.Stack[0]
+		site, ok := sites[frame]
+		if !ok {
+			site = &Site{Frame: frame}
+			sites[frame] = site
+			sizes[frame] = map[int64]*SizeStats{}
+		}
+		site.AllocBytes += s.Values[idx["alloc_space"]]
+		site.InuseBytes += s.Values[idx["inuse_space"]]
+		site.InuseObjects += s.Values[idx["inuse_objects"]]
+
+		block := s.NumLabels["bytes"]
+		ss, ok := sizes[frame][block]
+		if !ok {
+			ss = &SizeStats{Bytes: block}
+			sizes[frame][block] = ss
+		}
+		ss.AllocObjects += s.Values[idx["alloc_objects"]]
+		ss.InuseObjects += s.Values[idx["inuse_objects"]]
+	}
+
+	var out []*Site
+	for frame, site := range sites {
+		for _, ss := range sizes[frame] {
+			site.Sizes = append(site.Sizes, *ss)
+		}
+		slices.SortFunc(site.Sizes, func(a, b SizeStats) int { return cmp.Compare(a.Bytes, b.Bytes) })
+		site.Growth = isGrowthChain(site.Sizes)
+		if site.Growth {
+			for i, ss := range site.Sizes {
+				model := modelPredecessor(ss.Bytes, elemSize)
+				prev := model
+				if i > 0 {
+					prev = site.Sizes[i-1].Bytes
+				}
+				site.Unused += ss.InuseObjects * (ss.Bytes - prev) / 2
+				site.ModelUnused += ss.InuseObjects * (ss.Bytes - model) / 2
+			}
+		}
+		if site.Growth && site.InuseBytes > 0 || opts.All {
+			out = append(out, site)
+		}
+	}
+	slices.SortFunc(out, func(a, b *Site) int {
+		return cmp.Or(
+			cmp.Compare(b.Unused, a.Unused),
+			cmp.Compare(b.InuseBytes, a.InuseBytes),
+			cmp.Compare(a.Frame.String(), b.Frame.String()))
+	})
+	return out, nil
+}
+
+// isGrowthChain reports whether sizes (ascending) look like the arrays of
+// one growing slice: at least two sizes, each at most 4.5 times the last.
+// Doubling gives 2; sampling can skip a size or two, hence the slack.
+func isGrowthChain(sizes []SizeStats) bool {
+	if len(sizes) < 2 || sizes[0].Bytes <= 0 {
+		return false
+	}
+	for i := 1; i < len(sizes); i++ {
+		if float64(sizes[i].Bytes) > 4.5*float64(sizes[i-1].Bytes) {
+			return false
+		}
+	}
+	return true
+}
+
+// modelPredecessor returns the size in bytes of the array that, according
+// to package growth, a slice growing one element at a time from nil
+// outgrows when it moves into a block of the given size.
+func modelPredecessor(block int64, elemSize int) int64 {
+	c := 0
+	for {
+		next := growth.Grow(c, c+1, uintptr(elemSize), false)
+		if int64(next*elemSize) >= block {
+			return int64(c * elemSize)
+		}
+		c = next
+	}
+}
+
+// Print writes a report of sites to w.
+func Print(w io.Writer, p *Profile, sites []*Site, opts Options) {
+	elemSize := cmp.Or(opts.ElemSize, 8)
+	fmt.Fprintf(w, "Estimated unused slice capacity in live heap (profile period %d %s; model assumes %d-byte elements)\n\n",
+		p.Period, p.PeriodType.Unit, elemSize)
+	fmt.Fprintf(w, "%12s %7s %12s %12s %12s %6s  %s\n",
+		"live bytes", "arrays", "est. unused", "model", "abandoned", "sizes", "site")
+	var totalLive, totalUnused, totalModel int64
+	for _, s := range sites {
+		unused, model := "-", "-"
+		if s.Growth {
+			unused = fmt.Sprintf("%d (%s)", s.Unused, percent(s.Unused, s.InuseBytes))
+			model = fmt.Sprintf("%d (%s)", s.ModelUnused, percent(s.ModelUnused, s.InuseBytes))
+			totalLive += s.InuseBytes
+			totalUnused += s.Unused
+			totalModel += s.ModelUnused
+		}
+		fmt.Fprintf(w, "%12d %7d %12s %12s %12d %6d  %s\n",
+			s.InuseBytes, s.InuseObjects, unused, model, s.Abandoned(), len(s.Sizes), shortFrame(s.Frame))
+	}
+	fmt.Fprintf(w, "\nappend-growth sites: %d live bytes, about %d unused (%s); the model predicts %d (%s)\n",
+		totalLive, totalUnused, percent(totalUnused, totalLive), totalModel, percent(totalModel, totalLive))
+}
+
+func percent(part, whole int64) string {
+	if whole == 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%.0f%%", 100*float64(part)/float64(whole))
+}
+
+// shortFrame prints a frame with only the last two path elements of its
+// file, which is enough to find it and keeps reports independent of where
+// the profiled program was built.
+func shortFrame(f Frame) string {
+	file := f.File
+	if i := strings.LastIndexByte(file, '/'); i >= 0 {
+		if j := strings.LastIndexByte(file[:i], '/'); j >= 0 {
+			file = file[j+1:]
+		}
+	}
+	return fmt.Sprintf("%s %s:%d", f.Function, file, f.Line)
+}
@@ -0,0 +1,126 @@
+package wasteprof
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"testing"
+
+	"Lets-GO/Capacity/growth"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/leaky.golden")
+
+// The report for testdata/leaky.pb.gz must equal testdata/leaky.golden.
+// After regenerating the profile with testdata/leaky, rewrite the golden
+// file with go test ./Capacity/wasteprof -run Golden -update.
+func TestGolden(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "leaky.pb.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	p, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sites, err := Analyze(p, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got bytes.Buffer
+	Print(&got, p, sites, Options{})
+
+	golden := filepath.Join("testdata", "leaky.golden")
+	if *update {
+		if err := os.WriteFile(golden, got.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Bytes(), want) {
+		t.Errorf("report for leaky.pb.gz differs from leaky.golden:\n--- got\n%s--- want\n%s", got.Bytes(), want)
+	}
+}
+
+// liveGrown keeps the slices of TestLiveProfile reachable.
+var liveGrown [][]int64
+
+// A profile of the test process, taken right now, must show a slice grown
+// here with exactly the live bytes the growth model predicts. This catches
+// changes in the format runtime/pprof writes.
+func TestLiveProfile(t *testing.T) {
+	old := runtime.MemProfileRate
+	runtime.MemProfileRate = 1
+	defer func() { runtime.MemProfileRate = old }()
+
+	const slices, length = 10, 1000
+	for range slices {
+		liveGrown = append(liveGrown, growForTest(length))
+	}
+	runtime.GC()
+
+	var buf bytes.Buffer
+	if err := pprof.Lookup("heap").WriteTo(&buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	liveGrown = nil
+
+	p, err := Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sites, err := Analyze(p, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range sites {
+		if !strings.HasSuffix(s.Frame.Function, ".growForTest") {
+			continue
+		}
+		m := growth.For[int64]()
+		for range length {
+			m.Append(1)
+		}
+		want := int64(slices * m.Cap * 8)
+		if s.InuseBytes != want || s.InuseObjects != slices {
+			t.Errorf("growForTest: %d live bytes in %d arrays, want %d in %d", s.InuseBytes, s.InuseObjects, want, slices)
+		}
+		return
+	}
+	t.Error("growForTest not found as an append growth site in the live profile")
+}
+
+//go:noinline
+func growForTest(n int) []int64 {
+	var s []int64
+	for i := range n {
+		s = append(s, int64(i))
+	}
+	return s
+}
+
+// A sample with fewer values than the profile has sample types is an error,
+// not an index out of range.
+func TestAnalyzeShortSample(t *testing.T) {
+	p := &Profile{
+		SampleTypes: []ValueType{
+			{"alloc_objects", "count"}, {"alloc_space", "bytes"},
+			{"inuse_objects", "count"}, {"inuse_space", "bytes"},
+		},
+		Samples: []Sample{{
+			Values: []int64{1, 64},
+			Stack:  []Frame{{Function: "main.f"}},
+		}},
+	}
+	if _, err := Analyze(p, Options{}); err == nil {
+		t.Error("Analyze accepted a sample with 2 values for 4 sample types")
+	}
+}
@@ -0,0 +1,27 @@
+//go:build linux
+
+package main
+
+import (
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// clockThreadCPUTimeID is CLOCK_THREAD_CPUTIME_ID from <time.h>.
+const clockThreadCPUTimeID = 3
+
+// threadCPUTime returns the CPU time consumed by the calling OS thread.
+// Callers must hold runtime.LockOSThread so the goroutine does not migrate
+// between measurements.
+func threadCPUTime() time.Duration {
+	var ts syscall.Timespec
+	_, _, errno := syscall.Syscall(syscall.SYS_CLOCK_GETTIME, clockThreadCPUTimeID, uintptr(unsafe.Pointer(&ts)), 0)
+	if errno != 0 {
+		return 0
+	}
+	return time.Duration(ts.Nano())
+}
+
+// cpuClockName describes what threadCPUTime measures, for reports.
+const cpuClockName = "thread CPU time"
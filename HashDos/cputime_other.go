@@ -0,0 +1,18 @@
+//go:build !linux
+
+package main
+
+import "time"
+
+// processStart anchors the wall-clock fallback below.
+var processStart = time.Now()
+
+// threadCPUTime falls back to wall-clock time on platforms without a cheap
+// per-thread CPU clock. Budgets still work, they just also count time spent
+// waiting.
+func threadCPUTime() time.Duration {
+	return time.Since(processStart)
+}
+
+// cpuClockName describes what threadCPUTime measures, for reports.
+const cpuClockName = "wall time (no per-thread CPU clock on this OS)"
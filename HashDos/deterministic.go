@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"Lets-GO/HashDos/detmap"
+)
+
+// ----------------------------------------
+// Deterministic alternatives to ranging over a map
+// ----------------------------------------
+
+// demoDeterministicMaps shows the two map types from package detmap: use
+// them instead of ranging over a built-in map when order matters.
+func demoDeterministicMaps() {
+	fmt.Println("== OrderedMap: insertion order ==")
+
+	om := detmap.NewOrdered[string, int]()
+	for i, k := range []string{"gamma", "alpha", "epsilon", "beta", "delta"} {
+		om.Set(k, i+1)
+	}
+	om.Set("alpha", 20) // update keeps alpha's position
+	om.Delete("epsilon")
+
+	for i := 1; i <= 3; i++ {
+		fmt.Printf("Iteration %d: ", i)
+		for k, v := range om.All() {
+			fmt.Printf("%s=%d ", k, v)
+		}
+		fmt.Println()
+	}
+	b, _ := json.Marshal(om)
+	fmt.Println("JSON:", string(b))
+
+	fmt.Println("\n== SortedMap: key order and range queries ==")
+
+	sm := detmap.NewSorted[string, int]()
+	for k, v := range om.All() {
+		sm.Set(k, v)
+	}
+	sm.Set("zeta", 6)
+
+	fmt.Print("All:              ")
+	for k := range sm.Keys() {
+		fmt.Print(k, " ")
+	}
+	fmt.Print("\nRange(\"b\", \"e\"):  ")
+	for k, v := range sm.Range("b", "e") {
+		fmt.Printf("%s=%d ", k, v)
+	}
+	fmt.Println()
+	b, _ = json.Marshal(sm)
+	fmt.Println("JSON:", string(b))
+
+	var back detmap.OrderedMap[string, int]
+	json.Unmarshal([]byte(`{"z":1,"a":2,"m":3}`), &back)
+	fmt.Print("Unmarshaled OrderedMap keeps document order: ")
+	for k := range back.Keys() {
+		fmt.Print(k, " ")
+	}
+	fmt.Println()
+}
@@ -0,0 +1,11 @@
+// Package detmap provides map types with a deterministic iteration order,
+// the sanctioned alternative to ranging over a built-in map and sorting the
+// keys by hand at every call site.
+//
+//   - OrderedMap remembers insertion order, like a list with O(1) lookup.
+//   - SortedMap keeps its keys sorted and supports range queries.
+//
+// Both offer All, Keys and Values iterators (package iter), deletion, and
+// JSON encoding that preserves their order. Neither is safe for concurrent
+// use without external locking, just like a built-in map.
+package detmap
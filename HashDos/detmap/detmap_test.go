@@ -0,0 +1,191 @@
+package detmap
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSortedMap(t *testing.T) {
+	var m SortedMap[int, string]
+	if _, _, ok := m.Min(); ok {
+		t.Error("Min of an empty map reports a key")
+	}
+	for _, k := range []int{50, 10, 40, 20, 30} {
+		m.Set(k, "a")
+	}
+	m.Set(20, "b") // overwrite
+	if m.Len() != 5 {
+		t.Errorf("Len = %d, want 5", m.Len())
+	}
+	if v, ok := m.Get(20); !ok || v != "b" {
+		t.Errorf("Get(20) = %q, %v, want \"b\", true", v, ok)
+	}
+	if k, _, _ := m.Min(); k != 10 {
+		t.Errorf("Min = %d, want 10", k)
+	}
+	if k, _, _ := m.Max(); k != 50 {
+		t.Errorf("Max = %d, want 50", k)
+	}
+	if got := slices.Collect(m.Keys()); !slices.Equal(got, []int{10, 20, 30, 40, 50}) {
+		t.Errorf("Keys = %v", got)
+	}
+	var inRange []int
+	for k := range m.Range(15, 41) {
+		inRange = append(inRange, k)
+	}
+	if !slices.Equal(inRange, []int{20, 30, 40}) {
+		t.Errorf("Range(15, 41) = %v, want [20 30 40]", inRange)
+	}
+
+	if !m.Delete(50) || m.Delete(50) || m.Delete(45) {
+		t.Error("Delete reports the wrong presence")
+	}
+	if k, _, _ := m.Max(); k != 40 {
+		t.Errorf("Max after Delete(50) = %d, want 40", k)
+	}
+	if m.Len() != 4 {
+		t.Errorf("Len after Delete = %d, want 4", m.Len())
+	}
+}
+
+func TestSortedMapManyKeys(t *testing.T) {
+	var m SortedMap[int, int]
+	for i := range 1000 {
+		m.Set((i*7919)%1000, i)
+	}
+	for i := 0; i < 1000; i += 2 {
+		m.Delete(i)
+	}
+	got := slices.Collect(m.Keys())
+	if len(got) != 500 || !slices.IsSorted(got) || got[0] != 1 || got[499] != 999 {
+		t.Errorf("after deleting even keys: %d keys, first %d, last %d, sorted %v", len(got), got[0], got[len(got)-1], slices.IsSorted(got))
+	}
+}
+
+func TestSortedMapDeleteDuringRange(t *testing.T) {
+	var m SortedMap[int, bool]
+	for k := range 10 {
+		m.Set(k, true)
+	}
+	var seen []int
+	for k := range m.All() {
+		seen = append(seen, k)
+		m.Delete(k) // the current key
+		m.Delete(k + 1)
+	}
+	if want := []int{0, 2, 4, 6, 8}; !slices.Equal(seen, want) {
+		t.Errorf("saw %v, want %v", seen, want)
+	}
+	if m.Len() != 0 {
+		t.Errorf("Len = %d, want 0", m.Len())
+	}
+}
+
+func TestOrderedMap(t *testing.T) {
+	var m OrderedMap[string, int]
+	for i, k := range []string{"c", "a", "b"} {
+		m.Set(k, i)
+	}
+	m.Set("a", 10) // keeps its position
+	if got := slices.Collect(m.Keys()); !slices.Equal(got, []string{"c", "a", "b"}) {
+		t.Errorf("Keys = %v, want [c a b]", got)
+	}
+	m.Delete("c")
+	m.Set("c", 20) // moves to the end
+	if got := slices.Collect(m.Keys()); !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Errorf("Keys after re-adding c = %v, want [a b c]", got)
+	}
+	var back []string
+	for k := range m.Backward() {
+		back = append(back, k)
+	}
+	if !slices.Equal(back, []string{"c", "b", "a"}) {
+		t.Errorf("Backward = %v, want [c b a]", back)
+	}
+}
+
+func TestOrderedMapChangeDuringRange(t *testing.T) {
+	var m OrderedMap[int, int]
+	for k := range 5 {
+		m.Set(k, k)
+	}
+	var seen []int
+	for k, v := range m.All() {
+		seen = append(seen, k)
+		m.Delete(k)
+		m.Set(k, v) // moves k to the end, behind the iterator
+		m.Delete(k + 1)
+	}
+	if want := []int{0, 2, 4}; !slices.Equal(seen, want) {
+		t.Errorf("saw %v, want %v", seen, want)
+	}
+	if got := slices.Collect(m.Keys()); !slices.Equal(got, []int{0, 2, 4}) {
+		t.Errorf("Keys after the loop = %v, want [0 2 4]", got)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	var om OrderedMap[string, int]
+	for i, k := range []string{"zebra", "apple", "mango"} {
+		om.Set(k, i)
+	}
+	data, err := json.Marshal(&om)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"zebra":0,"apple":1,"mango":2}`; string(data) != want {
+		t.Errorf("Marshal = %s, want %s", data, want)
+	}
+	var back OrderedMap[string, int]
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if got := slices.Collect(back.Keys()); !slices.Equal(got, []string{"zebra", "apple", "mango"}) {
+		t.Errorf("round trip order = %v", got)
+	}
+
+	var sm SortedMap[float64, string]
+	sm.Set(2.5, "b")
+	sm.Set(-1, "a")
+	sm.Set(10, "c")
+	data, err = json.Marshal(&sm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"-1":"a","2.5":"b","10":"c"}`; string(data) != want {
+		t.Errorf("Marshal = %s, want %s", data, want)
+	}
+	var sback SortedMap[float64, string]
+	if err := json.Unmarshal(data, &sback); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := sback.Get(2.5); !ok || v != "b" || sback.Len() != 3 {
+		t.Errorf("round trip: Get(2.5) = %q, %v; Len = %d", v, ok, sback.Len())
+	}
+}
+
+// label is a string kind with its own text decoding.
+type label string
+
+func (l *label) UnmarshalText(b []byte) error {
+	*l = label(strings.ToUpper(string(b)))
+	return nil
+}
+
+func TestUnmarshalKeyLikeEncodingJSON(t *testing.T) {
+	data := []byte(`{"x":1,"y":2}`)
+	var plain map[label]int
+	if err := json.Unmarshal(data, &plain); err != nil {
+		t.Fatal(err)
+	}
+	var m OrderedMap[label, int]
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if got := maps.Collect(m.All()); !maps.Equal(got, plain) {
+		t.Errorf("OrderedMap decodes %v, encoding/json %v", got, plain)
+	}
+}
@@ -0,0 +1,135 @@
+package detmap
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"reflect"
+	"strconv"
+)
+
+// marshalObject encodes the pairs of seq as a JSON object, in seq's order.
+func marshalObject[K comparable, V any](seq iter.Seq2[K, V]) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	first := true
+	for k, v := range seq {
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+
+		ks, err := encodeKey(k)
+		if err != nil {
+			return nil, err
+		}
+		kb, _ := json.Marshal(ks)
+		buf.Write(kb)
+		buf.WriteByte(':')
+
+		vb, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(vb)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// unmarshalObject decodes a JSON object and calls set for each member, in
+// document order. A null document is an empty object.
+func unmarshalObject[K comparable, V any](data []byte, set func(K, V)) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return fmt.Errorf("detmap: cannot unmarshal %v into a map", tok)
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var k K
+		if err := decodeKey(tok.(string), &k); err != nil {
+			return err
+		}
+		var v V
+		if err := dec.Decode(&v); err != nil {
+			return err
+		}
+		set(k, v)
+	}
+	_, err = dec.Token()
+	return err
+}
+
+// encodeKey converts a map key to a JSON object key, following the rules
+// encoding/json uses for built-in maps: string kinds as-is, then
+// encoding.TextMarshaler, then integers. Floats are also accepted (as
+// strconv's shortest representation) so every cmp.Ordered key works.
+func encodeKey[K comparable](k K) (string, error) {
+	rv := reflect.ValueOf(k)
+	if rv.Kind() == reflect.String {
+		return rv.String(), nil
+	}
+	if tm, ok := any(k).(encoding.TextMarshaler); ok {
+		b, err := tm.MarshalText()
+		return string(b), err
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'g', -1, rv.Type().Bits()), nil
+	}
+	return "", fmt.Errorf("detmap: unsupported key type %T", k)
+}
+
+// decodeKey is the inverse of encodeKey. Like encoding/json, it prefers
+// encoding.TextUnmarshaler to setting a string kind directly, the opposite
+// of the order in which keys are encoded.
+func decodeKey[K comparable](s string, k *K) error {
+	if tu, ok := any(k).(encoding.TextUnmarshaler); ok {
+		return tu.UnmarshalText([]byte(s))
+	}
+	rv := reflect.ValueOf(k).Elem()
+	if rv.Kind() == reflect.String {
+		rv.SetString(s)
+		return nil
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, rv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("detmap: invalid key %q: %w", s, err)
+		}
+		rv.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, rv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("detmap: invalid key %q: %w", s, err)
+		}
+		rv.SetUint(n)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, rv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("detmap: invalid key %q: %w", s, err)
+		}
+		rv.SetFloat(f)
+		return nil
+	}
+	return fmt.Errorf("detmap: unsupported key type %T", *k)
+}
@@ -0,0 +1,154 @@
+package detmap
+
+import "iter"
+
+// OrderedMap is a map that iterates in insertion order. Setting an existing
+// key updates its value but keeps its position; deleting and re-adding a key
+// moves it to the end.
+//
+// The zero value is an empty map ready to use.
+type OrderedMap[K comparable, V any] struct {
+	index map[K]*entry[K, V]
+	// root is the sentinel of a circular doubly linked list: root.next is
+	// the oldest entry, root.prev the newest.
+	root entry[K, V]
+	seq  uint64 // the seq of the next new entry
+}
+
+type entry[K comparable, V any] struct {
+	key        K
+	value      V
+	prev, next *entry[K, V]
+	// seq numbers entries in the order they were added, so it increases
+	// along the list.
+	seq uint64
+	// deleted entries keep their prev and next links, so an iterator
+	// standing on one can still move on.
+	deleted bool
+}
+
+// NewOrdered returns an empty OrderedMap.
+func NewOrdered[K comparable, V any]() *OrderedMap[K, V] {
+	return &OrderedMap[K, V]{}
+}
+
+func (m *OrderedMap[K, V]) lazyInit() {
+	if m.index == nil {
+		m.index = make(map[K]*entry[K, V])
+		m.root.next = &m.root
+		m.root.prev = &m.root
+	}
+}
+
+// Len returns the number of entries.
+func (m *OrderedMap[K, V]) Len() int { return len(m.index) }
+
+// Get returns the value stored for key and whether it was present.
+func (m *OrderedMap[K, V]) Get(key K) (V, bool) {
+	if e, ok := m.index[key]; ok {
+		return e.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Has reports whether key is present.
+func (m *OrderedMap[K, V]) Has(key K) bool {
+	_, ok := m.index[key]
+	return ok
+}
+
+// Set stores value for key. A new key is appended at the end.
+func (m *OrderedMap[K, V]) Set(key K, value V) {
+	m.lazyInit()
+	if e, ok := m.index[key]; ok {
+		e.value = value
+		return
+	}
+	e := &entry[K, V]{key: key, value: value, prev: m.root.prev, next: &m.root, seq: m.seq}
+	m.seq++
+	m.root.prev.next = e
+	m.root.prev = e
+	m.index[key] = e
+}
+
+// Delete removes key and reports whether it was present.
+func (m *OrderedMap[K, V]) Delete(key K) bool {
+	e, ok := m.index[key]
+	if !ok {
+		return false
+	}
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.deleted = true
+	delete(m.index, key)
+	return true
+}
+
+// All returns an iterator over key-value pairs in insertion order.
+//
+// As with a built-in map, entries deleted during iteration are not
+// produced later. Entries added during iteration are not produced either,
+// including a deleted key set again: it moves to the end, and a loop that
+// moves every key there still ends.
+func (m *OrderedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if m.index == nil {
+			return
+		}
+		end := m.seq
+		for e := m.root.next; e != &m.root && e.seq < end; e = e.next {
+			if !e.deleted && !yield(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over key-value pairs, newest first.
+func (m *OrderedMap[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if m.index == nil {
+			return
+		}
+		for e := m.root.prev; e != &m.root; e = e.prev {
+			if !e.deleted && !yield(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+// Keys returns an iterator over keys in insertion order.
+func (m *OrderedMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range m.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iterator over values in insertion order.
+func (m *OrderedMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range m.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// MarshalJSON encodes m as a JSON object whose members appear in insertion
+// order. Keys follow encoding/json's rules for map keys.
+func (m *OrderedMap[K, V]) MarshalJSON() ([]byte, error) {
+	return marshalObject(m.All())
+}
+
+// UnmarshalJSON adds the members of a JSON object to m in document order.
+// Existing entries are kept.
+func (m *OrderedMap[K, V]) UnmarshalJSON(data []byte) error {
+	return unmarshalObject(data, m.Set)
+}
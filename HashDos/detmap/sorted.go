@@ -0,0 +1,237 @@
+package detmap
+
+import (
+	"cmp"
+	"iter"
+	"math/rand/v2"
+)
+
+// maxLevel bounds the height of the skip list. With p = 1/4 it comfortably
+// covers 4^16 entries.
+const maxLevel = 16
+
+// SortedMap is a map that iterates in ascending key order. It is a skip
+// list: Get, Set and Delete take O(log n) expected time.
+//
+// The zero value is an empty map ready to use.
+type SortedMap[K cmp.Ordered, V any] struct {
+	head  node[K, V] // sentinel; head.next[i] is the first node on level i
+	level int        // number of levels in use
+	n     int
+}
+
+type node[K cmp.Ordered, V any] struct {
+	key   K
+	value V
+	next  []*node[K, V]
+	// deleted nodes keep their links, so an iterator standing on one can
+	// still move on; the nodes those links reach may be deleted too.
+	deleted bool
+}
+
+// NewSorted returns an empty SortedMap.
+func NewSorted[K cmp.Ordered, V any]() *SortedMap[K, V] {
+	return &SortedMap[K, V]{}
+}
+
+func (m *SortedMap[K, V]) lazyInit() {
+	if m.head.next == nil {
+		m.head.next = make([]*node[K, V], maxLevel)
+		m.level = 1
+	}
+}
+
+// Len returns the number of entries.
+func (m *SortedMap[K, V]) Len() int { return m.n }
+
+// seek fills update[i] with the last node on level i whose key is < key,
+// and returns the first node with key >= key (or nil).
+func (m *SortedMap[K, V]) seek(key K, update []*node[K, V]) *node[K, V] {
+	x := &m.head
+	for i := m.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && cmp.Less(x.next[i].key, key) {
+			x = x.next[i]
+		}
+		if update != nil {
+			update[i] = x
+		}
+	}
+	return x.next[0]
+}
+
+// Get returns the value stored for key and whether it was present.
+func (m *SortedMap[K, V]) Get(key K) (V, bool) {
+	if m.n > 0 {
+		if x := m.seek(key, nil); x != nil && cmp.Compare(x.key, key) == 0 {
+			return x.value, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// Has reports whether key is present.
+func (m *SortedMap[K, V]) Has(key K) bool {
+	_, ok := m.Get(key)
+	return ok
+}
+
+// Set stores value for key.
+func (m *SortedMap[K, V]) Set(key K, value V) {
+	m.lazyInit()
+	var update [maxLevel]*node[K, V]
+	x := m.seek(key, update[:])
+	if x != nil && cmp.Compare(x.key, key) == 0 {
+		x.value = value
+		return
+	}
+
+	lvl := randomLevel()
+	for ; m.level < lvl; m.level++ {
+		update[m.level] = &m.head
+	}
+	x = &node[K, V]{key: key, value: value, next: make([]*node[K, V], lvl)}
+	for i := range lvl {
+		x.next[i] = update[i].next[i]
+		update[i].next[i] = x
+	}
+	m.n++
+}
+
+// Delete removes key and reports whether it was present.
+func (m *SortedMap[K, V]) Delete(key K) bool {
+	if m.n == 0 {
+		return false
+	}
+	var update [maxLevel]*node[K, V]
+	x := m.seek(key, update[:])
+	if x == nil || cmp.Compare(x.key, key) != 0 {
+		return false
+	}
+	for i := range x.next {
+		update[i].next[i] = x.next[i]
+	}
+	x.deleted = true
+	for m.level > 1 && m.head.next[m.level-1] == nil {
+		m.level--
+	}
+	m.n--
+	return true
+}
+
+// randomLevel returns a node height: 1 with probability 3/4, 2 with 3/16...
+func randomLevel() int {
+	lvl := 1
+	for lvl < maxLevel && rand.IntN(4) == 0 {
+		lvl++
+	}
+	return lvl
+}
+
+// Min returns the smallest key and its value.
+func (m *SortedMap[K, V]) Min() (K, V, bool) {
+	if m.n == 0 {
+		var k K
+		var v V
+		return k, v, false
+	}
+	x := m.head.next[0]
+	return x.key, x.value, true
+}
+
+// Max returns the largest key and its value.
+func (m *SortedMap[K, V]) Max() (K, V, bool) {
+	if m.n == 0 {
+		var k K
+		var v V
+		return k, v, false
+	}
+	x := &m.head
+	for i := m.level - 1; i >= 0; i-- {
+		for x.next[i] != nil {
+			x = x.next[i]
+		}
+	}
+	return x.key, x.value, true
+}
+
+// All returns an iterator over key-value pairs in ascending key order.
+//
+// As with a built-in map, entries deleted during iteration are not
+// produced later, and entries added during iteration may or may not be.
+func (m *SortedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if m.n == 0 {
+			return
+		}
+		for x := m.head.next[0]; x != nil; x = x.next[0] {
+			if !x.deleted && !yield(x.key, x.value) {
+				return
+			}
+		}
+	}
+}
+
+// Range returns an iterator over the entries with lo <= key < hi, in
+// ascending key order.
+func (m *SortedMap[K, V]) Range(lo, hi K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if m.n == 0 {
+			return
+		}
+		for x := m.seek(lo, nil); x != nil && cmp.Less(x.key, hi); x = x.next[0] {
+			if !x.deleted && !yield(x.key, x.value) {
+				return
+			}
+		}
+	}
+}
+
+// From returns an iterator over the entries with key >= lo, in ascending
+// key order.
+func (m *SortedMap[K, V]) From(lo K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if m.n == 0 {
+			return
+		}
+		for x := m.seek(lo, nil); x != nil; x = x.next[0] {
+			if !x.deleted && !yield(x.key, x.value) {
+				return
+			}
+		}
+	}
+}
+
+// Keys returns an iterator over keys in ascending order.
+func (m *SortedMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range m.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iterator over values in ascending key order.
+func (m *SortedMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range m.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// MarshalJSON encodes m as a JSON object whose members appear in ascending
+// key order.
+func (m *SortedMap[K, V]) MarshalJSON() ([]byte, error) {
+	return marshalObject(m.All())
+}
+
+// UnmarshalJSON adds the members of a JSON object to m. Existing entries
+// are kept.
+func (m *SortedMap[K, V]) UnmarshalJSON(data []byte) error {
+	return unmarshalObject(data, m.Set)
+}
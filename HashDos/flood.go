@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ----------------------------------------
+// The attacker: flooding the server with colliding keys
+// ----------------------------------------
+
+// floodConfig controls runFlood.
+type floodConfig struct {
+	keys      int           // keys per attack request
+	attackers int           // concurrent attacking clients in the latency test
+	duration  time.Duration // length of the latency test per table
+	def       defenses      // limits of the defended server
+}
+
+// runFlood starts an undefended and a defended server on loopback and
+// attacks both:
+//
+//  1. Cost of one request: random vs colliding keys, for each table and
+//     encoding (query string, form body, JSON object).
+//  2. Latency under attack: attackers send colliding keys non-stop while a
+//     well-behaved client measures how long its small requests take.
+func runFlood(cfg floodConfig) error {
+	open, err := startServer(defenses{})
+	if err != nil {
+		return err
+	}
+	defer open.Close()
+
+	guarded, err := startServer(cfg.def)
+	if err != nil {
+		return err
+	}
+	defer guarded.Close()
+
+	client := &http.Client{Timeout: time.Minute}
+	colliding := collidingKeys(cfg.keys)
+	random := randomKeys(cfg.keys)
+
+	fmt.Printf("Every colliding key has naiveHash = %#x; random keys have the same length (%d bytes).\n",
+		naiveHash(colliding[0]), len(colliding[0]))
+	fmt.Printf("Server CPU is measured as %s.\n\n", cpuClockName)
+
+	fmt.Printf("== 1. Cost of a single request with %d keys ==\n\n", cfg.keys)
+	fmt.Printf("%-8s %-8s %-8s %-9s %6s %12s %12s %12s\n",
+		"server", "table", "body", "keys", "status", "latency", "server cpu", "probes")
+	for _, srv := range []*runningServer{open, guarded} {
+		for _, kind := range []string{"naive", "builtin"} {
+			for _, enc := range []string{"query", "form", "json"} {
+				for _, ks := range []struct {
+					name string
+					keys []string
+				}{{"random", random}, {"colliding", colliding}} {
+					start := time.Now()
+					res, status, err := sendKeys(client, srv.url+"/"+kind, enc, ks.keys)
+					if err != nil {
+						return err
+					}
+					probes := "-"
+					if res.Probes >= 0 {
+						probes = fmt.Sprint(res.Probes)
+					}
+					fmt.Printf("%-8s %-8s %-8s %-9s %6d %12v %12v %12s\n",
+						srv.name, kind, enc, ks.name, status,
+						round(time.Since(start)), round(time.Duration(res.CPUNs)), probes)
+				}
+			}
+		}
+	}
+
+	fmt.Printf("\n== 2. Latency of a normal client while %d attackers flood for %v ==\n\n",
+		cfg.attackers, cfg.duration)
+	fmt.Printf("%-8s %-8s %10s %10s %10s %10s %10s\n",
+		"server", "table", "attacks", "rejected", "p50", "p99", "max")
+	for _, srv := range []*runningServer{open, guarded} {
+		for _, kind := range []string{"naive", "builtin"} {
+			r := underAttack(client, srv.url+"/"+kind, colliding, cfg)
+			p50, p99, worst := percentiles(r.latencies)
+			fmt.Printf("%-8s %-8s %10d %10d %10v %10v %10v\n",
+				srv.name, kind, r.attacks, r.rejected, round(p50), round(p99), round(worst))
+		}
+	}
+
+	for _, srv := range []*runningServer{open, guarded} {
+		fmt.Printf("\n== GET %s/metrics (%s server, defenses: %v) ==\n\n", srv.url, srv.name, srv.def)
+		resp, err := client.Get(srv.url + "/metrics")
+		if err != nil {
+			return err
+		}
+		io.Copy(os.Stdout, resp.Body)
+		resp.Body.Close()
+	}
+
+	fmt.Println(`
+Reading the results:
+  - The naive table's probes grow with n² for colliding keys, and linearly
+    for random keys. Go's map costs the same for both: with a random seed,
+    there is no way to precompute keys that collide.
+  - Limits on key count and body size bound n, so they bound the damage
+    even for a naive table; the CPU budget cuts off whatever slips through.`)
+	return nil
+}
+
+// runningServer is a hashServer listening on a loopback port.
+type runningServer struct {
+	name string
+	url  string
+	def  defenses
+	srv  *http.Server
+}
+
+func (s *runningServer) Close() error {
+	return s.srv.Shutdown(context.Background())
+}
+
+// startServer starts a hashServer with the given defenses on a random
+// loopback port.
+func startServer(def defenses) (*runningServer, error) {
+	ln, err := listenLoopback("127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	name := "open"
+	if def != (defenses{}) {
+		name = "guarded"
+	}
+	srv := &http.Server{Handler: newHashServer(def).handler()}
+	go srv.Serve(ln)
+	return &runningServer{name: name, url: "http://" + ln.Addr().String(), def: def, srv: srv}, nil
+}
+
+// sendKeys sends keys to u in the given encoding and decodes the response.
+func sendKeys(client *http.Client, u, enc string, keys []string) (storeResult, int, error) {
+	var req *http.Request
+	var err error
+	switch enc {
+	case "query":
+		req, err = http.NewRequest(http.MethodGet, u+"?"+encodeForm(keys), nil)
+	case "form":
+		req, err = http.NewRequest(http.MethodPost, u, strings.NewReader(encodeForm(keys)))
+		if req != nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	case "json":
+		req, err = http.NewRequest(http.MethodPost, u, bytes.NewReader(encodeJSON(keys)))
+		if req != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+	default:
+		err = fmt.Errorf("unknown encoding %q", enc)
+	}
+	if err != nil {
+		return storeResult{}, 0, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return storeResult{}, 0, err
+	}
+	defer resp.Body.Close()
+
+	var res storeResult
+	// A body rejected by http.MaxBytesReader may come back without JSON.
+	json.NewDecoder(resp.Body).Decode(&res)
+	return res, resp.StatusCode, nil
+}
+
+// encodeForm encodes keys as "k1=1&k2=1...". It does not use url.Values
+// because that is itself a map, and would reorder the keys.
+func encodeForm(keys []string) string {
+	var sb strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteByte('&')
+		}
+		sb.WriteString(url.QueryEscape(k))
+		sb.WriteString("=1")
+	}
+	return sb.String()
+}
+
+// encodeJSON encodes keys as {"k1":1,"k2":1,...}.
+func encodeJSON(keys []string) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		kb, _ := json.Marshal(k)
+		buf.Write(kb)
+		buf.WriteString(":1")
+	}
+	buf.WriteByte('}')
+	return buf.Bytes()
+}
+
+type attackResult struct {
+	attacks   int64
+	rejected  int64
+	latencies []time.Duration // of the normal client
+}
+
+// underAttack floods u with colliding form bodies for cfg.duration while a
+// normal client sends 10-key requests and records their latency.
+func underAttack(client *http.Client, u string, colliding []string, cfg floodConfig) attackResult {
+	var r attackResult
+	body := encodeForm(colliding)
+	deadline := time.Now().Add(cfg.duration)
+
+	var wg sync.WaitGroup
+	for range cfg.attackers {
+		wg.Go(func() {
+			for time.Now().Before(deadline) {
+				resp, err := client.Post(u, "application/x-www-form-urlencoded", strings.NewReader(body))
+				if err != nil {
+					continue
+				}
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+				atomic.AddInt64(&r.attacks, 1)
+				if resp.StatusCode != http.StatusOK {
+					atomic.AddInt64(&r.rejected, 1)
+				}
+			}
+		})
+	}
+
+	normal := randomKeys(10)
+	for time.Now().Before(deadline) {
+		start := time.Now()
+		if _, _, err := sendKeys(client, u, "form", normal); err == nil {
+			r.latencies = append(r.latencies, time.Since(start))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	wg.Wait()
+	return r
+}
@@ -0,0 +1,433 @@
+// Package hashqa measures the quality of hash functions, to back up the
+// claims HashDos makes about seeded hashing with numbers.
+//
+// Each function is put through four kinds of tests:
+//
+//   - Strict avalanche criterion (SAC): flipping any single input bit should
+//     flip each output bit with probability 1/2.
+//   - Bit independence criterion (BIC): when an input bit is flipped, any two
+//     output bits should change independently of each other.
+//   - Bucket distribution: chi-square over hash-table buckets for sequential,
+//     random and adversarial key sets. The adversarial keys are found by
+//     brute force against one instance of the function (the attacker's copy)
+//     and then hashed by another instance (the server's), which is exactly
+//     the situation a per-map random seed is designed for.
+//   - Seed sensitivity: two instances should disagree on about half of the
+//     output bits for the same input. Unseeded functions agree on all of
+//     them.
+//
+// Results are collected in a Scorecard.
+package hashqa
+
+import (
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"hash/fnv"
+	"hash/maphash"
+	"io"
+	"math"
+	"math/bits"
+	"math/rand/v2"
+
+	"Lets-GO/internal/stats"
+)
+
+// Func describes a hash function under test.
+type Func struct {
+	Name string
+	Bits int // output width: 32 or 64
+
+	// New returns an instance of the function. Seeded functions return an
+	// instance with a fresh random seed on every call; unseeded functions
+	// return the same function every time.
+	New func() func([]byte) uint64
+}
+
+// Standard returns the functions from the standard library worth comparing:
+// hash/maphash (seeded), FNV-1a 32 and 64, CRC-32 IEEE and Castagnoli.
+func Standard() []Func {
+	castagnoli := crc32.MakeTable(crc32.Castagnoli)
+	return []Func{
+		{Name: "maphash", Bits: 64, New: func() func([]byte) uint64 {
+			seed := maphash.MakeSeed()
+			return func(b []byte) uint64 { return maphash.Bytes(seed, b) }
+		}},
+		{Name: "fnv32a", Bits: 32, New: func() func([]byte) uint64 {
+			return func(b []byte) uint64 {
+				h := fnv.New32a()
+				h.Write(b)
+				return uint64(h.Sum32())
+			}
+		}},
+		{Name: "fnv64a", Bits: 64, New: func() func([]byte) uint64 {
+			return func(b []byte) uint64 {
+				h := fnv.New64a()
+				h.Write(b)
+				return h.Sum64()
+			}
+		}},
+		{Name: "crc32", Bits: 32, New: func() func([]byte) uint64 {
+			return func(b []byte) uint64 { return uint64(crc32.ChecksumIEEE(b)) }
+		}},
+		{Name: "crc32c", Bits: 32, New: func() func([]byte) uint64 {
+			return func(b []byte) uint64 { return uint64(crc32.Checksum(b, castagnoli)) }
+		}},
+	}
+}
+
+// Config controls the size of the tests. The zero value uses the defaults.
+type Config struct {
+	Samples     int // inputs for the SAC and BIC tests (default 1000; see Result.Samples)
+	InputBytes  int // input length for the SAC and BIC tests (default 8)
+	Keys        int // keys in the sequential and random key sets (default 20000)
+	Adversarial int // keys in the adversarial key set (default 2000)
+	Buckets     int // hash-table buckets, a power of two (default 256)
+}
+
+func (c Config) withDefaults() Config {
+	if c.Samples <= 0 {
+		c.Samples = defaultSamples
+	}
+	if c.InputBytes <= 0 {
+		c.InputBytes = 8
+	}
+	if c.Keys <= 0 {
+		c.Keys = 20000
+	}
+	if c.Adversarial <= 0 {
+		c.Adversarial = 2000
+	}
+	if c.Buckets <= 0 || c.Buckets&(c.Buckets-1) != 0 {
+		c.Buckets = 256
+	}
+	return c
+}
+
+// Result is the outcome of all tests for one function.
+type Result struct {
+	Name string
+	Bits int
+
+	// Samples is the number of inputs behind SACMaxBias and BICMaxCorr.
+	// Their pass limits shrink as 1/√Samples; below about 100 samples only
+	// grossly biased functions fail.
+	Samples int
+
+	SACMaxBias float64 // largest |P(output bit flips) - 1/2|
+	SACP       float64 // chi-square p-value over all (input bit, output bit) cells
+	BICMaxCorr float64 // largest |correlation| between two output bit flips
+
+	Dist []DistResult // sequential, random, adversarial
+
+	SeedDiff float64 // mean fraction of output bits two instances disagree on
+}
+
+// DistResult is the bucket distribution of one key set.
+type DistResult struct {
+	KeySet  string
+	Keys    int
+	Wanted  int     // keys asked for; the adversarial search can find fewer
+	P       float64 // chi-square p-value against a uniform distribution
+	MaxLoad int     // keys in the fullest bucket
+}
+
+// Complete reports whether the key set has all the keys asked for. A
+// p-value over fewer keys, or none, says nothing about the function.
+func (d DistResult) Complete() bool { return d.Keys > 0 && d.Keys >= d.Wanted }
+
+// Seeded reports whether two instances of the function behaved differently.
+func (r Result) Seeded() bool { return r.SeedDiff > 0 }
+
+// Thresholds used by the verdicts.
+const (
+	alpha          = 0.001
+	minSeedDiff    = 0.45
+	limitSDs       = 6 // standard deviations of sampling noise the SAC and BIC limits allow
+	defaultSamples = 1000
+)
+
+// sacLimit is the largest SAC bias that passes. With n samples a fair bit
+// flips n/2 ± √n/2 times, so the bias has a standard deviation of 0.5/√n.
+func (r Result) sacLimit() float64 {
+	return limitSDs * 0.5 / math.Sqrt(float64(cmp.Or(r.Samples, defaultSamples)))
+}
+
+// bicLimit is the largest BIC correlation that passes. The correlation of
+// two independent bits over n samples has a standard deviation of 1/√n.
+func (r Result) bicLimit() float64 {
+	return limitSDs / math.Sqrt(float64(cmp.Or(r.Samples, defaultSamples)))
+}
+
+// Verdict summarizes a Result in a few words.
+func (r Result) Verdict() string {
+	if len(r.Dist) < 3 {
+		return "incomplete: missing key sets"
+	}
+	mixing := r.SACMaxBias < r.sacLimit() && r.BICMaxCorr < r.bicLimit()
+	benign := true
+	for _, d := range r.Dist[:2] {
+		benign = benign && d.P >= alpha
+	}
+	searched := r.Dist[2].Complete()
+	adversarial := searched && r.Dist[2].P >= alpha
+
+	switch {
+	case mixing && benign && adversarial && r.SeedDiff >= minSeedDiff:
+		return "safe for untrusted keys"
+	case mixing && benign && !searched:
+		return "inconclusive: adversarial search found too few keys"
+	case mixing && benign:
+		return "trusted keys only (collisions can be precomputed)"
+	case benign:
+		return "poor mixing; trusted keys only"
+	default:
+		return "weak: skewed even on benign keys"
+	}
+}
+
+// Evaluate runs every test on f.
+func Evaluate(f Func, cfg Config) Result {
+	cfg = cfg.withDefaults()
+	r := Result{Name: f.Name, Bits: f.Bits, Samples: cfg.Samples}
+
+	h := f.New()
+	r.SACMaxBias, r.SACP, r.BICMaxCorr = avalanche(h, f.Bits, cfg)
+
+	mask := uint64(cfg.Buckets - 1)
+	r.Dist = []DistResult{
+		distribution("sequential", h, sequentialKeys(cfg.Keys), cfg.Buckets),
+		distribution("random", h, randomKeys(cfg.Keys, 16), cfg.Buckets),
+		distribution("adversarial", f.New(), adversarialKeys(f.New(), cfg.Adversarial, mask), cfg.Buckets),
+	}
+	r.Dist[2].Wanted = cfg.Adversarial
+
+	r.SeedDiff = seedDiff(f.New(), f.New(), f.Bits)
+	return r
+}
+
+// avalanche runs the SAC and BIC tests together: both look at which output
+// bits change when one input bit is flipped.
+func avalanche(h func([]byte) uint64, outBits int, cfg Config) (maxBias, sacP, maxCorr float64) {
+	inBits := cfg.InputBytes * 8
+	n := cfg.Samples
+
+	// flips[i][j]: times output bit j changed when input bit i was flipped.
+	// pairs[i][j*outBits+k]: times output bits j and k (j < k) both changed.
+	flips := make([][]int, inBits)
+	pairs := make([][]int32, inBits)
+	for i := range inBits {
+		flips[i] = make([]int, outBits)
+		pairs[i] = make([]int32, outBits*outBits)
+	}
+
+	x := make([]byte, cfg.InputBytes)
+	y := make([]byte, cfg.InputBytes)
+	outMask := uint64(1)<<outBits - 1
+	if outBits == 64 {
+		outMask = math.MaxUint64
+	}
+	var set [64]int
+	for range n {
+		fillRandom(x)
+		hx := h(x)
+		for i := range inBits {
+			copy(y, x)
+			y[i/8] ^= 1 << (i % 8)
+			d := (hx ^ h(y)) & outMask
+
+			m := 0
+			for d != 0 {
+				j := bits.TrailingZeros64(d)
+				d &= d - 1
+				set[m] = j
+				m++
+			}
+			for a := 0; a < m; a++ {
+				flips[i][set[a]]++
+				row := set[a] * outBits
+				for b := a + 1; b < m; b++ {
+					pairs[i][row+set[b]]++
+				}
+			}
+		}
+	}
+
+	var chi float64
+	for i := range inBits {
+		for j := range outBits {
+			c := float64(flips[i][j])
+			chi += stats.ChiTerm(c, float64(n)/2) + stats.ChiTerm(float64(n)-c, float64(n)/2)
+			maxBias = max(maxBias, math.Abs(c/float64(n)-0.5))
+		}
+	}
+	sacP = stats.ChiSquareP(chi, inBits*outBits)
+
+	nf := float64(n)
+	for i := range inBits {
+		for j := range outBits {
+			for k := j + 1; k < outBits; k++ {
+				sa, sb := float64(flips[i][j]), float64(flips[i][k])
+				sab := float64(pairs[i][j*outBits+k])
+				va, vb := nf*sa-sa*sa, nf*sb-sb*sb
+				if va == 0 || vb == 0 {
+					// A bit that always or never flips is fully determined
+					// by the input bit: as dependent as it gets.
+					maxCorr = 1
+					continue
+				}
+				maxCorr = max(maxCorr, math.Abs((nf*sab-sa*sb)/math.Sqrt(va*vb)))
+			}
+		}
+	}
+	return maxBias, sacP, maxCorr
+}
+
+// distribution hashes keys into buckets (low bits of the hash, as a table
+// with a power-of-two size would) and tests the counts for uniformity.
+func distribution(name string, h func([]byte) uint64, keys [][]byte, buckets int) DistResult {
+	counts := make([]int, buckets)
+	mask := uint64(buckets - 1)
+	for _, k := range keys {
+		counts[h(k)&mask]++
+	}
+
+	expected := float64(len(keys)) / float64(buckets)
+	var chi float64
+	maxLoad := 0
+	for _, c := range counts {
+		chi += stats.ChiTerm(float64(c), expected)
+		maxLoad = max(maxLoad, c)
+	}
+	return DistResult{
+		KeySet:  name,
+		Keys:    len(keys),
+		Wanted:  len(keys),
+		P:       stats.ChiSquareP(chi, buckets-1),
+		MaxLoad: maxLoad,
+	}
+}
+
+// seedDiff returns the mean fraction of output bits on which two instances
+// disagree, over random inputs.
+func seedDiff(a, b func([]byte) uint64, outBits int) float64 {
+	const n = 1000
+	x := make([]byte, 16)
+	total := 0
+	for range n {
+		fillRandom(x)
+		total += bits.OnesCount64(a(x) ^ b(x))
+	}
+	return float64(total) / float64(n*outBits)
+}
+
+// sequentialKeys returns 0..n-1 as 8-byte little-endian integers.
+func sequentialKeys(n int) [][]byte {
+	keys := make([][]byte, n)
+	for i := range keys {
+		keys[i] = binary.LittleEndian.AppendUint64(nil, uint64(i))
+	}
+	return keys
+}
+
+func randomKeys(n, length int) [][]byte {
+	keys := make([][]byte, n)
+	for i := range keys {
+		keys[i] = make([]byte, length)
+		fillRandom(keys[i])
+	}
+	return keys
+}
+
+// adversarialKeys plays the attacker: it searches random 8-byte keys until
+// it has n that attacker() puts in bucket 0. Against an unseeded function
+// they all collide on the server too; against a seeded one they should not.
+//
+// The search gives up after 64 times the expected number of tries, which
+// only happens for functions that hardly ever reach bucket 0. It then
+// returns fewer than n keys, and the result is inconclusive.
+func adversarialKeys(attacker func([]byte) uint64, n int, mask uint64) [][]byte {
+	keys := make([][]byte, 0, n)
+	x := make([]byte, 8)
+	for tries := 64 * n * int(mask+1); len(keys) < n && tries > 0; tries-- {
+		fillRandom(x)
+		if attacker(x)&mask == 0 {
+			keys = append(keys, append([]byte(nil), x...))
+		}
+	}
+	return keys
+}
+
+func fillRandom(b []byte) {
+	for i := 0; i < len(b); i += 8 {
+		var w [8]byte
+		binary.LittleEndian.PutUint64(w[:], rand.Uint64())
+		copy(b[i:], w[:])
+	}
+}
+
+// ----------------------------------------
+// Scorecard
+// ----------------------------------------
+
+// Scorecard is the set of results for several functions.
+type Scorecard []Result
+
+// Run evaluates every function in fs.
+func Run(fs []Func, cfg Config) Scorecard {
+	sc := make(Scorecard, 0, len(fs))
+	for _, f := range fs {
+		sc = append(sc, Evaluate(f, cfg))
+	}
+	return sc
+}
+
+// distP formats the p-value of key set i, or "-" if it is missing or
+// incomplete.
+func (r Result) distP(i int) string {
+	if i >= len(r.Dist) || !r.Dist[i].Complete() {
+		return "-"
+	}
+	return stats.FormatP(r.Dist[i].P)
+}
+
+// Print writes the scorecard as a table followed by a legend.
+func (sc Scorecard) Print(w io.Writer) {
+	fmt.Fprintf(w, "%-12s %4s %8s %8s %8s %8s %8s %8s %8s  %s\n",
+		"hash", "bits", "SAC bias", "SAC p", "BIC corr", "seq p", "rand p", "adv p", "seed Δ", "verdict")
+	for _, r := range sc {
+		fmt.Fprintf(w, "%-12s %4d %8.3f %8s %8.3f %8s %8s %8s %8.3f  %s\n",
+			r.Name, r.Bits, r.SACMaxBias, stats.FormatP(r.SACP), r.BICMaxCorr,
+			r.distP(0), r.distP(1), r.distP(2),
+			r.SeedDiff, r.Verdict())
+	}
+
+	fmt.Fprintf(w, "\nMax bucket load (sequential / random / adversarial keys):\n")
+	for _, r := range sc {
+		fmt.Fprintf(w, "  %-12s", r.Name)
+		for _, d := range r.Dist {
+			fmt.Fprintf(w, " %6d/%d", d.MaxLoad, d.Keys)
+			if !d.Complete() {
+				fmt.Fprintf(w, " (of %d wanted)", d.Wanted)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	var limits Result // every result of a Run has the same sample count
+	if len(sc) > 0 {
+		limits = sc[0]
+	}
+	fmt.Fprintf(w, `
+Legend:
+  SAC bias   largest |P(output bit flips) - 0.5| when one input bit flips (ideal 0; pass < %.3f)
+  SAC p      chi-square p-value over all input/output bit pairs (pass >= %g)
+  BIC corr   largest |correlation| between two output bit flips (ideal 0; pass < %.3f)
+  seq/rand   bucket uniformity for sequential and random keys
+  adv p      bucket uniformity for keys an attacker brute-forced into one bucket
+             using their own instance of the hash ("-" when the search gave
+             up before finding them all)
+  seed Δ     fraction of output bits two instances disagree on (0 = unseeded; ideal 0.5)
+`, limits.sacLimit(), alpha, limits.bicLimit())
+}
@@ -0,0 +1,56 @@
+package hashqa
+
+import (
+	"strings"
+	"testing"
+)
+
+// A function that never reaches bucket 0 leaves the attacker without keys.
+func TestAdversarialSearchCanGiveUp(t *testing.T) {
+	f := Func{Name: "odd", Bits: 64, New: func() func([]byte) uint64 {
+		h := Standard()[0].New()
+		return func(b []byte) uint64 { return h(b) | 1 }
+	}}
+	r := Evaluate(f, Config{Samples: 100, Keys: 2000, Adversarial: 10, Buckets: 16})
+	if d := r.Dist[2]; d.Keys != 0 || d.Complete() {
+		t.Errorf("adversarial key set: %d keys, complete %v; want 0, false", d.Keys, d.Complete())
+	}
+}
+
+// An empty key set has p = 1, which proves nothing about the function.
+func TestVerdictNeedsCompleteKeySets(t *testing.T) {
+	good := Result{
+		Dist: []DistResult{
+			{Keys: 100, Wanted: 100, P: 0.5},
+			{Keys: 100, Wanted: 100, P: 0.5},
+			{Keys: 100, Wanted: 100, P: 0.5},
+		},
+		SeedDiff: 0.5,
+	}
+	if v := good.Verdict(); v != "safe for untrusted keys" {
+		t.Fatalf("Verdict() = %q for a good result", v)
+	}
+
+	short := good
+	short.Dist = append([]DistResult(nil), good.Dist...)
+	short.Dist[2] = DistResult{Keys: 0, Wanted: 100, P: 1}
+	if v := short.Verdict(); !strings.HasPrefix(v, "inconclusive") {
+		t.Errorf("Verdict() = %q after a failed search, want inconclusive", v)
+	}
+
+	if v := (Result{}).Verdict(); !strings.HasPrefix(v, "incomplete") {
+		t.Errorf("Verdict() = %q without key sets, want incomplete", v)
+	}
+}
+
+// With few samples, sampling noise alone is large; the limits must grow
+// with it so that a good hash still passes.
+func TestFewSamplesStillMix(t *testing.T) {
+	for _, n := range []int{30, 100, 1000} {
+		r := Evaluate(Standard()[0], Config{Samples: n, Keys: 2000, Adversarial: 10, Buckets: 16})
+		if r.SACMaxBias >= r.sacLimit() || r.BICMaxCorr >= r.bicLimit() {
+			t.Errorf("maphash with %d samples: SAC bias %.3f (limit %.3f), BIC corr %.3f (limit %.3f)",
+				n, r.SACMaxBias, r.sacLimit(), r.BICMaxCorr, r.bicLimit())
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"Lets-GO/HashDos/hashqa"
+)
+
+// ----------------------------------------
+// Which hash is safe for our own data structures?
+// ----------------------------------------
+
+// runHashQuality prints a hashqa scorecard for the standard library hashes
+// and for naiveHash, the unseeded hash behind naiveTable. Any function with
+// the signature func([]byte) uint64 can be added to the list the same way.
+func runHashQuality(cfg hashqa.Config) {
+	funcs := append(hashqa.Standard(), hashqa.Func{
+		Name: "naiveHash",
+		Bits: 32,
+		New: func() func([]byte) uint64 {
+			return func(b []byte) uint64 { return uint64(naiveHash(string(b))) }
+		},
+	})
+
+	fmt.Println("Hash function scorecard")
+	fmt.Println("-----------------------")
+	hashqa.Run(funcs, cfg).Print(os.Stdout)
+
+	fmt.Println(`
+Only a seeded hash survives the adversarial key set: keys that collide for
+the attacker's copy of the function spread out evenly under the server's
+seed. That is the property explainConcepts describes for Go's maps, and the
+reason to build on hash/maphash rather than FNV or CRC-32 when keys come
+from outside.`)
+}
@@ -16,21 +16,116 @@
 // spec and can change between versions. The important guarantees are:
 //   - You must NOT rely on map iteration order.
 //   - Map hashing is not stable or predictable across runs.
+//
+// Modes (go run ./HashDos -mode=...):
+//   demo   the explanation below (default)
+//   serve  a localhost HTTP server that parses parameters into a naive,
+//          unseeded hash table or a Go map (see server.go)
+//   flood  attack that server with colliding keys and report the cost, with
+//          and without defenses (see flood.go)
+//   order  run thousands of range loops and test how random the iteration
+//          order really is (see order.go)
+//   detmap deterministic alternatives: insertion-ordered and sorted maps
+//          (see deterministic.go and package detmap)
+//   hashqa avalanche, distribution and seed tests for hash functions
+//          (see hashquality.go and package hashqa)
+//
+// The seeded Bloom filters and count-min sketches in package sketch are
+// checked against theory by go test ./HashDos/sketch. The sharded map in
+// package shardmap, which picks a shard with a seeded hash, has race tests
+// and benchmarks against a mutex and sync.Map:
+//
+//	go test -race -bench . ./HashDos/shardmap
 
 package main
 
-import "fmt"
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"Lets-GO/HashDos/hashqa"
+)
 
 func main() {
-	demoMapIterationOrder()
-	explainConcepts()
+	mode := flag.String("mode", "demo", "demo, serve, flood, order, detmap, or hashqa")
+	addr := flag.String("addr", "127.0.0.1:8080", "serve: loopback address to listen on")
+	keys := flag.Int("keys", 8192, "flood: keys per attack request")
+	attackers := flag.Int("attackers", 4, "flood: concurrent attackers in the latency test")
+	duration := flag.Duration("duration", time.Second, "flood: length of the latency test per table")
+	maxKeys := flag.Int("max-keys", 1000, "defense: max parameters per request (0 = off)")
+	maxBody := flag.Int64("max-body", 64<<10, "defense: max request body bytes (0 = off)")
+	cpuBudget := flag.Duration("cpu-budget", 20*time.Millisecond, "defense: max CPU time per request (0 = off)")
+	iterations := flag.Int("iterations", 10000, "order: range loops per map")
+	sizes := flag.String("sizes", "5,8,9,64,1024", "order: comma-separated map sizes")
+	keyTypes := flag.String("keytypes", "int,string", "order: comma-separated key types (int, string)")
+	samples := flag.Int("samples", 1000, "hashqa: inputs for the avalanche tests")
+	flag.Parse()
+
+	if *keys < 1 {
+		fmt.Fprintln(os.Stderr, "error: -keys must be at least 1")
+		os.Exit(2)
+	}
+	if *iterations < 1 {
+		fmt.Fprintln(os.Stderr, "error: -iterations must be at least 1")
+		os.Exit(2)
+	}
+	if *samples < 1 {
+		fmt.Fprintln(os.Stderr, "error: -samples must be at least 1")
+		os.Exit(2)
+	}
+	def := defenses{MaxKeys: *maxKeys, MaxBodyBytes: *maxBody, CPUBudget: *cpuBudget}
+
+	var err error
+	switch *mode {
+	case "demo":
+		demoMapIterationOrder()
+		explainConcepts()
+	case "serve":
+		err = serve(*addr, def)
+	case "flood":
+		err = runFlood(floodConfig{keys: *keys, attackers: *attackers, duration: *duration, def: def})
+	case "order":
+		var ns []int
+		ns, err = parseSizes(*sizes)
+		if err == nil {
+			analyzeIterationOrder(orderConfig{iterations: *iterations, sizes: ns, keyTypes: strings.Split(*keyTypes, ",")})
+		}
+	case "detmap":
+		demoDeterministicMaps()
+	case "hashqa":
+		runHashQuality(hashqa.Config{Samples: *samples})
+	default:
+		err = fmt.Errorf("unknown -mode %q", *mode)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+// serve runs a hashServer with the given defenses until interrupted.
+func serve(addr string, def defenses) error {
+	ln, err := listenLoopback(addr)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Listening on http://%s (defenses: %v)\n", ln.Addr(), def)
+	fmt.Println("  POST /naive    store parameters in the naive table")
+	fmt.Println("  POST /builtin  store parameters in a Go map")
+	fmt.Println("  GET  /metrics  latency and rejections per table")
+	return http.Serve(ln, newHashServer(def).handler())
 }
 
 // demoMapIterationOrder prints the order of keys for the same map multiple times.
 // You will see that the order may differ between runs, and can even differ
 // between loops in the same execution.
 func demoMapIterationOrder() {
-	fmt.Println("Demonstrating non-deterministic map iteration order:\n")
+	fmt.Println("Demonstrating non-deterministic map iteration order:")
+	fmt.Println()
 
 	m := map[string]int{
 		"alpha":   1,
@@ -51,7 +146,8 @@ func demoMapIterationOrder() {
 	}
 
 	fmt.Println("\nObserve that the key order is not guaranteed.")
-	fmt.Println("You must not rely on any specific ordering when ranging over a map.\n")
+	fmt.Println("You must not rely on any specific ordering when ranging over a map.")
+	fmt.Println()
 }
 
 // explainConcepts prints a textual explanation of what is going on and why
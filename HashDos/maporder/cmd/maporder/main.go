@@ -0,0 +1,26 @@
+// Command maporder reports code that depends on map iteration order.
+//
+// Run it on its own:
+//
+//	go run ./HashDos/maporder/cmd/maporder ./...
+//
+// or as a vet tool:
+//
+//	go build -o maporder ./HashDos/maporder/cmd/maporder
+//	go vet -vettool=$(pwd)/maporder ./...
+//
+// On this repository it reports demoMapIterationOrder in HashDos, which
+// prints keys in map order on purpose.
+//
+// The analyzer's tests run with go test ./HashDos/maporder.
+package main
+
+import (
+	"Lets-GO/HashDos/maporder"
+
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(maporder.Analyzer)
+}
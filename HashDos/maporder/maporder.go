@@ -0,0 +1,520 @@
+// Package maporder defines an analyzer that reports code whose result depends
+// on map iteration order.
+//
+// HashDos explains why Go randomizes the order of "for k := range m": so
+// nobody can rely on it. This pass finds the places where somebody does
+// anyway. It reports a range over a map when, inside the loop body,
+// iteration order flows into something order-sensitive:
+//
+//   - an append to a slice that the function later returns without sorting
+//     it first;
+//   - a write to an io.Writer (fmt.Fprint*, fmt.Print*, io.WriteString, or
+//     a Write/WriteString/WriteByte/WriteRune method);
+//   - string building with s += ... on a string declared outside the loop;
+//   - a break out of the loop, which makes "the first match" random.
+//
+// When the key type is ordered, the diagnostic carries a suggested fix that
+// ranges over slices.Sorted(maps.Keys(m)) instead.
+package maporder
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const doc = `report results that depend on map iteration order
+
+A range over a map is reported when its body appends to a slice that is
+returned unsorted, writes to an io.Writer, builds a string, or breaks on
+the first match. Map iteration order is random; sort the keys first.`
+
+// Analyzer reports ranges over maps whose order flows into an
+// order-sensitive sink.
+var Analyzer = &analysis.Analyzer{
+	Name:     "maporder",
+	Doc:      doc,
+	URL:      "https://go.dev/ref/spec#For_range",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	nodeFilter := []ast.Node{(*ast.RangeStmt)(nil)}
+	insp.WithStack(nodeFilter, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		rs := n.(*ast.RangeStmt)
+		if _, ok := pass.TypesInfo.TypeOf(rs.X).Underlying().(*types.Map); !ok {
+			return true
+		}
+
+		fn := enclosingFunc(stack)
+		if fn == nil {
+			return true
+		}
+		var label *ast.Ident
+		if len(stack) >= 2 {
+			if ls, ok := stack[len(stack)-2].(*ast.LabeledStmt); ok {
+				label = ls.Label
+			}
+		}
+
+		s := findSink(pass, rs, label, fn)
+		if s == nil {
+			return true
+		}
+
+		d := analysis.Diagnostic{
+			Pos:     rs.For,
+			End:     rs.X.End(),
+			Message: fmt.Sprintf("iteration order of map %s %s; map order is random", render(rs.X), s.what),
+			Related: []analysis.RelatedInformation{{Pos: s.pos, Message: "order-sensitive use here"}},
+		}
+		if fix, ok := sortedKeysFix(pass, rs, stack); ok {
+			d.SuggestedFixes = []analysis.SuggestedFix{fix}
+		}
+		pass.Report(d)
+		return true
+	})
+	return nil, nil
+}
+
+// sink is the first order-sensitive use found in a loop body.
+type sink struct {
+	pos  token.Pos
+	what string // completes "iteration order of map m ..."
+}
+
+// findSink walks the body of rs (not descending into function literals) and
+// returns the first order-sensitive use, or nil.
+func findSink(pass *analysis.Pass, rs *ast.RangeStmt, label *ast.Ident, fn *funcNode) *sink {
+	var found *sink
+	// depth counts the breakable statements between rs and the current node:
+	// an unlabeled break only leaves rs when depth is 0.
+	var walk func(n ast.Node, depth int)
+	walk = func(n ast.Node, depth int) {
+		ast.Inspect(n, func(n ast.Node) bool {
+			if found != nil {
+				return false
+			}
+			switch n := n.(type) {
+			case *ast.FuncLit:
+				return false
+
+			case *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+				head, body := splitBreakable(n)
+				for _, child := range head {
+					walk(child, depth)
+				}
+				walk(body, depth+1)
+				return false
+
+			case *ast.BranchStmt:
+				if n.Tok != token.BREAK {
+					return true
+				}
+				if (n.Label == nil && depth == 0) ||
+					(n.Label != nil && label != nil && pass.TypesInfo.Uses[n.Label] == pass.TypesInfo.Defs[label]) {
+					found = &sink{n.Pos(), "decides which element a break stops at"}
+				}
+
+			case *ast.AssignStmt:
+				if s := assignSink(pass, n, rs, fn); s != nil {
+					found = s
+				}
+
+			case *ast.CallExpr:
+				if what := writerCall(pass, n); what != "" {
+					found = &sink{n.Pos(), "determines the order of " + what}
+				}
+			}
+			return true
+		})
+	}
+	walk(rs.Body, 0)
+	return found
+}
+
+// splitBreakable splits a breakable statement into the parts evaluated
+// outside of it (init, condition, range expression...) and its body, where
+// an unlabeled break refers to the statement itself.
+func splitBreakable(n ast.Node) (head []ast.Node, body *ast.BlockStmt) {
+	add := func(parts ...ast.Node) {
+		for _, p := range parts {
+			if p != nil && !reflect.ValueOf(p).IsNil() {
+				head = append(head, p)
+			}
+		}
+	}
+	switch n := n.(type) {
+	case *ast.ForStmt:
+		add(n.Init, n.Cond, n.Post)
+		return head, n.Body
+	case *ast.RangeStmt:
+		add(n.X)
+		return head, n.Body
+	case *ast.SwitchStmt:
+		add(n.Init, n.Tag)
+		return head, n.Body
+	case *ast.TypeSwitchStmt:
+		add(n.Init, n.Assign)
+		return head, n.Body
+	case *ast.SelectStmt:
+		return nil, n.Body
+	}
+	return nil, nil
+}
+
+// assignSink reports "s = append(s, ...)" where s is later returned unsorted,
+// and "str += ..." on a string declared outside the loop.
+func assignSink(pass *analysis.Pass, as *ast.AssignStmt, rs *ast.RangeStmt, fn *funcNode) *sink {
+	if len(as.Lhs) != 1 || len(as.Rhs) != 1 {
+		return nil
+	}
+	id, ok := ast.Unparen(as.Lhs[0]).(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	obj, ok := pass.TypesInfo.ObjectOf(id).(*types.Var)
+	if !ok || declaredIn(obj, rs) {
+		return nil
+	}
+
+	switch as.Tok {
+	case token.ADD_ASSIGN:
+		if isString(obj.Type()) {
+			return &sink{as.Pos(), "determines the contents of string " + id.Name}
+		}
+
+	case token.ASSIGN:
+		call, ok := ast.Unparen(as.Rhs[0]).(*ast.CallExpr)
+		if !ok {
+			return nil
+		}
+		if isBuiltin(pass, call.Fun, "append") {
+			if returnedUnsorted(pass, obj, rs, fn) {
+				return &sink{as.Pos(), "determines the order of slice " + id.Name + ", which is returned unsorted"}
+			}
+			return nil
+		}
+		if bin, ok := ast.Unparen(as.Rhs[0]).(*ast.BinaryExpr); ok && bin.Op == token.ADD && isString(obj.Type()) {
+			if x, ok := ast.Unparen(bin.X).(*ast.Ident); ok && pass.TypesInfo.ObjectOf(x) == obj {
+				return &sink{as.Pos(), "determines the contents of string " + id.Name}
+			}
+		}
+	}
+	return nil
+}
+
+// writerCall reports whether call writes to an io.Writer, and describes it.
+func writerCall(pass *analysis.Pass, call *ast.CallExpr) string {
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	fn, ok := pass.TypesInfo.Uses[sel.Sel].(*types.Func)
+	if !ok {
+		return ""
+	}
+
+	if fn.Pkg() != nil && fn.Signature().Recv() == nil {
+		switch fn.Pkg().Path() + "." + fn.Name() {
+		case "fmt.Print", "fmt.Printf", "fmt.Println":
+			return "output written with " + fn.Name()
+		case "fmt.Fprint", "fmt.Fprintf", "fmt.Fprintln", "io.WriteString":
+			return "output written to " + render(call.Args[0])
+		}
+		return ""
+	}
+
+	switch fn.Name() {
+	case "Write", "WriteString", "WriteByte", "WriteRune":
+	default:
+		return ""
+	}
+	recv := pass.TypesInfo.TypeOf(sel.X)
+	if recv == nil || !implementsWriter(recv) {
+		return ""
+	}
+	if named, ok := types.Unalias(derefType(recv)).(*types.Named); ok && named.Obj().Pkg() != nil {
+		switch named.Obj().Pkg().Path() + "." + named.Obj().Name() {
+		case "strings.Builder", "bytes.Buffer":
+			return "string built in " + render(sel.X)
+		}
+	}
+	return "output written to " + render(sel.X)
+}
+
+// returnedUnsorted reports whether obj is returned by a return statement
+// after rs, and is not passed to a sort function between rs and that return.
+func returnedUnsorted(pass *analysis.Pass, obj *types.Var, rs *ast.RangeStmt, fn *funcNode) bool {
+	returned, sorted := false, false
+	ast.Inspect(fn.body, func(n ast.Node) bool {
+		if n == nil || n.Pos() < rs.End() {
+			return n != nil && n.End() > rs.End()
+		}
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			if isSortCall(pass, n) && len(n.Args) > 0 && refersTo(pass, n.Args[0], obj) {
+				sorted = true
+			}
+		case *ast.ReturnStmt:
+			if sorted {
+				return false
+			}
+			if len(n.Results) == 0 {
+				returned = returned || isNamedResult(pass, obj, fn)
+			}
+			for _, r := range n.Results {
+				if refersTo(pass, r, obj) {
+					returned = true
+				}
+			}
+		}
+		return true
+	})
+	return returned && !sorted
+}
+
+// isSortCall reports whether call is sort.X(...) or slices.Sort*(...).
+func isSortCall(pass *analysis.Pass, call *ast.CallExpr) bool {
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	fn, ok := pass.TypesInfo.Uses[sel.Sel].(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return false
+	}
+	switch fn.Pkg().Path() {
+	case "sort":
+		return true
+	case "slices":
+		return strings.HasPrefix(fn.Name(), "Sort")
+	}
+	return false
+}
+
+// refersTo reports whether e is obj, possibly wrapped in parentheses or a
+// type conversion such as sort.StringSlice(s).
+func refersTo(pass *analysis.Pass, e ast.Expr, obj types.Object) bool {
+	e = ast.Unparen(e)
+	if call, ok := e.(*ast.CallExpr); ok && len(call.Args) == 1 {
+		if tv, ok := pass.TypesInfo.Types[call.Fun]; ok && tv.IsType() {
+			return refersTo(pass, call.Args[0], obj)
+		}
+	}
+	id, ok := e.(*ast.Ident)
+	return ok && pass.TypesInfo.ObjectOf(id) == obj
+}
+
+// isNamedResult reports whether obj is one of fn's named results.
+func isNamedResult(pass *analysis.Pass, obj *types.Var, fn *funcNode) bool {
+	if fn.typ.Results == nil {
+		return false
+	}
+	for _, field := range fn.typ.Results.List {
+		for _, name := range field.Names {
+			if pass.TypesInfo.Defs[name] == obj {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// declaredIn reports whether obj is declared inside rs (including its
+// key and value variables).
+func declaredIn(obj types.Object, rs *ast.RangeStmt) bool {
+	return obj.Pos() >= rs.Pos() && obj.Pos() < rs.End()
+}
+
+// funcNode is the signature and body of a FuncDecl or FuncLit.
+type funcNode struct {
+	typ  *ast.FuncType
+	body *ast.BlockStmt
+}
+
+// enclosingFunc returns the innermost function in stack, or nil.
+func enclosingFunc(stack []ast.Node) *funcNode {
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch f := stack[i].(type) {
+		case *ast.FuncDecl:
+			if f.Body != nil {
+				return &funcNode{f.Type, f.Body}
+			}
+		case *ast.FuncLit:
+			return &funcNode{f.Type, f.Body}
+		}
+	}
+	return nil
+}
+
+func isBuiltin(pass *analysis.Pass, fun ast.Expr, name string) bool {
+	id, ok := ast.Unparen(fun).(*ast.Ident)
+	if !ok {
+		return false
+	}
+	b, ok := pass.TypesInfo.Uses[id].(*types.Builtin)
+	return ok && b.Name() == name
+}
+
+func isString(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&types.IsString != 0
+}
+
+var writerIface = types.NewInterfaceType([]*types.Func{
+	types.NewFunc(token.NoPos, nil, "Write", types.NewSignatureType(nil, nil, nil,
+		types.NewTuple(types.NewParam(token.NoPos, nil, "p", types.NewSlice(types.Typ[types.Byte]))),
+		types.NewTuple(
+			types.NewParam(token.NoPos, nil, "n", types.Typ[types.Int]),
+			types.NewParam(token.NoPos, nil, "err", types.Universe.Lookup("error").Type())),
+		false)),
+}, nil).Complete()
+
+// implementsWriter reports whether t or *t implements io.Writer.
+func implementsWriter(t types.Type) bool {
+	if types.Implements(t, writerIface) {
+		return true
+	}
+	if _, ok := t.Underlying().(*types.Pointer); !ok {
+		return types.Implements(types.NewPointer(t), writerIface)
+	}
+	return false
+}
+
+func derefType(t types.Type) types.Type {
+	if p, ok := t.Underlying().(*types.Pointer); ok {
+		return p.Elem()
+	}
+	return t
+}
+
+// render returns the source text of e.
+func render(e ast.Expr) string {
+	return types.ExprString(e)
+}
+
+// ----------------------------------------
+// Suggested fix: range over sorted keys
+// ----------------------------------------
+
+// sortedKeysFix rewrites
+//
+//	for k, v := range m {
+//
+// as
+//
+//	for _, k := range slices.Sorted(maps.Keys(m)) {
+//		v := m[k]
+//
+// adding the "maps" and "slices" imports if needed. It is only offered
+// when the key type is ordered and the loop declares its key variable.
+func sortedKeysFix(pass *analysis.Pass, rs *ast.RangeStmt, stack []ast.Node) (analysis.SuggestedFix, bool) {
+	key, ok := rs.Key.(*ast.Ident)
+	if !ok || key.Name == "_" || rs.Tok != token.DEFINE {
+		return analysis.SuggestedFix{}, false
+	}
+	mt := pass.TypesInfo.TypeOf(rs.X).Underlying().(*types.Map)
+	if b, ok := mt.Key().Underlying().(*types.Basic); !ok || b.Info()&types.IsOrdered == 0 {
+		return analysis.SuggestedFix{}, false
+	}
+
+	var value *ast.Ident
+	if rs.Value != nil {
+		value, _ = rs.Value.(*ast.Ident)
+		if value != nil && value.Name == "_" {
+			value = nil
+		}
+		// m is evaluated once per iteration by the fix; only allow
+		// expressions without side effects.
+		if value != nil && !isSimple(rs.X) {
+			return analysis.SuggestedFix{}, false
+		}
+	}
+
+	file, ok := stack[0].(*ast.File)
+	if !ok {
+		return analysis.SuggestedFix{}, false
+	}
+	mapsName, mapsEdits := importName(file, "maps")
+	slicesName, slicesEdits := importName(file, "slices")
+
+	m := render(rs.X)
+	edits := []analysis.TextEdit{{
+		Pos:     rs.Key.Pos(),
+		End:     rs.X.End(),
+		NewText: fmt.Appendf(nil, "_, %s := range %s.Sorted(%s.Keys(%s))", key.Name, slicesName, mapsName, m),
+	}}
+	if value != nil {
+		// Insert before the first statement rather than after the brace, so
+		// a comment on the "for" line stays there.
+		at, text := rs.Body.Rbrace, "%s := %s[%s]\n"
+		if len(rs.Body.List) > 0 {
+			at, text = rs.Body.List[0].Pos(), "%s := %s[%s]\n\t"
+		}
+		edits = append(edits, analysis.TextEdit{
+			Pos:     at,
+			End:     at,
+			NewText: fmt.Appendf(nil, text, value.Name, m, key.Name),
+		})
+	}
+	edits = append(edits, mapsEdits...)
+	edits = append(edits, slicesEdits...)
+
+	return analysis.SuggestedFix{
+		Message:   "Range over sorted keys",
+		TextEdits: edits,
+	}, true
+}
+
+// isSimple reports whether e is an identifier or a chain of field
+// selections, which can be evaluated repeatedly without side effects.
+func isSimple(e ast.Expr) bool {
+	switch e := ast.Unparen(e).(type) {
+	case *ast.Ident:
+		return true
+	case *ast.SelectorExpr:
+		return isSimple(e.X)
+	}
+	return false
+}
+
+// importName returns the name under which file imports path, and the edits
+// that add the import when it is missing.
+func importName(file *ast.File, path string) (string, []analysis.TextEdit) {
+	for _, imp := range file.Imports {
+		if strings.Trim(imp.Path.Value, `"`) == path {
+			if imp.Name != nil {
+				return imp.Name.Name, nil
+			}
+			return path, nil
+		}
+	}
+
+	for _, decl := range file.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.IMPORT {
+			continue
+		}
+		if gd.Lparen.IsValid() {
+			return path, []analysis.TextEdit{{Pos: gd.Lparen + 1, End: gd.Lparen + 1, NewText: fmt.Appendf(nil, "\n\t%q", path)}}
+		}
+		return path, []analysis.TextEdit{{Pos: gd.Pos(), End: gd.Pos(), NewText: fmt.Appendf(nil, "import %q\n", path)}}
+	}
+	return path, []analysis.TextEdit{{Pos: file.Name.End(), End: file.Name.End(), NewText: fmt.Appendf(nil, "\n\nimport %q", path)}}
+}
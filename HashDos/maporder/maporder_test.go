@@ -0,0 +1,13 @@
+package maporder_test
+
+import (
+	"testing"
+
+	"Lets-GO/HashDos/maporder"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), maporder.Analyzer, "maporder")
+}
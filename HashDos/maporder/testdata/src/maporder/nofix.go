@@ -0,0 +1,33 @@
+package maporder
+
+import "fmt"
+
+type point struct{ x, y int }
+
+// Struct keys are not ordered: reported, but no fix is offered.
+func points(m map[point]string) {
+	for p, name := range m { // want `iteration order of map m determines the order of output written with Println`
+		fmt.Println(p, name)
+	}
+}
+
+// Without a key variable there is nothing to sort by.
+func values(m map[string]int) {
+	for _, v := range m { // want `iteration order of map m determines the order of output written with Println`
+		fmt.Println(v)
+	}
+}
+
+// A function call as the map would be evaluated once per iteration.
+func fromCall(get func() map[string]int) {
+	for k, v := range get() { // want `iteration order of map get\(\) determines the order of output written with Println`
+		fmt.Println(k, v)
+	}
+}
+
+// A function literal has its own body: its writes are not the loop's.
+func background(m map[string]int) {
+	for k := range m {
+		go func() { fmt.Println(k) }()
+	}
+}
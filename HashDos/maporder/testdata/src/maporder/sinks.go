@@ -0,0 +1,153 @@
+package maporder
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Appends to a slice that is returned unsorted.
+func keysUnsorted(m map[string]int) []string {
+	var keys []string
+	for k := range m { // want `iteration order of map m determines the order of slice keys, which is returned unsorted`
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Sorting before returning is fine.
+func keysSorted(m map[string]int) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// A slice that never leaves the function is fine.
+func sumValues(m map[string]int) int {
+	var vs []int
+	for _, v := range m {
+		vs = append(vs, v)
+	}
+	total := 0
+	for _, v := range vs {
+		total += v
+	}
+	return total
+}
+
+// Named result with a bare return.
+func valuesNamed(m map[int]string) (out []string) {
+	for k, v := range m { // want `iteration order of map m determines the order of slice out, which is returned unsorted`
+		out = append(out, fmt.Sprint(k, v))
+	}
+	return
+}
+
+// Writes to an io.Writer.
+func dump(w io.Writer, m map[string]int) {
+	for k, v := range m { // want `iteration order of map m determines the order of output written to w`
+		fmt.Fprintf(w, "%s=%d\n", k, v)
+	}
+}
+
+func printAll(m map[string]int) {
+	for k, v := range m { // want `iteration order of map m determines the order of output written with Println`
+		fmt.Println(k, v)
+	}
+}
+
+func writeFile(f *os.File, m map[string]string) {
+	for _, v := range m { // want `iteration order of map m determines the order of output written to f`
+		f.WriteString(v)
+	}
+}
+
+// String building.
+func joinKeys(m map[string]bool) string {
+	s := ""
+	for k := range m { // want `iteration order of map m determines the contents of string s`
+		s += k + ","
+	}
+	return s
+}
+
+func build(m map[string]bool) string {
+	var sb strings.Builder
+	for k := range m { // want `iteration order of map m determines the order of string built in sb`
+		sb.WriteString(k)
+	}
+	return sb.String()
+}
+
+// Break on first match.
+func firstAdult(ages map[string]int) string {
+	found := ""
+	for name, age := range ages { // want `iteration order of map ages decides which element a break stops at`
+		if age >= 18 {
+			found = name
+			break
+		}
+	}
+	return found
+}
+
+// A break that only leaves an inner loop or switch is fine.
+func innerBreak(m map[string][]int) int {
+	n := 0
+	for _, vs := range m {
+		for _, v := range vs {
+			if v < 0 {
+				break
+			}
+			n += v
+		}
+		switch {
+		case n > 100:
+			break
+		}
+	}
+	return n
+}
+
+// A labeled break out of the map loop is not.
+func labeled(m map[string][]int) bool {
+	neg := false
+outer:
+	for _, vs := range m { // want `iteration order of map m decides which element a break stops at`
+		for _, v := range vs {
+			if v < 0 {
+				neg = true
+				break outer
+			}
+		}
+	}
+	return neg
+}
+
+// Order-insensitive loops are fine.
+func count(m map[string]int) (n int) {
+	for _, v := range m {
+		n += v
+	}
+	return n
+}
+
+func invert(m map[string]int) map[int]string {
+	out := make(map[int]string, len(m))
+	for k, v := range m {
+		out[v] = k
+	}
+	return out
+}
+
+// Ranging over a slice is not this analyzer's business.
+func printSlice(xs []string) {
+	for _, x := range xs {
+		fmt.Println(x)
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"math/rand/v2"
+	"strings"
+)
+
+// ----------------------------------------
+// A naive hash table (what NOT to build)
+// ----------------------------------------
+//
+// naiveTable is a deliberately simple chained hash table. It hashes keys with
+// the same unseeded polynomial hash as Java's String.hashCode:
+//
+//	h = 31*h + byte
+//
+// Because there is no random seed, the bucket of every key is known before
+// the program even starts. An attacker can precompute thousands of keys with
+// the same hash, and every Put then has to walk one ever-growing chain:
+// inserting n such keys costs O(n²) key comparisons instead of O(n).
+//
+// Go's built-in map avoids exactly this by mixing a per-map random seed into
+// the hash (see explainConcepts).
+type naiveTable struct {
+	buckets [][]naiveEntry
+	n       int
+
+	// probes counts key comparisons performed by Put and Get. It is the
+	// "work" an attacker is trying to maximize.
+	probes int
+}
+
+type naiveEntry struct {
+	key   string
+	value string
+}
+
+func newNaiveTable() *naiveTable {
+	return &naiveTable{buckets: make([][]naiveEntry, 8)}
+}
+
+// naiveHash is the unseeded hash used by naiveTable.
+func naiveHash(s string) uint32 {
+	var h uint32
+	for i := 0; i < len(s); i++ {
+		h = 31*h + uint32(s[i])
+	}
+	return h
+}
+
+// Put inserts or replaces key.
+func (t *naiveTable) Put(key, value string) {
+	b := naiveHash(key) % uint32(len(t.buckets))
+	chain := t.buckets[b]
+	for i := range chain {
+		t.probes++
+		if chain[i].key == key {
+			chain[i].value = value
+			return
+		}
+	}
+	t.buckets[b] = append(chain, naiveEntry{key: key, value: value})
+	t.n++
+
+	// Keep the average chain short for "normal" keys. This does nothing
+	// against colliding keys: they share the full 32-bit hash, so they land
+	// in the same bucket whatever the table size.
+	if t.n > 2*len(t.buckets) {
+		t.grow()
+	}
+}
+
+// Get returns the value stored for key.
+func (t *naiveTable) Get(key string) (string, bool) {
+	b := naiveHash(key) % uint32(len(t.buckets))
+	for _, e := range t.buckets[b] {
+		t.probes++
+		if e.key == key {
+			return e.value, true
+		}
+	}
+	return "", false
+}
+
+// Len returns the number of keys stored.
+func (t *naiveTable) Len() int { return t.n }
+
+// Probes returns the number of key comparisons performed so far.
+func (t *naiveTable) Probes() int { return t.probes }
+
+// LongestChain returns the length of the longest bucket chain.
+func (t *naiveTable) LongestChain() int {
+	longest := 0
+	for _, chain := range t.buckets {
+		longest = max(longest, len(chain))
+	}
+	return longest
+}
+
+func (t *naiveTable) grow() {
+	old := t.buckets
+	t.buckets = make([][]naiveEntry, 2*len(old))
+	for _, chain := range old {
+		for _, e := range chain {
+			b := naiveHash(e.key) % uint32(len(t.buckets))
+			t.buckets[b] = append(t.buckets[b], e)
+		}
+	}
+}
+
+// ----------------------------------------
+// The built-in map behind the same interface
+// ----------------------------------------
+
+// table is the small interface the HTTP server stores request parameters in,
+// so the naive table and Go's map can be compared on identical input.
+type table interface {
+	Put(key, value string)
+	Len() int
+	// Probes reports key comparisons, or -1 when the table cannot tell.
+	Probes() int
+}
+
+// builtinTable is Go's own map, which uses a seeded hash.
+type builtinTable map[string]string
+
+func (t builtinTable) Put(key, value string) { t[key] = value }
+func (t builtinTable) Len() int              { return len(t) }
+func (t builtinTable) Probes() int           { return -1 }
+
+// newTable returns an empty table of the given kind ("naive" or "builtin").
+func newTable(kind string) table {
+	if kind == "naive" {
+		return newNaiveTable()
+	}
+	return builtinTable{}
+}
+
+// ----------------------------------------
+// Key sets
+// ----------------------------------------
+
+// collidingKeys returns n distinct keys that all have the same naiveHash.
+//
+// It relies on "Aa" and "BB" having the same hash (31*'A'+'a' == 31*'B'+'B'),
+// so any string built from k such blocks collides with every other one:
+// that gives 2^k keys of length 2k.
+func collidingKeys(n int) []string {
+	blocks := [2]string{"Aa", "BB"}
+
+	k := 1
+	for 1<<k < n {
+		k++
+	}
+
+	keys := make([]string, 0, n)
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		sb.Reset()
+		for bit := k - 1; bit >= 0; bit-- {
+			sb.WriteString(blocks[(i>>bit)&1])
+		}
+		keys = append(keys, sb.String())
+	}
+	return keys
+}
+
+// randomKeys returns n random keys with the same length as collidingKeys(n),
+// so both key sets produce request bodies of the same size.
+func randomKeys(n int) []string {
+	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+	length := 2
+	for 1<<(length/2) < n {
+		length += 2
+	}
+
+	keys := make([]string, 0, n)
+	b := make([]byte, length)
+	for i := 0; i < n; i++ {
+		for j := range b {
+			b[j] = letters[rand.IntN(len(letters))]
+		}
+		keys = append(keys, string(b))
+	}
+	return keys
+}
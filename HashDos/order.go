@@ -0,0 +1,305 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+
+	"Lets-GO/internal/stats"
+)
+
+// ----------------------------------------
+// How random is map iteration order, really?
+// ----------------------------------------
+//
+// demoMapIterationOrder prints five loops and leaves the judgement to the
+// reader. analyzeIterationOrder measures it instead: it ranges over the same
+// map thousands of times and runs chi-square tests against what a truly
+// random order would produce.
+//
+// Two chi-square tests are run, each over one multinomial whose cells are
+// mutually exclusive outcomes of a loop, so the p-values mean what they say:
+//
+//   - first key:  which key comes out first. Uniform means every key is
+//                 equally likely to lead.
+//   - order:      which of the n! full orders came out, for maps small
+//                 enough that every order is expected at least 5 times.
+//
+// Two more numbers describe the order without a p-value:
+//
+//   - precedence: for each pair of tracked keys, how far the share of
+//                 loops with a before b is from 1/2, averaged over pairs.
+//                 The pairs overlap, so no test is run on them.
+//   - successors: how many different keys were ever seen right after each
+//                 tracked key. A random permutation produces many; a rotated
+//                 but otherwise fixed order produces one.
+//
+// The language only promises that you cannot rely on the order. What the
+// runtime actually does shows in the numbers, and the verdict column is
+// derived from them.
+//
+// Run it with: go run ./HashDos -mode=order [-iterations N -sizes 5,8,1024 -keytypes int,string]
+
+// orderConfig controls analyzeIterationOrder.
+type orderConfig struct {
+	iterations int
+	sizes      []int
+	keyTypes   []string // "int" and/or "string"
+}
+
+// maxTracked bounds the keys followed by the precedence and successor
+// statistics, so large maps stay O(iterations × n).
+const maxTracked = 32
+
+// maxOrderN is the largest map whose full orders are counted.
+const maxOrderN = 8
+
+// orderStats holds the raw counts for one map.
+type orderStats struct {
+	n          int
+	iterations int
+	tracked    int
+
+	first      []int   // first[k]: times key k came first
+	before     [][]int // before[a][b]: times tracked a preceded tracked b (a < b)
+	successors []map[int]bool
+	orders     map[string]int // times each full order came out, only for n <= maxOrderN
+}
+
+// analyzeIterationOrder runs the analysis for every size and key type in cfg
+// and prints a report.
+func analyzeIterationOrder(cfg orderConfig) {
+	fmt.Printf("Map iteration order analysis on %s (%s/%s), %d iterations per map\n\n",
+		runtime.Version(), runtime.GOOS, runtime.GOARCH, cfg.iterations)
+	fmt.Println("p-values are from chi-square tests against a uniformly random order;")
+	fmt.Println("p < 0.001 means \"this is not what a random permutation looks like\".")
+	fmt.Println()
+
+	fmt.Printf("%-7s %6s %10s %10s %10s %12s %12s  %s\n",
+		"keys", "n", "first p", "order p", "prec bias", "successors", "orders", "verdict")
+	for _, kt := range cfg.keyTypes {
+		for _, n := range cfg.sizes {
+			var st *orderStats
+			switch kt {
+			case "int":
+				st = collectOrderStats(intKeys(n), cfg.iterations)
+			case "string":
+				st = collectOrderStats(stringKeys(n), cfg.iterations)
+			default:
+				fmt.Printf("%-7s unknown key type, skipped\n", kt)
+				continue
+			}
+			st.printRow(kt)
+		}
+	}
+
+	fmt.Print(`
+Columns:
+  first p     uniformity of the first key over all n keys
+  order p     uniformity over all n! full orders; only when every order is
+              expected at least 5 times (iterations >= 5*n!)
+  prec bias   mean |share of loops with a before b - 1/2| over pairs of up
+              to 32 tracked keys: 0 plus noise of about 0.4/sqrt(iterations)
+              for a shuffle, 0.5 for a fixed order. Descriptive, no test:
+              the pairs overlap and are far from independent.
+  successors  average number of distinct keys seen right after a tracked
+              key (a random order gives min(n-1, iterations) at most)
+  orders      distinct full orders seen, out of n! possible (n <= 8 only)
+
+Verdicts come from the numbers above: "first key biased" and "orders not
+uniform" from the tests, "fixed successors" when keys are followed by at
+most two different keys, "pairs biased" when prec bias is over five times
+the noise level.
+
+Background, from the runtime's source rather than measured here (Swiss
+tables, Go 1.24 and later):
+  - Maps with at most 8 entries live in a single group of 8 slots.
+    Iteration picks a random starting slot and walks the group cyclically,
+    so there are at most 8 different orders, each a rotation of the same
+    cycle: successors never change and precedence is far from 50/50. When
+    the group is full every key is equally likely to come first; when it
+    has empty slots, keys right after a run of empty slots lead more often.
+  - Larger maps spread over many groups, and eventually several tables.
+    Iteration randomizes the starting table and the slot offset inside each
+    group, but still visits groups in a fixed cyclic sequence. The order is
+    again close to a rotation of one cycle (one or two distinct successors
+    per key), and the first key again favors keys that follow empty slots.
+  - Either way: the order is unpredictable enough that you cannot rely on
+    it, yet far from a shuffle. Never use map order as a source of
+    randomness either.
+`)
+}
+
+// collectOrderStats ranges over a map built from keys the given number of
+// times and counts what it sees.
+func collectOrderStats[K comparable](keys []K, iterations int) *orderStats {
+	n := len(keys)
+	m := make(map[K]int, n)
+	for i, k := range keys {
+		m[k] = i
+	}
+
+	tracked := min(n, maxTracked)
+	st := &orderStats{
+		n:          n,
+		iterations: iterations,
+		tracked:    tracked,
+		first:      make([]int, n),
+		before:     make([][]int, tracked),
+		successors: make([]map[int]bool, tracked),
+	}
+	for k := range tracked {
+		st.before[k] = make([]int, tracked)
+		st.successors[k] = map[int]bool{}
+	}
+	if n <= maxOrderN {
+		st.orders = map[string]int{}
+	}
+
+	posOf := make([]int, tracked)
+	order := make([]int, 0, n)
+	for range iterations {
+		order = order[:0]
+		for _, idx := range m {
+			order = append(order, idx)
+		}
+
+		st.first[order[0]]++
+		for pos, idx := range order {
+			if idx < tracked {
+				posOf[idx] = pos
+				if pos+1 < n {
+					st.successors[idx][order[pos+1]] = true
+				}
+			}
+		}
+		for a := 0; a < tracked; a++ {
+			for b := a + 1; b < tracked; b++ {
+				if posOf[a] < posOf[b] {
+					st.before[a][b]++
+				}
+			}
+		}
+		if st.orders != nil {
+			st.orders[fmt.Sprint(order)]++
+		}
+	}
+	return st
+}
+
+// printRow prints one line of the report for st.
+func (st *orderStats) printRow(keyType string) {
+	iters := float64(st.iterations)
+
+	// First key: n cells, each expected iterations/n.
+	var firstChi float64
+	for _, c := range st.first {
+		firstChi += stats.ChiTerm(float64(c), iters/float64(st.n))
+	}
+	firstP := stats.ChiSquareP(firstChi, st.n-1)
+
+	// Full orders: n! cells, each expected iterations/n!. Orders never
+	// seen contribute their expected count each.
+	orderP := math.NaN()
+	if st.orders != nil {
+		cells := factorial(st.n)
+		expected := iters / float64(cells)
+		if expected >= 5 {
+			var chi float64
+			for _, c := range st.orders {
+				chi += stats.ChiTerm(float64(c), expected)
+			}
+			chi += float64(cells-len(st.orders)) * expected
+			orderP = stats.ChiSquareP(chi, cells-1)
+		}
+	}
+
+	// Precedence: how far each pair is from a fair coin, on average.
+	var precBias float64
+	pairs := 0
+	for a := 0; a < st.tracked; a++ {
+		for b := a + 1; b < st.tracked; b++ {
+			precBias += math.Abs(float64(st.before[a][b])/iters - 0.5)
+			pairs++
+		}
+	}
+	precBias /= float64(pairs)
+	precNoise := 0.5 * math.Sqrt(2/(math.Pi*iters)) // E|share-1/2| for a fair coin
+
+	var succ float64
+	for _, s := range st.successors {
+		succ += float64(len(s))
+	}
+	succ /= float64(st.tracked)
+
+	orders := "-"
+	if st.orders != nil {
+		orders = fmt.Sprintf("%d/%d", len(st.orders), factorial(st.n))
+	}
+
+	var problems []string
+	if firstP < 0.001 {
+		problems = append(problems, "first key biased")
+	}
+	if orderP < 0.001 {
+		problems = append(problems, "orders not uniform")
+	}
+	if succ <= 2 {
+		problems = append(problems, "fixed successors")
+	}
+	if precBias > 5*precNoise {
+		problems = append(problems, "pairs biased")
+	}
+	verdict := "consistent with random"
+	if len(problems) > 0 {
+		verdict = strings.Join(problems, ", ")
+	}
+
+	orderCol := "-"
+	if !math.IsNaN(orderP) {
+		orderCol = stats.FormatP(orderP)
+	}
+	fmt.Printf("%-7s %6d %10s %10s %10.3f %12.1f %12s  %s\n",
+		keyType, st.n, stats.FormatP(firstP), orderCol, precBias, succ, orders, verdict)
+}
+
+func factorial(n int) int {
+	f := 1
+	for i := 2; i <= n; i++ {
+		f *= i
+	}
+	return f
+}
+
+func intKeys(n int) []int {
+	keys := make([]int, n)
+	for i := range keys {
+		keys[i] = i
+	}
+	return keys
+}
+
+func stringKeys(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = "key-" + strconv.Itoa(i)
+	}
+	return keys
+}
+
+// parseSizes parses a comma-separated list of map sizes such as "5,8,64".
+func parseSizes(s string) ([]int, error) {
+	var sizes []int
+	for f := range strings.SplitSeq(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil || n < 2 {
+			return nil, fmt.Errorf("invalid map size %q (need an integer >= 2)", f)
+		}
+		sizes = append(sizes, n)
+	}
+	slices.Sort(sizes)
+	return sizes, nil
+}
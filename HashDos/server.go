@@ -0,0 +1,332 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"Lets-GO/HashDos/detmap"
+)
+
+// ----------------------------------------
+// A tiny "parse parameters into a map" server
+// ----------------------------------------
+//
+// Almost every web framework turns query strings, form bodies and JSON
+// objects into hash tables before your handler runs. That is what makes
+// Hash DoS practical: the attacker controls the keys, and the table is
+// filled before any application logic gets a chance to say no.
+//
+// The server below does the same. POST /naive stores every parameter in
+// naiveTable, POST /builtin stores them in a Go map. GET /metrics reports
+// latency and rejections per table.
+
+// defenses are the limits a server applies to every request. A zero value
+// disables the corresponding limit.
+type defenses struct {
+	MaxKeys      int           // parameters per request
+	MaxBodyBytes int64         // request body size
+	CPUBudget    time.Duration // CPU time spent filling the table
+}
+
+func (d defenses) String() string {
+	if d == (defenses{}) {
+		return "none"
+	}
+	return fmt.Sprintf("max %d keys, max %d body bytes, %v CPU", d.MaxKeys, d.MaxBodyBytes, d.CPUBudget)
+}
+
+var (
+	errTooManyKeys = errors.New("too many keys")
+	errCPUBudget   = errors.New("CPU budget exceeded")
+	errNotObject   = errors.New("JSON body must be an object")
+)
+
+// storeResult is the JSON response of the /naive and /builtin endpoints.
+type storeResult struct {
+	Table  string `json:"table"`
+	Keys   int    `json:"keys"`
+	Probes int    `json:"probes"`
+	CPUNs  int64  `json:"cpu_ns"`
+	WallNs int64  `json:"wall_ns"`
+	Error  string `json:"error,omitempty"`
+}
+
+type hashServer struct {
+	def     defenses
+	metrics *serverMetrics
+}
+
+func newHashServer(def defenses) *hashServer {
+	return &hashServer{def: def, metrics: newServerMetrics()}
+}
+
+func (s *hashServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/naive", func(w http.ResponseWriter, r *http.Request) { s.store(w, r, "naive") })
+	mux.HandleFunc("/builtin", func(w http.ResponseWriter, r *http.Request) { s.store(w, r, "builtin") })
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) { s.metrics.writeTo(w) })
+	return mux
+}
+
+// store parses every parameter of r into a fresh table of the given kind.
+func (s *hashServer) store(w http.ResponseWriter, r *http.Request, kind string) {
+	// Pin the goroutine to its OS thread so the thread CPU clock only
+	// measures this request.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	start := time.Now()
+	in := &ingester{t: newTable(kind), def: s.def, cpuStart: threadCPUTime()}
+
+	if s.def.MaxBodyBytes > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, s.def.MaxBodyBytes)
+	}
+
+	err := in.addPairs(r.URL.RawQuery)
+	if err == nil && r.Method == http.MethodPost {
+		err = in.addBody(r)
+	}
+
+	res := storeResult{
+		Table:  kind,
+		Keys:   in.t.Len(),
+		Probes: in.t.Probes(),
+		CPUNs:  int64(threadCPUTime() - in.cpuStart),
+		WallNs: int64(time.Since(start)),
+	}
+
+	status := http.StatusOK
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case err == nil:
+	case errors.Is(err, errTooManyKeys), errors.As(err, &maxBytesErr):
+		status = http.StatusRequestEntityTooLarge
+	case errors.Is(err, errCPUBudget):
+		status = http.StatusServiceUnavailable
+	default:
+		status = http.StatusBadRequest
+	}
+	if err != nil {
+		res.Error = err.Error()
+	}
+	s.metrics.record(kind, status, time.Duration(res.WallNs), time.Duration(res.CPUNs))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(res)
+}
+
+// ingester fills a table while enforcing the server's defenses.
+type ingester struct {
+	t        table
+	def      defenses
+	cpuStart time.Duration
+	params   int // parameters parsed so far, repeated keys included
+}
+
+// cpuCheckEvery is how many parameters add stores between two looks at the
+// CPU clock.
+const cpuCheckEvery = 64
+
+// add stores one parameter. It checks the parameter limit on every call
+// and the CPU budget every cpuCheckEvery parameters, so an attack is cut
+// off while it is happening rather than after the table is already full.
+// Both count parameters, not distinct keys: repeating a colliding key costs
+// a full probe sequence each time without growing the table.
+func (in *ingester) add(key, value string) error {
+	if in.def.MaxKeys > 0 && in.params >= in.def.MaxKeys {
+		return errTooManyKeys
+	}
+	in.params++
+	in.t.Put(key, value)
+	if in.def.CPUBudget > 0 && in.params%cpuCheckEvery == 0 && threadCPUTime()-in.cpuStart > in.def.CPUBudget {
+		return errCPUBudget
+	}
+	return nil
+}
+
+// addPairs parses a URL-encoded "k=v&k=v" string. It is used instead of
+// url.ParseQuery so every key goes through add (and its limits) as soon as
+// it is read.
+func (in *ingester) addPairs(raw string) error {
+	for raw != "" {
+		var pair string
+		pair, raw, _ = strings.Cut(raw, "&")
+		if pair == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(pair, "=")
+		key, err := url.QueryUnescape(k)
+		if err != nil {
+			return err
+		}
+		value, err := url.QueryUnescape(v)
+		if err != nil {
+			return err
+		}
+		if err := in.add(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addBody parses a form or JSON object body, depending on Content-Type.
+func (in *ingester) addBody(r *http.Request) error {
+	ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch ct {
+	case "application/json":
+		return in.addJSON(r.Body)
+	case "application/x-www-form-urlencoded", "":
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		return in.addPairs(string(body))
+	default:
+		return fmt.Errorf("unsupported Content-Type %q", ct)
+	}
+}
+
+// addJSON streams the top-level members of a JSON object into the table.
+// Values are stored as their raw JSON text.
+func (in *ingester) addJSON(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return errNotObject
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := tok.(string)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return err
+		}
+		if err := in.add(key, string(value)); err != nil {
+			return err
+		}
+	}
+	_, err = dec.Token()
+	return err
+}
+
+// ----------------------------------------
+// Metrics
+// ----------------------------------------
+
+type tableMetrics struct {
+	requests     int
+	rejectedSize int // 413: too many keys or body too large
+	rejectedCPU  int // 503: CPU budget exceeded
+	rejectedBad  int // 400: malformed input
+	cpu          time.Duration
+	latencies    []time.Duration
+}
+
+type serverMetrics struct {
+	mu      sync.Mutex
+	byTable detmap.SortedMap[string, *tableMetrics]
+}
+
+func newServerMetrics() *serverMetrics {
+	return &serverMetrics{}
+}
+
+func (m *serverMetrics) record(kind string, status int, wall, cpu time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tm, ok := m.byTable.Get(kind)
+	if !ok {
+		tm = &tableMetrics{}
+		m.byTable.Set(kind, tm)
+	}
+	tm.requests++
+	tm.cpu += cpu
+	tm.latencies = append(tm.latencies, wall)
+	switch status {
+	case http.StatusRequestEntityTooLarge:
+		tm.rejectedSize++
+	case http.StatusServiceUnavailable:
+		tm.rejectedCPU++
+	case http.StatusBadRequest:
+		tm.rejectedBad++
+	}
+}
+
+// writeTo prints one line per table, sorted by table name. byTable is a
+// SortedMap, so there is no need to collect and sort its keys first.
+func (m *serverMetrics) writeTo(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fmt.Fprintf(w, "%-8s %8s %8s %8s %8s %10s %10s %10s %10s\n",
+		"table", "requests", "413", "503", "400", "p50", "p99", "max", "cpu")
+	for kind, tm := range m.byTable.All() {
+		p50, p99, worst := percentiles(tm.latencies)
+		fmt.Fprintf(w, "%-8s %8d %8d %8d %8d %10v %10v %10v %10v\n",
+			kind, tm.requests, tm.rejectedSize, tm.rejectedCPU, tm.rejectedBad,
+			round(p50), round(p99), round(worst), round(tm.cpu))
+	}
+}
+
+// percentiles returns the 50th and 99th percentile and the maximum of ds.
+func percentiles(ds []time.Duration) (p50, p99, worst time.Duration) {
+	if len(ds) == 0 {
+		return 0, 0, 0
+	}
+	sorted := slices.Clone(ds)
+	slices.Sort(sorted)
+	at := func(p float64) time.Duration { return sorted[int(p*float64(len(sorted)-1))] }
+	return at(0.50), at(0.99), sorted[len(sorted)-1]
+}
+
+// round trims a duration to three significant-ish digits for tables.
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	default:
+		return d.Round(time.Microsecond)
+	}
+}
+
+// ----------------------------------------
+// Listening (localhost only)
+// ----------------------------------------
+
+// listenLoopback listens on addr and refuses anything that is not a loopback
+// address: this is an attack simulator, not something to expose.
+func listenLoopback(addr string) (net.Listener, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if host != "localhost" {
+		ip := net.ParseIP(host)
+		if ip == nil || !ip.IsLoopback() {
+			return nil, fmt.Errorf("refusing to listen on %q: only loopback addresses are allowed", addr)
+		}
+	}
+	return net.Listen("tcp", addr)
+}
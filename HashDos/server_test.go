@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+// Repeating a colliding key does not grow the table, so a limit on the
+// number of distinct keys never fires. Every parameter must count.
+func TestRepeatedKeysCountTowardLimits(t *testing.T) {
+	keys := collidingKeys(999)
+	for range 40000 {
+		keys = append(keys, keys[len(keys)-1])
+	}
+
+	tests := []struct {
+		name string
+		def  defenses
+		want int
+	}{
+		{"max keys", defenses{MaxKeys: 1000}, http.StatusRequestEntityTooLarge},
+		{"cpu budget", defenses{CPUBudget: 20 * time.Millisecond}, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(newHashServer(tt.def).handler())
+			defer srv.Close()
+
+			resp, err := http.Get(srv.URL + "/naive?" + encodeForm(keys))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			var res storeResult
+			if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.want {
+				t.Errorf("status %d after %d probes, want %d", resp.StatusCode, res.Probes, tt.want)
+			}
+		})
+	}
+}
+
+func TestIngesterCountsParameters(t *testing.T) {
+	in := &ingester{t: newTable("builtin"), def: defenses{MaxKeys: 3}}
+	var errs []error
+	for range 5 {
+		errs = append(errs, in.add("k", "v"))
+	}
+	want := []error{nil, nil, nil, errTooManyKeys, errTooManyKeys}
+	if !slices.Equal(errs, want) {
+		t.Errorf("add errors = %v, want %v", errs, want)
+	}
+}
@@ -0,0 +1,207 @@
+// Package shardmap provides ShardedMap, a concurrent map built from many
+// small built-in maps, each behind its own lock ("lock striping").
+//
+// It carries the HashDos lesson into concurrency. Keys are assigned to
+// shards with hash/maphash under a seed chosen when the map is created, so
+// an attacker cannot aim all their keys at one shard and serialize every
+// goroutine on its lock. Inside a shard, keys live in a built-in map, which
+// picks its own random seed: every shard is seeded independently.
+package shardmap
+
+import (
+	"hash/maphash"
+	"iter"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// ShardedMap is a map safe for concurrent use by multiple goroutines.
+// The zero value is not usable; create one with New.
+type ShardedMap[K comparable, V any] struct {
+	seed   maphash.Seed
+	mask   uint64
+	shards []shard[K, V]
+}
+
+type shard[K comparable, V any] struct {
+	mu sync.RWMutex
+	m  map[K]V
+	// Pad each shard to a multiple of a cache line (64 bytes on common
+	// CPUs), so goroutines locking neighboring shards do not slow each
+	// other down.
+	_ [(64 - (unsafe.Sizeof(sync.RWMutex{})+unsafe.Sizeof(map[K]V(nil)))%64) % 64]byte
+}
+
+// New returns an empty map with at least the given number of shards,
+// rounded up to a power of two. If shards <= 0, it uses 4×GOMAXPROCS.
+func New[K comparable, V any](shards int) *ShardedMap[K, V] {
+	if shards <= 0 {
+		shards = 4 * runtime.GOMAXPROCS(0)
+	}
+	n := 1
+	for n < shards {
+		n *= 2
+	}
+	m := &ShardedMap[K, V]{
+		seed:   maphash.MakeSeed(),
+		mask:   uint64(n - 1),
+		shards: make([]shard[K, V], n),
+	}
+	for i := range m.shards {
+		m.shards[i].m = make(map[K]V)
+	}
+	return m
+}
+
+// Shards returns the number of shards.
+func (m *ShardedMap[K, V]) Shards() int { return len(m.shards) }
+
+func (m *ShardedMap[K, V]) shardFor(key K) *shard[K, V] {
+	return &m.shards[maphash.Comparable(m.seed, key)&m.mask]
+}
+
+// Load returns the value stored for key and whether it was present.
+func (m *ShardedMap[K, V]) Load(key K) (V, bool) {
+	s := m.shardFor(key)
+	s.mu.RLock()
+	v, ok := s.m[key]
+	s.mu.RUnlock()
+	return v, ok
+}
+
+// Store sets the value for key.
+func (m *ShardedMap[K, V]) Store(key K, value V) {
+	s := m.shardFor(key)
+	s.mu.Lock()
+	s.m[key] = value
+	s.mu.Unlock()
+}
+
+// Delete removes key.
+func (m *ShardedMap[K, V]) Delete(key K) {
+	s := m.shardFor(key)
+	s.mu.Lock()
+	delete(s.m, key)
+	s.mu.Unlock()
+}
+
+// LoadOrStore returns the existing value for key if present. Otherwise it
+// stores value and returns it. loaded reports whether the value was
+// already there.
+func (m *ShardedMap[K, V]) LoadOrStore(key K, value V) (actual V, loaded bool) {
+	s := m.shardFor(key)
+
+	// Most calls find the key: try under the read lock first.
+	s.mu.RLock()
+	v, ok := s.m[key]
+	s.mu.RUnlock()
+	if ok {
+		return v, true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v, ok := s.m[key]; ok {
+		return v, true
+	}
+	s.m[key] = value
+	return value, false
+}
+
+// LoadAndDelete removes key and returns its previous value, if any.
+func (m *ShardedMap[K, V]) LoadAndDelete(key K) (V, bool) {
+	s := m.shardFor(key)
+	s.mu.Lock()
+	v, ok := s.m[key]
+	delete(s.m, key)
+	s.mu.Unlock()
+	return v, ok
+}
+
+// ComputeOp tells Compute what to do with the value returned by its
+// callback.
+type ComputeOp int
+
+const (
+	UpdateOp ComputeOp = iota // store the new value
+	DeleteOp                  // delete the key
+	CancelOp                  // leave the map unchanged
+)
+
+// Compute atomically reads, modifies and writes the value for key. fn gets
+// the current value (the zero value if loaded is false) and returns the new
+// value and what to do with it. Compute returns the value stored for key
+// afterwards and whether the key is present.
+//
+// fn runs with the shard locked: it must be quick and must not use m.
+func (m *ShardedMap[K, V]) Compute(key K, fn func(old V, loaded bool) (V, ComputeOp)) (V, bool) {
+	s := m.shardFor(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, loaded := s.m[key]
+	v, op := fn(old, loaded)
+	switch op {
+	case UpdateOp:
+		s.m[key] = v
+		return v, true
+	case DeleteOp:
+		delete(s.m, key)
+		var zero V
+		return zero, false
+	default:
+		return old, loaded
+	}
+}
+
+// Len returns the number of entries. Under concurrent updates, it is only
+// a snapshot: each shard is counted at a slightly different moment.
+func (m *ShardedMap[K, V]) Len() int {
+	n := 0
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mu.RLock()
+		n += len(s.m)
+		s.mu.RUnlock()
+	}
+	return n
+}
+
+// All returns an iterator over a snapshot of the map. Each shard is copied
+// under its read lock and then yielded with no lock held, so the loop body
+// may freely call methods of m. The snapshot is consistent per shard, not
+// across shards. Order is random, as with a built-in map.
+func (m *ShardedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		type kv struct {
+			k K
+			v V
+		}
+		var buf []kv
+		for i := range m.shards {
+			s := &m.shards[i]
+			s.mu.RLock()
+			buf = buf[:0]
+			for k, v := range s.m {
+				buf = append(buf, kv{k, v})
+			}
+			s.mu.RUnlock()
+
+			for _, e := range buf {
+				if !yield(e.k, e.v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Snapshot returns a copy of the map as a built-in map.
+func (m *ShardedMap[K, V]) Snapshot() map[K]V {
+	out := make(map[K]V, m.Len())
+	for k, v := range m.All() {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,207 @@
+package shardmap_test
+
+import (
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"testing"
+
+	"Lets-GO/HashDos/shardmap"
+)
+
+// The tests hammer one ShardedMap from many goroutines. Run them with
+// go test -race to let the race detector watch.
+
+const workers, perWorker, counters = 16, 2000, 64
+
+// Compute is atomic: concurrent increments are never lost.
+func TestComputeConcurrent(t *testing.T) {
+	m := shardmap.New[int, int](0)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(func() {
+			for i := range perWorker {
+				m.Compute(i%counters, func(old int, _ bool) (int, shardmap.ComputeOp) {
+					return old + 1, shardmap.UpdateOp
+				})
+			}
+		})
+	}
+	wg.Wait()
+	total := 0
+	for _, v := range m.All() {
+		total += v
+	}
+	if total != workers*perWorker {
+		t.Errorf("Compute lost updates: total %d, want %d", total, workers*perWorker)
+	}
+}
+
+// LoadOrStore has exactly one winner per key.
+func TestLoadOrStoreConcurrent(t *testing.T) {
+	m := shardmap.New[int, int](0)
+	winners := make([]int, counters)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Go(func() {
+			for k := range counters {
+				actual, loaded := m.LoadOrStore(k, w)
+				if !loaded {
+					mu.Lock()
+					winners[k]++
+					mu.Unlock()
+				}
+				if v, _ := m.Load(k); v != actual {
+					t.Errorf("key %d: Load %d after LoadOrStore returned %d", k, v, actual)
+				}
+			}
+		})
+	}
+	wg.Wait()
+	for k, n := range winners {
+		if n != 1 {
+			t.Errorf("LoadOrStore key %d stored %d times, want once", k, n)
+		}
+	}
+}
+
+// Snapshots can be taken while writers run, and the loop body may use the
+// map without deadlocking.
+func TestAllDuringWrites(t *testing.T) {
+	m := shardmap.New[int, int](0)
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Go(func() {
+			r := rand.New(rand.NewPCG(uint64(w), 0))
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				k := r.IntN(1000)
+				if r.IntN(2) == 0 {
+					m.Store(k, k)
+				} else {
+					m.Delete(k)
+				}
+			}
+		})
+	}
+	defer func() {
+		close(stop)
+		wg.Wait()
+	}()
+	for range 100 {
+		for k, v := range m.All() {
+			if v != k {
+				t.Fatalf("snapshot saw %d=%d", k, v)
+			}
+			m.Load(k) // allowed: no lock is held while yielding
+		}
+	}
+}
+
+// ----------------------------------------
+// Benchmarks
+// ----------------------------------------
+//
+// sync.Map is tuned for keys that are written once and read many times; a
+// single mutex serializes everything; sharding spreads both reads and writes
+// over many locks, and its seeded shard hash keeps an attacker from piling
+// all keys onto one of them.
+
+// concurrentMap is what the benchmarks need from each implementation.
+type concurrentMap interface {
+	Load(int) (int, bool)
+	Store(int, int)
+}
+
+type mutexMap struct {
+	mu sync.Mutex
+	m  map[int]int
+}
+
+func (m *mutexMap) Load(k int) (int, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.m[k]
+	return v, ok
+}
+
+func (m *mutexMap) Store(k, v int) {
+	m.mu.Lock()
+	m.m[k] = v
+	m.mu.Unlock()
+}
+
+type rwMutexMap struct {
+	mu sync.RWMutex
+	m  map[int]int
+}
+
+func (m *rwMutexMap) Load(k int) (int, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	v, ok := m.m[k]
+	return v, ok
+}
+
+func (m *rwMutexMap) Store(k, v int) {
+	m.mu.Lock()
+	m.m[k] = v
+	m.mu.Unlock()
+}
+
+// syncMap adapts sync.Map to concurrentMap.
+type syncMap struct{ m sync.Map }
+
+func (m *syncMap) Load(k int) (int, bool) {
+	v, ok := m.m.Load(k)
+	if !ok {
+		return 0, false
+	}
+	return v.(int), true
+}
+
+func (m *syncMap) Store(k, v int) { m.m.Store(k, v) }
+
+// BenchmarkConcurrentMaps runs every implementation under every
+// read/write mix, with GOMAXPROCS goroutines over 1<<16 keys.
+func BenchmarkConcurrentMaps(b *testing.B) {
+	const keys = 1 << 16
+
+	impls := []struct {
+		name string
+		new  func() concurrentMap
+	}{
+		{"Mutex+map", func() concurrentMap { return &mutexMap{m: map[int]int{}} }},
+		{"RWMutex+map", func() concurrentMap { return &rwMutexMap{m: map[int]int{}} }},
+		{"sync.Map", func() concurrentMap { return &syncMap{} }},
+		{"ShardedMap", func() concurrentMap { return shardmap.New[int, int](0) }},
+	}
+	for _, impl := range impls {
+		for _, readPct := range []int{99, 90, 50, 10} {
+			b.Run(impl.name+"/reads="+strconv.Itoa(readPct)+"%", func(b *testing.B) {
+				m := impl.new()
+				for k := range keys {
+					m.Store(k, k)
+				}
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					r := rand.New(rand.NewPCG(rand.Uint64(), 0))
+					for pb.Next() {
+						k := r.IntN(keys)
+						if r.IntN(100) < readPct {
+							m.Load(k)
+						} else {
+							m.Store(k, k)
+						}
+					}
+				})
+			})
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package sketch
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+)
+
+// BloomParams returns the number of bits m and hash functions k that give a
+// false-positive rate of p after n insertions:
+//
+//	m = -n·ln(p) / ln(2)²    k = (m/n)·ln(2)
+func BloomParams(n int, p float64) (m uint64, k int) {
+	n = max(n, 1)
+	p = min(max(p, 1e-12), 0.5)
+	m = uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	k = max(1, int(math.Round(float64(m)/float64(n)*math.Ln2)))
+	return m, k
+}
+
+// bloomFPRate is the expected false-positive rate of a filter with m bits
+// and k hash functions holding n keys: (1 - e^(-kn/m))^k.
+func bloomFPRate(m uint64, k int, n uint64) float64 {
+	return math.Pow(1-math.Exp(-float64(k)*float64(n)/float64(m)), float64(k))
+}
+
+// Bloom is a Bloom filter: a set that may report false positives but never
+// false negatives.
+type Bloom struct {
+	seed Seed
+	m    uint64 // bits
+	k    int    // hash functions
+	n    uint64 // keys added
+	bits []uint64
+}
+
+// NewBloom returns a filter sized for n keys at false-positive rate p.
+func NewBloom(n int, p float64, seed Seed) *Bloom {
+	m, k := BloomParams(n, p)
+	return &Bloom{seed: seed, m: m, k: k, bits: make([]uint64, (m+63)/64)}
+}
+
+// M returns the number of bits, K the number of hash functions and N the
+// number of keys added.
+func (f *Bloom) M() uint64 { return f.m }
+func (f *Bloom) K() int    { return f.k }
+func (f *Bloom) N() uint64 { return f.n }
+
+// Add inserts key.
+func (f *Bloom) Add(key []byte) {
+	f.seed.indexes(key, f.k, f.m, func(_ int, idx uint64) {
+		f.bits[idx/64] |= 1 << (idx % 64)
+	})
+	f.n++
+}
+
+// Contains reports whether key may have been added.
+func (f *Bloom) Contains(key []byte) bool {
+	ok := true
+	f.seed.indexes(key, f.k, f.m, func(_ int, idx uint64) {
+		ok = ok && f.bits[idx/64]&(1<<(idx%64)) != 0
+	})
+	return ok
+}
+
+// FalsePositiveRate returns the theoretical false-positive rate for the
+// keys added so far.
+func (f *Bloom) FalsePositiveRate() float64 { return bloomFPRate(f.m, f.k, f.n) }
+
+// FillRatio returns the fraction of bits set.
+func (f *Bloom) FillRatio() float64 {
+	set := 0
+	for _, w := range f.bits {
+		set += bits.OnesCount64(w)
+	}
+	return float64(set) / float64(f.m)
+}
+
+func (f *Bloom) compatible(g *Bloom) bool {
+	return f.m == g.m && f.k == g.k && f.seed.Equal(g.seed)
+}
+
+// Union adds every key of g to f. The result is exactly the filter that
+// would have been built from both key sets.
+func (f *Bloom) Union(g *Bloom) error {
+	if !f.compatible(g) {
+		return ErrIncompatible
+	}
+	for i := range f.bits {
+		f.bits[i] |= g.bits[i]
+	}
+	f.n += g.n
+	return nil
+}
+
+// Intersect keeps only the bits set in both f and g. Keys in both sets are
+// still reported; the false-positive rate is at most that of either input.
+func (f *Bloom) Intersect(g *Bloom) error {
+	if !f.compatible(g) {
+		return ErrIncompatible
+	}
+	for i := range f.bits {
+		f.bits[i] &= g.bits[i]
+	}
+	f.n = min(f.n, g.n)
+	return nil
+}
+
+// MarshalBinary encodes the filter, including its seed. It fails with
+// ErrLocalSeed for filters using NewLocalSeed.
+func (f *Bloom) MarshalBinary() ([]byte, error) {
+	b, err := appendHeader(nil, "LGBF", f.seed)
+	if err != nil {
+		return nil, err
+	}
+	b = binary.LittleEndian.AppendUint64(b, f.m)
+	b = binary.LittleEndian.AppendUint64(b, uint64(f.k))
+	b = binary.LittleEndian.AppendUint64(b, f.n)
+	for _, w := range f.bits {
+		b = binary.LittleEndian.AppendUint64(b, w)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary restores a filter written by MarshalBinary.
+func (f *Bloom) UnmarshalBinary(data []byte) error {
+	seed, rest, err := readHeader(data, "LGBF")
+	if err != nil {
+		return err
+	}
+	r := &reader{b: rest}
+	m, k, n := r.uint64(), r.uint64(), r.uint64()
+	if err := checkShape(m, k); err != nil {
+		return err
+	}
+	raw := r.bytes((m + 63) / 64 * 8)
+	if err := r.done(); err != nil {
+		return err
+	}
+	words := make([]uint64, (m+63)/64)
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint64(raw[8*i:])
+	}
+	*f = Bloom{seed: seed, m: m, k: int(k), n: n, bits: words}
+	return nil
+}
@@ -0,0 +1,132 @@
+package sketch
+
+import "encoding/binary"
+
+// CountingBloom is a Bloom filter with an 8-bit counter per cell instead of a
+// bit, so keys can be removed. Counters saturate at 255 and then stay there:
+// decrementing a saturated counter could create false negatives.
+type CountingBloom struct {
+	seed     Seed
+	m        uint64
+	k        int
+	n        uint64 // keys currently added
+	counters []uint8
+}
+
+// NewCountingBloom returns a counting filter sized for n keys at
+// false-positive rate p.
+func NewCountingBloom(n int, p float64, seed Seed) *CountingBloom {
+	m, k := BloomParams(n, p)
+	return &CountingBloom{seed: seed, m: m, k: k, counters: make([]uint8, m)}
+}
+
+// M returns the number of counters, K the number of hash functions and N
+// the number of keys currently added.
+func (f *CountingBloom) M() uint64 { return f.m }
+func (f *CountingBloom) K() int    { return f.k }
+func (f *CountingBloom) N() uint64 { return f.n }
+
+// Add inserts key.
+func (f *CountingBloom) Add(key []byte) {
+	f.seed.indexes(key, f.k, f.m, func(_ int, idx uint64) {
+		if f.counters[idx] < 255 {
+			f.counters[idx]++
+		}
+	})
+	f.n++
+}
+
+// Remove deletes one occurrence of key and reports whether it may have been
+// present. Removing a key that was never added corrupts the filter, as with
+// any counting Bloom filter; the check below only catches the obvious cases.
+func (f *CountingBloom) Remove(key []byte) bool {
+	if !f.Contains(key) {
+		return false
+	}
+	f.seed.indexes(key, f.k, f.m, func(_ int, idx uint64) {
+		if f.counters[idx] < 255 {
+			f.counters[idx]--
+		}
+	})
+	f.n--
+	return true
+}
+
+// Contains reports whether key may be present.
+func (f *CountingBloom) Contains(key []byte) bool {
+	return f.Count(key) > 0
+}
+
+// Count returns an upper bound on how many times key is present: the
+// smallest of its counters.
+func (f *CountingBloom) Count(key []byte) int {
+	c := 255
+	f.seed.indexes(key, f.k, f.m, func(_ int, idx uint64) {
+		c = min(c, int(f.counters[idx]))
+	})
+	return c
+}
+
+// FalsePositiveRate returns the theoretical false-positive rate for the
+// keys currently present.
+func (f *CountingBloom) FalsePositiveRate() float64 { return bloomFPRate(f.m, f.k, f.n) }
+
+func (f *CountingBloom) compatible(g *CountingBloom) bool {
+	return f.m == g.m && f.k == g.k && f.seed.Equal(g.seed)
+}
+
+// Union adds the counters of g to f (saturating), as if every key of g had
+// been added to f.
+func (f *CountingBloom) Union(g *CountingBloom) error {
+	if !f.compatible(g) {
+		return ErrIncompatible
+	}
+	for i, c := range g.counters {
+		f.counters[i] = uint8(min(255, int(f.counters[i])+int(c)))
+	}
+	f.n += g.n
+	return nil
+}
+
+// Intersect keeps the smaller of each pair of counters.
+func (f *CountingBloom) Intersect(g *CountingBloom) error {
+	if !f.compatible(g) {
+		return ErrIncompatible
+	}
+	for i, c := range g.counters {
+		f.counters[i] = min(f.counters[i], c)
+	}
+	f.n = min(f.n, g.n)
+	return nil
+}
+
+// MarshalBinary encodes the filter, including its seed.
+func (f *CountingBloom) MarshalBinary() ([]byte, error) {
+	b, err := appendHeader(nil, "LGCB", f.seed)
+	if err != nil {
+		return nil, err
+	}
+	b = binary.LittleEndian.AppendUint64(b, f.m)
+	b = binary.LittleEndian.AppendUint64(b, uint64(f.k))
+	b = binary.LittleEndian.AppendUint64(b, f.n)
+	return append(b, f.counters...), nil
+}
+
+// UnmarshalBinary restores a filter written by MarshalBinary.
+func (f *CountingBloom) UnmarshalBinary(data []byte) error {
+	seed, rest, err := readHeader(data, "LGCB")
+	if err != nil {
+		return err
+	}
+	r := &reader{b: rest}
+	m, k, n := r.uint64(), r.uint64(), r.uint64()
+	if err := checkShape(m, k); err != nil {
+		return err
+	}
+	raw := r.bytes(m)
+	if err := r.done(); err != nil {
+		return err
+	}
+	*f = CountingBloom{seed: seed, m: m, k: int(k), n: n, counters: append([]uint8(nil), raw...)}
+	return nil
+}
@@ -0,0 +1,127 @@
+package sketch
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// CountMin is a count-min sketch: it estimates how often each key was seen
+// in a stream, using a fixed amount of memory. Estimates never undercount.
+// With probability 1-delta, an estimate exceeds the true count by at most
+// epsilon times the total of all counts.
+type CountMin struct {
+	seed     Seed
+	width    uint64
+	depth    int
+	total    uint64
+	counters []uint64 // depth rows of width counters
+}
+
+// CountMinParams returns the width e/epsilon and depth ln(1/delta) for the
+// given error bound and failure probability.
+func CountMinParams(epsilon, delta float64) (width uint64, depth int) {
+	epsilon = min(max(epsilon, 1e-9), 1)
+	delta = min(max(delta, 1e-12), 0.5)
+	width = uint64(math.Ceil(math.E / epsilon))
+	depth = max(1, int(math.Ceil(math.Log(1/delta))))
+	return width, depth
+}
+
+// NewCountMin returns a sketch with the given error bound epsilon (relative
+// to the total count) and failure probability delta.
+func NewCountMin(epsilon, delta float64, seed Seed) *CountMin {
+	w, d := CountMinParams(epsilon, delta)
+	return &CountMin{seed: seed, width: w, depth: d, counters: make([]uint64, w*uint64(d))}
+}
+
+// Width, Depth and Total return the sketch's shape and the sum of all counts.
+func (s *CountMin) Width() uint64 { return s.width }
+func (s *CountMin) Depth() int    { return s.depth }
+func (s *CountMin) Total() uint64 { return s.total }
+
+// Epsilon returns the error bound the sketch was sized for.
+func (s *CountMin) Epsilon() float64 { return math.E / float64(s.width) }
+
+// Add counts key n more times.
+func (s *CountMin) Add(key []byte, n uint64) {
+	s.seed.indexes(key, s.depth, s.width, func(row int, idx uint64) {
+		s.counters[uint64(row)*s.width+idx] += n
+	})
+	s.total += n
+}
+
+// Estimate returns the estimated count of key.
+func (s *CountMin) Estimate(key []byte) uint64 {
+	est := uint64(math.MaxUint64)
+	s.seed.indexes(key, s.depth, s.width, func(row int, idx uint64) {
+		est = min(est, s.counters[uint64(row)*s.width+idx])
+	})
+	return est
+}
+
+func (s *CountMin) compatible(t *CountMin) bool {
+	return s.width == t.width && s.depth == t.depth && s.seed.Equal(t.seed)
+}
+
+// Union adds t's counts to s: the result sketches both streams together.
+func (s *CountMin) Union(t *CountMin) error {
+	if !s.compatible(t) {
+		return ErrIncompatible
+	}
+	for i, c := range t.counters {
+		s.counters[i] += c
+	}
+	s.total += t.total
+	return nil
+}
+
+// Intersect keeps the smaller of each pair of counters. Estimates are then
+// upper bounds on the smaller of a key's counts in the two streams.
+func (s *CountMin) Intersect(t *CountMin) error {
+	if !s.compatible(t) {
+		return ErrIncompatible
+	}
+	for i, c := range t.counters {
+		s.counters[i] = min(s.counters[i], c)
+	}
+	s.total = min(s.total, t.total)
+	return nil
+}
+
+// MarshalBinary encodes the sketch, including its seed.
+func (s *CountMin) MarshalBinary() ([]byte, error) {
+	b, err := appendHeader(nil, "LGCM", s.seed)
+	if err != nil {
+		return nil, err
+	}
+	b = binary.LittleEndian.AppendUint64(b, s.width)
+	b = binary.LittleEndian.AppendUint64(b, uint64(s.depth))
+	b = binary.LittleEndian.AppendUint64(b, s.total)
+	for _, c := range s.counters {
+		b = binary.LittleEndian.AppendUint64(b, c)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary restores a sketch written by MarshalBinary.
+func (s *CountMin) UnmarshalBinary(data []byte) error {
+	seed, rest, err := readHeader(data, "LGCM")
+	if err != nil {
+		return err
+	}
+	r := &reader{b: rest}
+	w, d, total := r.uint64(), r.uint64(), r.uint64()
+	if err := checkShape(w, d); err != nil {
+		return err
+	}
+	raw := r.bytes(w * d * 8)
+	if err := r.done(); err != nil {
+		return err
+	}
+	counters := make([]uint64, w*d)
+	for i := range counters {
+		counters[i] = binary.LittleEndian.Uint64(raw[8*i:])
+	}
+	*s = CountMin{seed: seed, width: w, depth: int(d), total: total, counters: counters}
+	return nil
+}
@@ -0,0 +1,85 @@
+package sketch
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Serialized structures start with a 4-byte magic, a version byte and the
+// 16-byte SipHash key, followed by structure-specific fields. All integers
+// are little-endian.
+const version = 1
+
+const headerLen = 4 + 1 + 16
+
+var errShort = errors.New("sketch: data too short")
+
+func appendHeader(b []byte, magic string, s Seed) ([]byte, error) {
+	if !s.Portable() {
+		return nil, ErrLocalSeed
+	}
+	b = append(b, magic...)
+	b = append(b, version)
+	b = binary.LittleEndian.AppendUint64(b, s.key[0])
+	b = binary.LittleEndian.AppendUint64(b, s.key[1])
+	return b, nil
+}
+
+func readHeader(b []byte, magic string) (Seed, []byte, error) {
+	if len(b) < headerLen {
+		return Seed{}, nil, errShort
+	}
+	if string(b[:4]) != magic {
+		return Seed{}, nil, fmt.Errorf("sketch: bad magic %q, want %q", b[:4], magic)
+	}
+	if b[4] != version {
+		return Seed{}, nil, fmt.Errorf("sketch: unsupported version %d", b[4])
+	}
+	s := Seed{key: [2]uint64{binary.LittleEndian.Uint64(b[5:]), binary.LittleEndian.Uint64(b[13:])}}
+	return s, b[headerLen:], nil
+}
+
+// reader consumes little-endian integers from a byte slice, remembering the
+// first error.
+type reader struct {
+	b   []byte
+	err error
+}
+
+func (r *reader) uint64() uint64 {
+	if r.err != nil || len(r.b) < 8 {
+		r.err = errShort
+		return 0
+	}
+	v := binary.LittleEndian.Uint64(r.b)
+	r.b = r.b[8:]
+	return v
+}
+
+func (r *reader) bytes(n uint64) []byte {
+	if r.err != nil || uint64(len(r.b)) < n {
+		r.err = errShort
+		return nil
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v
+}
+
+// done returns the first error, or an error if data is left over.
+func (r *reader) done() error {
+	if r.err == nil && len(r.b) != 0 {
+		r.err = fmt.Errorf("sketch: %d trailing bytes", len(r.b))
+	}
+	return r.err
+}
+
+// checkShape rejects sizes no constructor would produce, before anything is
+// allocated for them.
+func checkShape(cells, k uint64) error {
+	if cells == 0 || cells > 1<<40 || k == 0 || k > 64 {
+		return fmt.Errorf("sketch: invalid size %d x %d", cells, k)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+// Package sketch provides seeded probabilistic data structures: a Bloom
+// filter, a counting Bloom filter and a count-min sketch.
+//
+// They apply the lesson of HashDos to structures that are even easier to
+// attack than a hash table. With a known hash function, an attacker can
+// craft keys that set exactly the bits of a key they want to appear present
+// (forcing false positives), or pile counts onto one cell of a count-min
+// sketch. Every structure here therefore hashes through a Seed, a secret
+// random per-instance key, and only structures with the same Seed can be
+// combined.
+//
+// Two kinds of seed exist:
+//
+//   - NewLocalSeed uses hash/maphash, exactly like Go's maps. It is fast, but
+//     a maphash.Seed cannot leave the process: structures using it cannot be
+//     serialized.
+//   - NewSeed generates a 128-bit key with crypto/rand and hashes with
+//     SipHash-2-4, the keyed hash many languages use for their own hash
+//     tables. The key is stored by MarshalBinary, so a structure can be
+//     saved and reloaded elsewhere with the same hash function.
+//
+// All structures use double hashing (Kirsch and Mitzenmacher): the i-th
+// index of a key is h1 + i*h2, where h1 and h2 are the two halves of one
+// 64-bit hash.
+package sketch
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"hash/maphash"
+	"math/bits"
+)
+
+// ErrLocalSeed is returned when serializing a structure whose Seed was made
+// by NewLocalSeed.
+var ErrLocalSeed = errors.New("sketch: maphash seed cannot be serialized; use NewSeed for structures that are saved")
+
+// ErrIncompatible is returned when combining structures with different
+// seeds or shapes.
+var ErrIncompatible = errors.New("sketch: structures have different seeds or sizes")
+
+// Seed is the secret key of a structure's hash function.
+type Seed struct {
+	local *maphash.Seed // non-nil for NewLocalSeed
+	key   [2]uint64     // SipHash key for NewSeed
+}
+
+// NewSeed returns a random seed that can be serialized.
+func NewSeed() Seed {
+	var b [16]byte
+	rand.Read(b[:])
+	return Seed{key: [2]uint64{binary.LittleEndian.Uint64(b[:8]), binary.LittleEndian.Uint64(b[8:])}}
+}
+
+// NewLocalSeed returns a random hash/maphash seed, valid in this process
+// only.
+func NewLocalSeed() Seed {
+	s := maphash.MakeSeed()
+	return Seed{local: &s}
+}
+
+// Portable reports whether structures using s can be serialized.
+func (s Seed) Portable() bool { return s.local == nil }
+
+// Equal reports whether s and t define the same hash function.
+func (s Seed) Equal(t Seed) bool {
+	if s.local != nil || t.local != nil {
+		return s.local != nil && t.local != nil && *s.local == *t.local
+	}
+	return s.key == t.key
+}
+
+// hash returns the 64-bit hash of b under s.
+func (s Seed) hash(b []byte) uint64 {
+	if s.local != nil {
+		return maphash.Bytes(*s.local, b)
+	}
+	return sipHash24(s.key[0], s.key[1], b)
+}
+
+// indexes calls fn with the k indexes of b in [0, m).
+func (s Seed) indexes(b []byte, k int, m uint64, fn func(i int, idx uint64)) {
+	h := s.hash(b)
+	h1, h2 := h&0xffffffff, h>>32|1
+	for i := range k {
+		fn(i, (h1+uint64(i)*h2)%m)
+	}
+}
+
+// sipHash24 is SipHash-2-4 (Aumasson and Bernstein) with a 128-bit key.
+func sipHash24(k0, k1 uint64, b []byte) uint64 {
+	v0 := k0 ^ 0x736f6d6570736575
+	v1 := k1 ^ 0x646f72616e646f6d
+	v2 := k0 ^ 0x6c7967656e657261
+	v3 := k1 ^ 0x7465646279746573
+
+	round := func() {
+		v0 += v1
+		v1 = bits.RotateLeft64(v1, 13)
+		v1 ^= v0
+		v0 = bits.RotateLeft64(v0, 32)
+		v2 += v3
+		v3 = bits.RotateLeft64(v3, 16)
+		v3 ^= v2
+		v0 += v3
+		v3 = bits.RotateLeft64(v3, 21)
+		v3 ^= v0
+		v2 += v1
+		v1 = bits.RotateLeft64(v1, 17)
+		v1 ^= v2
+		v2 = bits.RotateLeft64(v2, 32)
+	}
+
+	n := len(b)
+	for ; len(b) >= 8; b = b[8:] {
+		m := binary.LittleEndian.Uint64(b)
+		v3 ^= m
+		round()
+		round()
+		v0 ^= m
+	}
+
+	last := uint64(n) << 56
+	for i := len(b) - 1; i >= 0; i-- {
+		last |= uint64(b[i]) << (8 * i)
+	}
+	v3 ^= last
+	round()
+	round()
+	v0 ^= last
+
+	v2 ^= 0xff
+	round()
+	round()
+	round()
+	round()
+	return v0 ^ v1 ^ v2 ^ v3
+}
@@ -0,0 +1,183 @@
+package sketch_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"Lets-GO/HashDos/sketch"
+)
+
+// Keys 0..n-1 are added, keys n.. are never added.
+const (
+	n       = 10000
+	queries = 10 * n
+)
+
+func key(i int) []byte { return binary.LittleEndian.AppendUint64(nil, uint64(i)) }
+
+// withinBinomial reports whether a rate measured over n trials is within
+// four standard deviations (plus a 5% relative margin for the model's own
+// approximation) of the expected rate p.
+func withinBinomial(measured, p float64, n int) bool {
+	sigma := math.Sqrt(p * (1 - p) / float64(n))
+	return math.Abs(measured-p) <= 4*sigma+0.05*p
+}
+
+func TestBloomFalsePositiveRate(t *testing.T) {
+	seeds := []struct {
+		name string
+		new  func() sketch.Seed
+	}{{"maphash", sketch.NewLocalSeed}, {"siphash", sketch.NewSeed}}
+	for _, target := range []float64{0.1, 0.01, 0.001} {
+		for _, seed := range seeds {
+			f := sketch.NewBloom(n, target, seed.new())
+			for i := range n {
+				f.Add(key(i))
+			}
+			fp := 0
+			for i := n; i < n+queries; i++ {
+				if f.Contains(key(i)) {
+					fp++
+				}
+			}
+			measured := float64(fp) / queries
+			if theory := f.FalsePositiveRate(); !withinBinomial(measured, theory, queries) {
+				t.Errorf("%s target %g (m=%d k=%d): measured %.5f, theory %.5f",
+					seed.name, target, f.M(), f.K(), measured, theory)
+			}
+		}
+	}
+}
+
+func TestCountingBloomRemove(t *testing.T) {
+	cb := sketch.NewCountingBloom(n, 0.01, sketch.NewSeed())
+	for i := range n {
+		cb.Add(key(i))
+	}
+	for i := 0; i < n; i += 2 {
+		cb.Remove(key(i))
+	}
+	for i := 1; i < n; i += 2 {
+		if !cb.Contains(key(i)) {
+			t.Fatalf("key %d missing after removing other keys", i)
+		}
+	}
+	fp := 0
+	for i := n; i < n+queries; i++ {
+		if cb.Contains(key(i)) {
+			fp++
+		}
+	}
+	measured := float64(fp) / queries
+	if theory := cb.FalsePositiveRate(); !withinBinomial(measured, theory, queries) {
+		t.Errorf("false-positive rate for %d keys: measured %.5f, theory %.5f", cb.N(), measured, theory)
+	}
+}
+
+func TestCountMinErrorBound(t *testing.T) {
+	const epsilon, delta = 0.001, 0.01
+	cm := sketch.NewCountMin(epsilon, delta, sketch.NewSeed())
+	truth := make([]uint64, n)
+	zipf := rand.NewZipf(rand.New(rand.NewPCG(1, 2)), 1.1, 1, n-1)
+	for range 10 * n {
+		i := int(zipf.Uint64())
+		truth[i]++
+		cm.Add(key(i), 1)
+	}
+	bound := uint64(epsilon * float64(cm.Total()))
+	over := 0
+	for i, c := range truth {
+		est := cm.Estimate(key(i))
+		if est < c {
+			t.Fatalf("key %d: estimate %d undercounts %d", i, est, c)
+		}
+		if est > c+bound {
+			over++
+		}
+	}
+	if rate := float64(over) / n; rate > delta {
+		t.Errorf("width=%d depth=%d: %.4f of keys exceed true+ε·N (=%d), allowed δ=%g",
+			cm.Width(), cm.Depth(), rate, bound, delta)
+	}
+}
+
+func TestMarshalKeepsSeed(t *testing.T) {
+	f := sketch.NewBloom(n, 0.01, sketch.NewSeed())
+	for i := range n {
+		f.Add(key(i))
+	}
+	data, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var g sketch.Bloom
+	if err := g.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	for i := range n + queries/10 {
+		if f.Contains(key(i)) != g.Contains(key(i)) {
+			t.Fatalf("reloaded filter disagrees on key %d", i)
+		}
+	}
+
+	cm := sketch.NewCountMin(0.001, 0.01, sketch.NewSeed())
+	for i := range n {
+		cm.Add(key(i%100), 1)
+	}
+	data, err = cm.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cm2 sketch.CountMin
+	if err := cm2.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if cm2.Total() != cm.Total() || cm2.Estimate(key(0)) != cm.Estimate(key(0)) {
+		t.Errorf("reloaded count-min sketch: total %d, estimate %d; want %d, %d",
+			cm2.Total(), cm2.Estimate(key(0)), cm.Total(), cm.Estimate(key(0)))
+	}
+
+	_, err = sketch.NewBloom(n, 0.01, sketch.NewLocalSeed()).MarshalBinary()
+	if !errors.Is(err, sketch.ErrLocalSeed) {
+		t.Errorf("marshaling a maphash-seeded filter: err = %v, want ErrLocalSeed", err)
+	}
+}
+
+func TestUnionIntersect(t *testing.T) {
+	seed := sketch.NewSeed()
+	a := sketch.NewBloom(n, 0.01, seed)
+	b := sketch.NewBloom(n, 0.01, seed)
+	for i := range n / 2 {
+		a.Add(key(i))       // [0, n/2)
+		b.Add(key(i + n/4)) // [n/4, 3n/4)
+	}
+	u := sketch.NewBloom(n, 0.01, seed)
+	if err := u.Union(a); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Union(b); err != nil {
+		t.Fatal(err)
+	}
+	for i := range 3 * n / 4 {
+		if !u.Contains(key(i)) {
+			t.Fatalf("union is missing key %d", i)
+		}
+	}
+
+	if err := a.Intersect(b); err != nil {
+		t.Fatal(err)
+	}
+	for i := n / 4; i < n/2; i++ {
+		if !a.Contains(key(i)) {
+			t.Fatalf("intersection is missing key %d", i)
+		}
+	}
+
+	err := a.Union(sketch.NewBloom(n, 0.01, sketch.NewSeed()))
+	if !errors.Is(err, sketch.ErrIncompatible) {
+		t.Errorf("union of filters with different seeds: err = %v, want ErrIncompatible", err)
+	}
+}
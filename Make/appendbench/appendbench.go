@@ -0,0 +1,102 @@
+// Package appendbench measures the ways of building a slice of n elements
+// that the Make lesson talks about.
+//
+// Every strategy builds the same []int holding 0..n-1:
+//
+//	AppendNoPrealloc  var s []int; s = append(s, i)        (grows as it goes)
+//	MakeLenIndex      s := make([]int, n); s[i] = i         (one allocation)
+//	MakeCapAppend     s := make([]int, 0, n); append        (one allocation)
+//	SlicesGrow        s = slices.Grow(s, n); append         (one allocation)
+//	BulkAppend64      append 64 elements at a time, no prealloc
+//	AppendAll         s := append([]int(nil), src...)      (one call)
+//
+// The benchmarks in appendbench_test.go run every strategy at DefaultSizes.
+// Pipe them into benchsum for markdown tables:
+//
+//	go test -bench . -benchmem ./Make/appendbench | go run ./Make/benchsum/cmd/benchsum
+package appendbench
+
+import "slices"
+
+// Strategy is one way of building the slice.
+type Strategy struct {
+	Name  string
+	Build func(n int) []int
+}
+
+// Strategies lists every strategy, AppendNoPrealloc first: it is the
+// baseline the others are compared with.
+var Strategies = []Strategy{
+	{"AppendNoPrealloc", appendNoPrealloc},
+	{"MakeLenIndex", makeLenIndex},
+	{"MakeCapAppend", makeCapAppend},
+	{"SlicesGrow", slicesGrow},
+	{"BulkAppend64", bulkAppend64},
+	{"AppendAll", appendAll},
+}
+
+// DefaultSizes are the slice lengths benchmarked by default.
+var DefaultSizes = []int{10, 100, 1000, 10000, 100000}
+
+func appendNoPrealloc(n int) []int {
+	var s []int
+	for i := 0; i < n; i++ {
+		s = append(s, i)
+	}
+	return s
+}
+
+func makeLenIndex(n int) []int {
+	s := make([]int, n)
+	for i := range s {
+		s[i] = i
+	}
+	return s
+}
+
+func makeCapAppend(n int) []int {
+	s := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		s = append(s, i)
+	}
+	return s
+}
+
+func slicesGrow(n int) []int {
+	var s []int
+	s = slices.Grow(s, n)
+	for i := 0; i < n; i++ {
+		s = append(s, i)
+	}
+	return s
+}
+
+// bulkAppend64 appends whole chunks, as code copying from a reader or a
+// batch API does. There is still no preallocation, but append grows once
+// per chunk at most, and each call copies 64 elements.
+func bulkAppend64(n int) []int {
+	var chunk [64]int
+	var s []int
+	for i := 0; i < n; i += len(chunk) {
+		m := min(len(chunk), n-i)
+		for j := range m {
+			chunk[j] = i + j
+		}
+		s = append(s, chunk[:m]...)
+	}
+	return s
+}
+
+// source is the input of appendAll, built on first use for each size.
+var source = map[int][]int{}
+
+// appendAll copies an existing slice in one append call: append sizes the
+// new array from the length of src.
+func appendAll(n int) []int {
+	src, ok := source[n]
+	if !ok {
+		src = makeLenIndex(n)
+		source[n] = src
+	}
+	return append([]int(nil), src...)
+}
@@ -0,0 +1,43 @@
+package appendbench
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// sink keeps results alive so the compiler cannot drop the work or keep the
+// slice on the stack.
+var sink []int
+
+func TestStrategies(t *testing.T) {
+	for _, n := range append([]int{0, 1, 63, 64, 65}, DefaultSizes...) {
+		want := makeLenIndex(n)
+		for _, st := range Strategies {
+			if got := st.Build(n); !slices.Equal(got, want) {
+				t.Errorf("%s(%d) built the wrong slice", st.Name, n)
+			}
+		}
+	}
+}
+
+// benchmark runs one strategy at every size, as sub-benchmarks named
+// "n=<size>" so that benchsum puts the sizes in rows.
+func benchmark(b *testing.B, build func(n int) []int) {
+	for _, n := range DefaultSizes {
+		b.Run("n="+strconv.Itoa(n), func(b *testing.B) {
+			build(n) // appendAll builds its input on first use
+			b.ReportAllocs()
+			for b.Loop() {
+				sink = build(n)
+			}
+		})
+	}
+}
+
+func BenchmarkAppendNoPrealloc(b *testing.B) { benchmark(b, appendNoPrealloc) }
+func BenchmarkMakeLenIndex(b *testing.B)     { benchmark(b, makeLenIndex) }
+func BenchmarkMakeCapAppend(b *testing.B)    { benchmark(b, makeCapAppend) }
+func BenchmarkSlicesGrow(b *testing.B)       { benchmark(b, slicesGrow) }
+func BenchmarkBulkAppend64(b *testing.B)     { benchmark(b, bulkAppend64) }
+func BenchmarkAppendAll(b *testing.B)        { benchmark(b, appendAll) }
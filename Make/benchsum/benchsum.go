@@ -0,0 +1,198 @@
+// Package benchsum turns "go test -bench" output into markdown comparison
+// tables.
+//
+// Benchmark names are split at their last "/": the part before it is the
+// column (the thing being compared) and the part after it is the row (the
+// case, such as "n=1000"). Names without sub-benchmarks form a single row.
+// For example
+//
+//	BenchmarkAppendNoPrealloc/n=1000-8   300000   4012 ns/op   25208 B/op   12 allocs/op
+//	BenchmarkMakeCapAppend/n=1000-8     1000000   1105 ns/op    8192 B/op    1 allocs/op
+//
+// becomes one row "n=1000" with columns "AppendNoPrealloc" and
+// "MakeCapAppend", in one table per unit (ns/op, B/op, allocs/op). When a
+// benchmark appears several times (go test -count), the mean is used.
+package benchsum
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Result is one benchmark line.
+type Result struct {
+	Column, Row string
+	Iterations  int
+	Values      map[string]float64 // by unit, such as "ns/op"
+}
+
+// Parse reads benchmark lines from r, ignoring everything else.
+func Parse(r io.Reader) ([]Result, error) {
+	var results []Result
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		res, ok := parseLine(sc.Text())
+		if ok {
+			results = append(results, res)
+		}
+	}
+	return results, sc.Err()
+}
+
+// parseLine parses one line of the form
+// "BenchmarkName-8  N  value unit  value unit ...".
+func parseLine(line string) (Result, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 || len(fields)%2 != 0 || !strings.HasPrefix(fields[0], "Benchmark") {
+		return Result{}, false
+	}
+	iterations, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return Result{}, false
+	}
+
+	name := strings.TrimPrefix(fields[0], "Benchmark")
+	if i := strings.LastIndexByte(name, '-'); i >= 0 {
+		if _, err := strconv.Atoi(name[i+1:]); err == nil {
+			name = name[:i] // GOMAXPROCS suffix
+		}
+	}
+	res := Result{Column: name, Iterations: iterations, Values: map[string]float64{}}
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		res.Column, res.Row = name[:i], name[i+1:]
+	}
+
+	for i := 2; i < len(fields); i += 2 {
+		v, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return Result{}, false
+		}
+		res.Values[fields[i+1]] = v
+	}
+	return res, true
+}
+
+// Table is the summary of one unit.
+type Table struct {
+	Unit    string
+	Columns []string // in order of first appearance
+	Rows    []string // in order of first appearance
+	Cells   map[[2]string]float64
+}
+
+// Summarize builds one Table per unit. Units appear in the order ns/op,
+// B/op, allocs/op, then any others in order of first appearance.
+func Summarize(results []Result) []Table {
+	type key struct{ unit, col, row string }
+	sums := map[key]float64{}
+	counts := map[key]int{}
+	var units, cols, rows []string
+	addOnce := func(list *[]string, s string) {
+		if !slices.Contains(*list, s) {
+			*list = append(*list, s)
+		}
+	}
+	for _, u := range []string{"ns/op", "B/op", "allocs/op"} {
+		for _, r := range results {
+			if _, ok := r.Values[u]; ok {
+				addOnce(&units, u)
+				break
+			}
+		}
+	}
+	for _, r := range results {
+		addOnce(&cols, r.Column)
+		addOnce(&rows, r.Row)
+		for u, v := range r.Values {
+			k := key{u, r.Column, r.Row}
+			sums[k] += v
+			counts[k]++
+		}
+	}
+	for _, r := range results {
+		var extra []string
+		for u := range r.Values {
+			if !slices.Contains(units, u) {
+				extra = append(extra, u)
+			}
+		}
+		slices.Sort(extra) // Values is a map: fix the order within a line
+		for _, u := range extra {
+			addOnce(&units, u)
+		}
+	}
+
+	var tables []Table
+	for _, u := range units {
+		t := Table{Unit: u, Columns: cols, Rows: rows, Cells: map[[2]string]float64{}}
+		for _, c := range cols {
+			for _, r := range rows {
+				k := key{u, c, r}
+				if counts[k] > 0 {
+					t.Cells[[2]string{c, r}] = sums[k] / float64(counts[k])
+				}
+			}
+		}
+		tables = append(tables, t)
+	}
+	return tables
+}
+
+// WriteMarkdown writes t as a markdown table. If baseline names a column,
+// every other cell also shows its ratio to the baseline in the same row.
+func (t Table) WriteMarkdown(w io.Writer, baseline string) {
+	fmt.Fprintf(w, "### %s\n\n", t.Unit)
+	fmt.Fprintf(w, "| %s |", "case")
+	for _, c := range t.Columns {
+		fmt.Fprintf(w, " %s |", c)
+	}
+	fmt.Fprint(w, "\n|---|")
+	for range t.Columns {
+		fmt.Fprint(w, "---:|")
+	}
+	fmt.Fprintln(w)
+
+	for _, r := range t.Rows {
+		row := r
+		if row == "" {
+			row = "-"
+		}
+		fmt.Fprintf(w, "| %s |", row)
+		base, hasBase := t.Cells[[2]string{baseline, r}]
+		for _, c := range t.Columns {
+			v, ok := t.Cells[[2]string{c, r}]
+			switch {
+			case !ok:
+				fmt.Fprint(w, " |")
+			case hasBase && c != baseline && base != 0:
+				fmt.Fprintf(w, " %s (%.2fx) |", formatValue(v), v/base)
+			default:
+				fmt.Fprintf(w, " %s |", formatValue(v))
+			}
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w)
+}
+
+// formatValue prints v with thousands separators and at most one decimal
+// for values below 100.
+func formatValue(v float64) string {
+	if r := math.Round(v*10) / 10; r < 100 && r != math.Trunc(r) {
+		return strconv.FormatFloat(r, 'f', 1, 64)
+	}
+	s := strconv.FormatInt(int64(math.Round(v)), 10)
+	var b strings.Builder
+	for i, c := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
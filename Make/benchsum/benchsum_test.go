@@ -0,0 +1,127 @@
+package benchsum
+
+import (
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	const out = `goos: linux
+goarch: amd64
+pkg: Lets-GO/Make/appendbench
+cpu: Some CPU @ 3.00GHz
+BenchmarkAppendNoPrealloc/n=1000-8   	  300000	      4012 ns/op	   25208 B/op	      12 allocs/op
+BenchmarkMakeCapAppend/n=1000-16     	 1000000	      1105 ns/op
+BenchmarkSingle                      	     100	        10.5 ns/op	         3.00 MB/s
+BenchmarkLogs-8
+    appendbench_test.go:12: a log line
+BenchmarkOdd-8                       	     100	        10 ns/op	 extra
+BenchmarkNoCount-8                   	    many	        10 ns/op
+BenchmarkBadValue-8                  	     100	      fast ns/op
+--- FAIL: BenchmarkBroken
+PASS
+ok  	Lets-GO/Make/appendbench	3.2s
+`
+	results, err := Parse(strings.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Result{
+		{"AppendNoPrealloc", "n=1000", 300000, map[string]float64{"ns/op": 4012, "B/op": 25208, "allocs/op": 12}},
+		{"MakeCapAppend", "n=1000", 1000000, map[string]float64{"ns/op": 1105}},
+		{"Single", "", 100, map[string]float64{"ns/op": 10.5, "MB/s": 3}},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d: %+v", len(results), len(want), results)
+	}
+	for i, w := range want {
+		r := results[i]
+		if r.Column != w.Column || r.Row != w.Row || r.Iterations != w.Iterations || !maps.Equal(r.Values, w.Values) {
+			t.Errorf("result %d = %+v, want %+v", i, r, w)
+		}
+	}
+}
+
+func TestSummarizeAveragesRepeats(t *testing.T) {
+	const out = `BenchmarkA/n=1-8   	100	10 ns/op	1 allocs/op
+BenchmarkA/n=1-8   	100	20 ns/op	1 allocs/op
+BenchmarkA/n=1-8   	100	60 ns/op	1 allocs/op
+BenchmarkB/n=1-8   	100	5 ns/op	2 allocs/op	7 widgets/op
+BenchmarkA/n=2-8   	100	8 ns/op
+`
+	results, err := Parse(strings.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables := Summarize(results)
+	var units []string
+	for _, tb := range tables {
+		units = append(units, tb.Unit)
+	}
+	if got := strings.Join(units, " "); got != "ns/op allocs/op widgets/op" {
+		t.Errorf("units = %s", got)
+	}
+	ns := tables[0]
+	if got := ns.Cells[[2]string{"A", "n=1"}]; got != 30 {
+		t.Errorf("mean of A/n=1 = %v, want 30", got)
+	}
+	if got := strings.Join(ns.Columns, " "); got != "A B" {
+		t.Errorf("columns = %s, want A B", got)
+	}
+	if got := strings.Join(ns.Rows, " "); got != "n=1 n=2" {
+		t.Errorf("rows = %s, want n=1 n=2", got)
+	}
+	if _, ok := tables[1].Cells[[2]string{"A", "n=2"}]; ok {
+		t.Error("A/n=2 has an allocs/op cell but reported none")
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	tb := Table{
+		Unit:    "ns/op",
+		Columns: []string{"Slow", "Fast"},
+		Rows:    []string{"n=10", "n=20"},
+		Cells: map[[2]string]float64{
+			{"Slow", "n=10"}: 4000, {"Fast", "n=10"}: 1000,
+			{"Fast", "n=20"}: 12.25,
+		},
+	}
+	var b strings.Builder
+	tb.WriteMarkdown(&b, "Slow")
+	want := `### ns/op
+
+| case | Slow | Fast |
+|---|---:|---:|
+| n=10 | 4,000 | 1,000 (0.25x) |
+| n=20 | | 12.3 |
+
+`
+	if b.String() != want {
+		t.Errorf("WriteMarkdown =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		v    float64
+		want string
+	}{
+		{0, "0"},
+		{7, "7"},
+		{12.34, "12.3"},
+		{99.94, "99.9"},
+		{99.96, "100"},
+		{12.25, "12.3"},
+		{3.04, "3"},
+		{100.4, "100"},
+		{100.5, "101"},
+		{999.5, "1,000"},
+		{1234567, "1,234,567"},
+	}
+	for _, tt := range tests {
+		if got := formatValue(tt.v); got != tt.want {
+			t.Errorf("formatValue(%v) = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Command benchsum reads "go test -bench" output on standard input and
+// prints markdown comparison tables, one per unit.
+//
+//	go test -bench . -benchmem ./Make/appendbench | go run ./Make/benchsum/cmd/benchsum
+//	go test -bench . -benchmem ./... | go run ./Make/benchsum/cmd/benchsum -baseline ""
+//
+// By default every cell is compared with the first column; -baseline picks
+// another one, and -baseline "" turns the ratios off.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"Lets-GO/Make/benchsum"
+)
+
+func main() {
+	baseline := flag.String("baseline", "first", "column to compare against; \"first\" for the first column, \"\" for none")
+	flag.Parse()
+
+	results, err := benchsum.Parse(os.Stdin)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if len(results) == 0 {
+		fmt.Fprintln(os.Stderr, "benchsum: no benchmark lines on standard input")
+		os.Exit(1)
+	}
+	for _, t := range benchsum.Summarize(results) {
+		base := *baseline
+		if base == "first" {
+			base = t.Columns[0]
+		}
+		t.WriteMarkdown(os.Stdout, base)
+	}
+}
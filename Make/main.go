@@ -4,48 +4,68 @@ import (
 	"fmt"
 )
 
+// What do these choices cost? Package appendbench benchmarks every way of
+// building a slice; pipe the results into benchsum for markdown tables:
+//
+//	go test -bench . -benchmem ./Make/appendbench | go run ./Make/benchsum/cmd/benchsum
+//
+// Growing without preallocation allocates once per reallocation (about
+// log n times) and, counting every abandoned array, several times the
+// bytes of the final slice. make(len)+index, make(0, cap)+append,
+// slices.Grow and a single append of a whole slice allocate exactly once.
+// Appending in chunks of 64 skips the reallocations below 64 elements
+// (three fewer allocations), but still reallocates about log n times and
+// copies about as many bytes.
+
 func main() {
 	// 1) make([]int, 5) → length 5, capacity 5
 	x := make([]int, 5)
 	fmt.Println("1) x := make([]int, 5)")
 	printSlice("x", x)
-	fmt.Println("   x[0]..x[4] are valid and all zero-initialized\n")
+	fmt.Println("   x[0]..x[4] are valid and all zero-initialized")
+	fmt.Println()
 
 	// Beginner mistake: thinking append will "fill" those 5 slots
 	fmt.Println("2) Append to x (length 5) with x = append(x, 10)")
 	x = append(x, 10)
 	printSlice("x after append", x)
 	fmt.Println("   Notice: 10 is added AFTER the 5 zeros, not replacing them.")
-	fmt.Println("   Now len=6, cap likely doubled (10 in this example)\n")
+	fmt.Println("   Now len=6, cap likely doubled (10 in this example)")
+	fmt.Println()
 
 	// 2) make with length 5 and capacity 10
 	y := make([]int, 5, 10)
 	fmt.Println("3) y := make([]int, 5, 10)")
 	printSlice("y", y)
 	fmt.Println("   y has 5 elements (all zero), but room (capacity) for 10.")
-	fmt.Println("   You can index y[0]..y[4], and append up to 5 more without realloc.\n")
+	fmt.Println("   You can index y[0]..y[4], and append up to 5 more without realloc.")
+	fmt.Println()
 
 	// Show appending to y
 	y = append(y, 1, 2, 3)
 	printSlice("y after append 1,2,3", y)
-	fmt.Println("   Still same backing array until length exceeds capacity.\n")
+	fmt.Println("   Still same backing array until length exceeds capacity.")
+	fmt.Println()
 
 	// 3) make with length 0 and capacity 10
 	z := make([]int, 0, 10)
 	fmt.Println("4) z := make([]int, 0, 10)")
 	printSlice("z", z)
 	fmt.Println("   z is non-nil, len=0, cap=10. You CANNOT do z[0] yet (out of range).")
-	fmt.Println("   But you CAN append safely without realloc.\n")
+	fmt.Println("   But you CAN append safely without realloc.")
+	fmt.Println()
 
 	// Append to z
 	z = append(z, 5, 6, 7, 8)
 	printSlice("z after append 5,6,7,8", z)
-	fmt.Println("   Now len=4, cap still 10. You used 4 of the reserved slots.\n")
+	fmt.Println("   Now len=4, cap still 10. You used 4 of the reserved slots.")
+	fmt.Println()
 
 	// 4) Invalid example (in comments) – capacity < length
 	fmt.Println("5) Invalid make examples (won't compile, shown as comments):")
 	fmt.Println(`   // bad := make([]int, 5, 3)   // ❌ compile-time error`)
-	fmt.Println("   If you somehow pass a smaller capacity via variable, it will panic at runtime.\n")
+	fmt.Println("   If you somehow pass a smaller capacity via variable, it will panic at runtime.")
+	fmt.Println()
 }
 
 // Helper to print slice content, length, and capacity
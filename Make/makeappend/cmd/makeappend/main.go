@@ -0,0 +1,27 @@
+// Command makeappend reports slices made with a length and then only
+// appended to.
+//
+// Run it on its own:
+//
+//	go run ./Make/makeappend/cmd/makeappend ./...
+//
+// or as a vet tool:
+//
+//	go build -o makeappend ./Make/makeappend/cmd/makeappend
+//	go vet -vettool=$(pwd)/makeappend ./...
+//
+// The Make lesson itself is not reported: it prints x's five zeros before
+// appending, which is the point of the demo.
+//
+// The analyzer's tests run with go test ./Make/makeappend.
+package main
+
+import (
+	"Lets-GO/Make/makeappend"
+
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(makeappend.Analyzer)
+}
@@ -0,0 +1,215 @@
+// Package makeappend defines an analyzer that reports slices made with a
+// length and then only appended to.
+//
+// The Make lesson shows the mistake:
+//
+//	x := make([]int, 5)
+//	x = append(x, 10) // [0 0 0 0 0 10], not [10]
+//
+// make([]T, n) creates n zero elements; append adds after them. When the
+// zeros are never written or read before the first append, they were not
+// wanted: the author meant make([]T, 0, n), which only reserves capacity.
+//
+// To stay clear of intentional zero-fill, the pass reports a local
+// x := make([]T, n) only when:
+//
+//   - n is not the constant 0,
+//   - the first use of x is x = append(x, ...),
+//   - x is never indexed (x[i], even to read), resliced, passed to a
+//     function or method (other than as the slice appended to, or to len
+//     and cap), or has its address taken, and
+//   - x is not referenced from a function literal.
+//
+// A function given x may fill in the zeros, as binary.PutUint32 and
+// io.ReadFull do. An index, even a read after the appends, counts the
+// zeros: with make([]T, 0, n) it would see other elements, or panic.
+//
+// The three-argument form make([]T, n, c) states the length on purpose
+// and is not reported.
+package makeappend
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const doc = `report make([]T, n) followed by appends only
+
+x := make([]T, n) creates n zero elements, and append(x, v) adds v after
+them. When x is only appended to, the zeros are a mistake: the pass
+reports it and suggests make([]T, 0, n), which reserves the capacity
+without the elements.`
+
+// Analyzer reports slices made with a non-zero length and then only
+// appended to.
+var Analyzer = &analysis.Analyzer{
+	Name:     "makeappend",
+	Doc:      doc,
+	URL:      "https://go.dev/ref/spec#Making_slices_maps_and_channels",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	nodeFilter := []ast.Node{(*ast.FuncDecl)(nil)}
+	insp.Preorder(nodeFilter, func(n ast.Node) {
+		fd := n.(*ast.FuncDecl)
+		if fd.Body == nil {
+			return
+		}
+		ast.Inspect(fd.Body, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				if n.Tok == token.DEFINE && len(n.Lhs) == len(n.Rhs) {
+					for i, l := range n.Lhs {
+						if id, ok := l.(*ast.Ident); ok {
+							check(pass, fd.Body, id, n.Rhs[i])
+						}
+					}
+				}
+			case *ast.ValueSpec:
+				if len(n.Names) == len(n.Values) {
+					for i, id := range n.Names {
+						check(pass, fd.Body, id, n.Values[i])
+					}
+				}
+			}
+			return true
+		})
+	})
+	return nil, nil
+}
+
+// check reports id := rhs if rhs is make([]T, n) and id is then only
+// appended to.
+func check(pass *analysis.Pass, body *ast.BlockStmt, id *ast.Ident, rhs ast.Expr) {
+	call, ok := ast.Unparen(rhs).(*ast.CallExpr)
+	if !ok || len(call.Args) != 2 || !isBuiltin(pass, call.Fun, "make") {
+		return
+	}
+	if _, ok := pass.TypesInfo.TypeOf(call.Args[0]).Underlying().(*types.Slice); !ok {
+		return
+	}
+	if tv := pass.TypesInfo.Types[call.Args[1]]; tv.Value != nil && constant.Sign(tv.Value) == 0 {
+		return
+	}
+	v, ok := pass.TypesInfo.Defs[id].(*types.Var)
+	if !ok {
+		return
+	}
+
+	uses := find(pass, body, v)
+	if len(uses) == 0 || !appendsToSelf(pass, v, uses[0]) {
+		return
+	}
+	for _, u := range uses {
+		if !appendOnly(pass, u) {
+			return
+		}
+	}
+
+	n := types.ExprString(call.Args[1])
+	typ := types.ExprString(call.Args[0])
+	pass.Report(analysis.Diagnostic{
+		Pos: call.Pos(),
+		End: call.End(),
+		Message: fmt.Sprintf("%s is made with length %s and then only appended to, so it starts with %s zero elements; use make(%s, 0, %s) to reserve capacity instead",
+			id.Name, n, n, typ, n),
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message: fmt.Sprintf("Reserve capacity: make(%s, 0, %s)", typ, n),
+			TextEdits: []analysis.TextEdit{{
+				Pos:     call.Args[1].Pos(),
+				End:     call.Args[1].Pos(),
+				NewText: []byte("0, "),
+			}},
+		}},
+	})
+}
+
+// use is one reference to the slice variable, with the nodes enclosing
+// it, innermost last.
+type use struct {
+	id    *ast.Ident
+	stack []ast.Node
+}
+
+func (u use) parent() ast.Node { return u.stack[len(u.stack)-1] }
+
+func find(pass *analysis.Pass, body *ast.BlockStmt, v *types.Var) []use {
+	var uses []use
+	var stack []ast.Node
+	ast.Inspect(body, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		if id, ok := n.(*ast.Ident); ok && pass.TypesInfo.Uses[id] == v {
+			uses = append(uses, use{id, append([]ast.Node(nil), stack...)})
+		}
+		stack = append(stack, n)
+		return true
+	})
+	return uses
+}
+
+// appendsToSelf reports whether u is the x in x = append(x, ...).
+func appendsToSelf(pass *analysis.Pass, v *types.Var, u use) bool {
+	as, ok := u.parent().(*ast.AssignStmt)
+	if !ok || as.Tok != token.ASSIGN || len(as.Lhs) != 1 || len(as.Rhs) != 1 || as.Lhs[0] != u.id {
+		return false
+	}
+	call, ok := ast.Unparen(as.Rhs[0]).(*ast.CallExpr)
+	if !ok || !isBuiltin(pass, call.Fun, "append") || len(call.Args) == 0 {
+		return false
+	}
+	arg, ok := ast.Unparen(call.Args[0]).(*ast.Ident)
+	return ok && pass.TypesInfo.Uses[arg] == v
+}
+
+// appendOnly reports whether u leaves the slice's elements alone: it does
+// not index, reslice, pass or take the address of the slice, and is not
+// inside a function literal.
+func appendOnly(pass *analysis.Pass, u use) bool {
+	for _, n := range u.stack {
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
+	}
+	switch parent := u.parent().(type) {
+	case *ast.SliceExpr, *ast.IndexExpr:
+		return false
+	case *ast.UnaryExpr:
+		return parent.Op != token.AND
+	case *ast.CallExpr:
+		// Only append(x, ...) and len(x) or cap(x) are known not to
+		// touch the elements; any other function may fill them in.
+		switch {
+		case isBuiltin(pass, parent.Fun, "append"):
+			return parent.Args[0] == u.id // append(y, x...) reads the zeros
+		case isBuiltin(pass, parent.Fun, "len"), isBuiltin(pass, parent.Fun, "cap"):
+			return true
+		}
+		return false
+	case *ast.SelectorExpr:
+		return false // a method of a slice type may write the elements
+	}
+	return true
+}
+
+func isBuiltin(pass *analysis.Pass, fun ast.Expr, name string) bool {
+	id, ok := ast.Unparen(fun).(*ast.Ident)
+	if !ok {
+		return false
+	}
+	b, ok := pass.TypesInfo.Uses[id].(*types.Builtin)
+	return ok && b.Name() == name
+}
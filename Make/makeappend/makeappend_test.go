@@ -0,0 +1,13 @@
+package makeappend_test
+
+import (
+	"testing"
+
+	"Lets-GO/Make/makeappend"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), makeappend.Analyzer, "makeappend")
+}
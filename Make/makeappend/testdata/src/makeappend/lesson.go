@@ -0,0 +1,37 @@
+package makeappend
+
+import "fmt"
+
+// The Make lesson's beginner mistake, without the printing in between:
+// x ends up as [0 0 0 0 0 10].
+func beginnerMistake() []int {
+	x := make([]int, 5) // want `x is made with length 5 and then only appended to, so it starts with 5 zero elements; use make\(\[\]int, 0, 5\) to reserve capacity instead`
+	x = append(x, 10)
+	return x
+}
+
+// The lesson's fix: length 0, capacity 10.
+func reserved() []int {
+	z := make([]int, 0, 10)
+	z = append(z, 5, 6, 7, 8)
+	return z
+}
+
+// Sizing by the input is the most common form of the mistake.
+func upper(words []string) []string {
+	out := make([]string, len(words)) // want `out is made with length len\(words\) and then only appended to`
+	for _, w := range words {
+		out = append(out, fmt.Sprint(w, "!"))
+	}
+	fmt.Println(len(out))
+	return out
+}
+
+// var declarations are checked too.
+func squares(n int) []int {
+	var sq = make([]int, n) // want `sq is made with length n`
+	for i := range n {
+		sq = append(sq, i*i)
+	}
+	return sq
+}
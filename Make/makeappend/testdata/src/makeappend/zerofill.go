@@ -0,0 +1,120 @@
+package makeappend
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// The Make lesson shows the zeros before appending: they are the point.
+func shown() {
+	x := make([]int, 5)
+	fmt.Println(x)
+	x = append(x, 10)
+	fmt.Println(x)
+}
+
+// Filling by index uses the length.
+func byIndex(n int) []int {
+	s := make([]int, n)
+	for i := range s {
+		s[i] = i
+	}
+	s = append(s, -1)
+	return s
+}
+
+// Counting into zeroed buckets writes through the index.
+func histogram(text string) []int {
+	counts := make([]int, 26)
+	counts = append(counts, 0) // a bucket for everything else
+	for _, c := range text {
+		if c >= 'a' && c <= 'z' {
+			counts[c-'a']++
+		} else {
+			counts[26]++
+		}
+	}
+	return counts
+}
+
+// Reserving a header, appending the payload, then filling the header in.
+func frame(payload []byte) []byte {
+	buf := make([]byte, 4)
+	buf = append(buf, payload...)
+	binary.BigEndian.PutUint32(buf[:4], uint32(len(payload)))
+	return buf
+}
+
+// The header is filled in by a function that is given the whole slice.
+func header(body []byte) []byte {
+	hdr := make([]byte, 4)
+	hdr = append(hdr, body...)
+	binary.LittleEndian.PutUint32(hdr, uint32(len(body)))
+	return hdr
+}
+
+// io.ReadFull overwrites the zeros.
+func readInto(r io.Reader, extra []byte) ([]byte, error) {
+	buf := make([]byte, 16)
+	buf = append(buf, extra...)
+	_, err := io.ReadFull(r, buf)
+	return buf, err
+}
+
+// copy writes into the zeroed elements.
+func padded(src []byte) []byte {
+	dst := make([]byte, 8)
+	dst = append(dst, '\n')
+	copy(dst, src)
+	return dst
+}
+
+// Reading into the buffer happens before any append.
+func readAll(r io.Reader) ([]byte, error) {
+	buf := make([]byte, 512)
+	n, err := r.Read(buf)
+	buf = append(buf[:n], 0)
+	return buf, err
+}
+
+// Length zero is what the fix would write anyway.
+func empty() []int {
+	s := make([]int, 0)
+	s = append(s, 1)
+	return s
+}
+
+// The three-argument form states the length on purpose.
+func explicit() []int {
+	y := make([]int, 5, 10)
+	y = append(y, 1, 2, 3)
+	return y
+}
+
+// A closure might write the elements.
+func closure(n int) []int {
+	s := make([]int, n)
+	s = append(s, 1)
+	set := func(i int) { s[i] = i }
+	set(0)
+	return s
+}
+
+type point struct{ x, y int }
+
+// Setting a field writes the element too.
+func fields(n int) []point {
+	ps := make([]point, n)
+	ps = append(ps, point{})
+	ps[0].x = 1
+	return ps
+}
+
+// Reading by index after the appends counts the zeros as well: with
+// make([]int, 0, 3), x[3] would be out of range.
+func readAfter() int {
+	x := make([]int, 3)
+	x = append(x, 1)
+	return x[3]
+}
@@ -0,0 +1,158 @@
+// Package alias reports whether two slices share a backing array, and
+// exactly which elements they share.
+//
+// Two slices alias when their capacity windows (from element 0 to cap)
+// intersect in memory. That can matter in two ways:
+//
+//   - Both slices see some elements through their lengths: writing through
+//     one changes what the other reads (demoSharedStorage).
+//   - One slice's spare capacity, beyond its length, covers elements the
+//     other can see: the next append to the first slice overwrites them
+//     without reallocating (demoAppendWithSubslice).
+//
+// The positions come from unsafe.SliceData and cap, so the report is about
+// the actual memory, not about how the slices were made.
+package alias
+
+import (
+	"fmt"
+	"strings"
+	"unsafe"
+)
+
+// Range is a half-open range of indexes [Lo, Hi). It is empty when
+// Lo == Hi.
+type Range struct{ Lo, Hi int }
+
+// Empty reports whether r holds no index.
+func (r Range) Empty() bool { return r.Hi <= r.Lo }
+
+func (r Range) String() string { return fmt.Sprintf("[%d:%d]", r.Lo, r.Hi) }
+
+// shift returns r moved by d. Empty ranges stay [0:0].
+func (r Range) shift(d int) Range {
+	if r.Empty() {
+		return Range{}
+	}
+	return Range{r.Lo + d, r.Hi + d}
+}
+
+// Overlap describes how slices a and b share memory. All ranges are empty
+// when Shared is false.
+type Overlap struct {
+	// Shared reports that the capacity windows intersect: a and b use the
+	// same backing array.
+	Shared bool
+
+	// Offset is the index in a of b's element 0, in elements. It is
+	// negative when b starts before a.
+	Offset int
+
+	// Both holds the elements visible through both lengths, as indexes of
+	// a. Subtract Offset for indexes of b.
+	Both Range
+
+	// AppendAClobbersB holds the indexes of a, at or beyond len(a) but
+	// within cap(a), that b can see: append(a, ...) writes over them.
+	AppendAClobbersB Range
+
+	// AppendBClobbersA is the same for append(b, ...), as indexes of b.
+	AppendBClobbersA Range
+}
+
+// Overlaps reports how a and b share memory.
+func Overlaps[T any](a, b []T) Overlap {
+	var zero T
+	size := unsafe.Sizeof(zero)
+	if size == 0 || cap(a) == 0 || cap(b) == 0 {
+		return Overlap{} // zero-size elements occupy no memory to share
+	}
+	pa := uintptr(unsafe.Pointer(unsafe.SliceData(a)))
+	pb := uintptr(unsafe.Pointer(unsafe.SliceData(b)))
+
+	// Offset of b in elements of a. Slices of the same element type that
+	// share an array are always a whole number of elements apart.
+	var off int
+	if pb >= pa {
+		off = int((pb - pa) / size)
+	} else {
+		off = -int((pa - pb) / size)
+	}
+
+	// Everything below is in indexes of a.
+	capA := Range{0, cap(a)}
+	capB := Range{off, off + cap(b)}
+	if intersect(capA, capB).Empty() {
+		return Overlap{}
+	}
+	lenA := Range{0, len(a)}
+	lenB := Range{off, off + len(b)}
+	spareA := Range{len(a), cap(a)}
+	spareB := Range{off + len(b), off + cap(b)}
+
+	return Overlap{
+		Shared:           true,
+		Offset:           off,
+		Both:             intersect(lenA, lenB),
+		AppendAClobbersB: intersect(spareA, lenB),
+		AppendBClobbersA: intersect(spareB, lenA).shift(-off),
+	}
+}
+
+// SharesBacking reports whether a and b use the same backing array.
+func SharesBacking[T any](a, b []T) bool {
+	return Overlaps(a, b).Shared
+}
+
+// intersect returns the intersection of x and y, or an empty range.
+func intersect(x, y Range) Range {
+	r := Range{max(x.Lo, y.Lo), min(x.Hi, y.Hi)}
+	if r.Empty() {
+		return Range{}
+	}
+	return r
+}
+
+// Describe explains how slices a and b, called nameA and nameB, relate. It
+// prints both headers with pointers relative to the lower of the two, so
+// the output does not depend on where the arrays were allocated:
+//
+//	x: ptr=+0 len=4 cap=4
+//	y: ptr=+0 len=2 cap=4
+//	share a backing array, y starts at x[0]
+//	  both see:                x[0:2] (y[0:2])
+//	  append(y) overwrites:    x[2:4] (y[2:4])
+func Describe[T any](nameA string, a []T, nameB string, b []T) string {
+	o := Overlaps(a, b)
+	var sb strings.Builder
+	if !o.Shared {
+		fmt.Fprintf(&sb, "%s: len=%d cap=%d\n%s: len=%d cap=%d\n", nameA, len(a), cap(a), nameB, len(b), cap(b))
+		fmt.Fprintf(&sb, "separate memory: writes and appends to one never affect the other\n")
+		return sb.String()
+	}
+
+	base := min(0, o.Offset)
+	fmt.Fprintf(&sb, "%s: ptr=+%d len=%d cap=%d\n", nameA, -base, len(a), cap(a))
+	fmt.Fprintf(&sb, "%s: ptr=+%d len=%d cap=%d\n", nameB, o.Offset-base, len(b), cap(b))
+	if o.Offset >= 0 {
+		fmt.Fprintf(&sb, "share a backing array, %s starts at %s[%d]\n", nameB, nameA, o.Offset)
+	} else {
+		fmt.Fprintf(&sb, "share a backing array, %s starts at %s[%d]\n", nameA, nameB, -o.Offset)
+	}
+
+	line := func(what string, r Range, in string, other Range, otherName string) {
+		fmt.Fprintf(&sb, "  %-24s %s%s (%s%s)\n", what+":", in, r, otherName, other)
+	}
+	if o.Both.Empty() {
+		fmt.Fprintf(&sb, "  %-24s nothing\n", "both see:")
+	} else {
+		line("both see", o.Both, nameA, o.Both.shift(-o.Offset), nameB)
+	}
+	if !o.AppendAClobbersB.Empty() {
+		line("append("+nameA+") overwrites", o.AppendAClobbersB.shift(-o.Offset), nameB, o.AppendAClobbersB, nameA)
+	}
+	if !o.AppendBClobbersA.Empty() {
+		line("append("+nameB+") overwrites", o.AppendBClobbersA.shift(o.Offset), nameA, o.AppendBClobbersA, nameB)
+	}
+	return sb.String()
+}
@@ -0,0 +1,231 @@
+// Package diagram draws slices and their backing arrays as ASCII art.
+//
+//	array 1
+//	       0   1   2   3
+//	     +---+---+---+---+
+//	     | a | b | c | d |
+//	     +---+---+---+---+
+//	  x  [===============]   len=4 cap=4
+//	  y  [=======|-------]   len=2 cap=4
+//	  z      [===========]   len=3 cap=3
+//
+// Each slice header is a bracket under the cells it can reach: it starts at
+// the header's pointer, "=" covers its length and "-" its spare capacity,
+// the part an append fills in place. Slices whose capacity windows do not
+// meet are drawn under separate arrays.
+//
+// A Diagram remembers what it drew last, so when it is drawn again after a
+// write or an append it marks changed cells with "*" and arrays it has not
+// seen before with "(new)". The output never contains addresses: the same
+// program always draws the same pictures.
+package diagram
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unsafe"
+)
+
+// View is a named slice to draw.
+type View[T any] struct {
+	Name string
+	S    []T
+}
+
+// V returns a View.
+func V[T any](name string, s []T) View[T] { return View[T]{name, s} }
+
+// Diagram draws views of []T and remembers the last drawing.
+type Diagram[T any] struct {
+	prev map[uintptr]string // cell address -> value drawn last time
+
+	// views drawn last time. Holding them keeps their arrays alive, so
+	// the allocator cannot reuse an address for a "new" array and make the
+	// change marks depend on garbage collection timing.
+	views []View[T]
+}
+
+// New returns a Diagram with nothing drawn yet.
+func New[T any]() *Diagram[T] { return &Diagram[T]{} }
+
+// Render draws views once, without marking changes.
+func Render[T any](views ...View[T]) string {
+	return New[T]().Draw(views...)
+}
+
+// array is a run of cells covered by the capacity windows of some views.
+type array[T any] struct {
+	base, end uintptr // addresses of the first cell and one past the last
+	views     []View[T]
+}
+
+// Draw returns the drawing of views. Changes since the previous Draw are
+// marked.
+func (d *Diagram[T]) Draw(views ...View[T]) string {
+	var zero T
+	size := unsafe.Sizeof(zero)
+
+	var b strings.Builder
+	var arrays []*array[T]
+	for _, v := range views {
+		switch {
+		case v.S == nil:
+			fmt.Fprintf(&b, "%s: nil (no backing array)\n", v.Name)
+			continue
+		case cap(v.S) == 0 || size == 0:
+			fmt.Fprintf(&b, "%s: len=%d cap=%d, no memory to draw\n", v.Name, len(v.S), cap(v.S))
+			continue
+		}
+		lo := uintptr(unsafe.Pointer(unsafe.SliceData(v.S)))
+		hi := lo + uintptr(cap(v.S))*size
+		arrays = merge(arrays, &array[T]{base: lo, end: hi, views: []View[T]{v}})
+	}
+	if len(arrays) > 0 && b.Len() > 0 {
+		b.WriteString("\n")
+	}
+
+	seen := map[uintptr]string{}
+	for i, a := range arrays {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		d.drawArray(&b, i+1, a, size, seen)
+	}
+	d.prev = seen
+	d.views = views
+	return trimLines(b.String())
+}
+
+// merge adds a to arrays, combining it with every array it overlaps. The
+// order of first appearance is kept, which keeps the output stable.
+func merge[T any](arrays []*array[T], a *array[T]) []*array[T] {
+	var out []*array[T]
+	var into *array[T]
+	for _, x := range arrays {
+		if x.base < a.end && a.base < x.end {
+			if into == nil {
+				into = x
+				out = append(out, x)
+			}
+			into.base = min(into.base, x.base, a.base)
+			into.end = max(into.end, x.end, a.end)
+			if x != into {
+				into.views = append(into.views, x.views...)
+			}
+			continue
+		}
+		out = append(out, x)
+	}
+	if into == nil {
+		return append(out, a)
+	}
+	into.views = append(into.views, a.views...)
+	return out
+}
+
+func (d *Diagram[T]) drawArray(b *strings.Builder, n int, a *array[T], size uintptr, seen map[uintptr]string) {
+	cells := int((a.end - a.base) / size)
+
+	// Values: read each cell through a view whose capacity covers it.
+	vals := make([]string, cells)
+	for _, v := range a.views {
+		full := v.S[:cap(v.S)]
+		start := int((uintptr(unsafe.Pointer(unsafe.SliceData(v.S))) - a.base) / size)
+		for i, x := range full {
+			vals[start+i] = fmt.Sprint(x)
+		}
+	}
+	width := 3
+	for _, s := range vals {
+		width = max(width, len(s)+2)
+	}
+	nameWidth := 1
+	for _, v := range a.views {
+		nameWidth = max(nameWidth, len(v.Name))
+	}
+	indent := strings.Repeat(" ", nameWidth+4)
+
+	isNew := d.prev != nil
+	changed := make([]bool, cells)
+	for i, s := range vals {
+		addr := a.base + uintptr(i)*size
+		if old, ok := d.prev[addr]; ok {
+			isNew = false
+			changed[i] = old != s
+		}
+		seen[addr] = s
+	}
+
+	title := fmt.Sprintf("array %d", n)
+	if isNew {
+		title += " (new)"
+	}
+	b.WriteString(title + "\n")
+
+	// Index row, border, values, border.
+	b.WriteString(indent)
+	for i := range cells {
+		fmt.Fprintf(b, " %s", center(fmt.Sprint(i), width))
+	}
+	b.WriteString("\n")
+	border := indent + "+" + strings.Repeat(strings.Repeat("-", width)+"+", cells) + "\n"
+	b.WriteString(border)
+	b.WriteString(indent + "|")
+	for _, s := range vals {
+		fmt.Fprintf(b, "%s|", center(s, width))
+	}
+	b.WriteString("\n")
+	b.WriteString(border)
+	if slices.Contains(changed, true) {
+		b.WriteString(indent)
+		for _, c := range changed {
+			mark := ""
+			if c {
+				mark = "*"
+			}
+			fmt.Fprintf(b, " %s", center(mark, width))
+		}
+		b.WriteString("   * changed\n")
+	}
+
+	// One bracket per view.
+	for _, v := range a.views {
+		start := int((uintptr(unsafe.Pointer(unsafe.SliceData(v.S))) - a.base) / size)
+		var line strings.Builder
+		line.WriteString(strings.Repeat(" ", start*(width+1)))
+		line.WriteString("[")
+		for i := range cap(v.S) {
+			fill := "="
+			if i >= len(v.S) {
+				fill = "-"
+			}
+			line.WriteString(strings.Repeat(fill, width))
+			switch {
+			case i == cap(v.S)-1:
+				line.WriteString("]")
+			case i == len(v.S)-1:
+				line.WriteString("|")
+			default:
+				line.WriteString(fill)
+			}
+		}
+		padding := max(0, cells*(width+1)+1-line.Len())
+		fmt.Fprintf(b, "  %-*s  %s%s   len=%d cap=%d\n", nameWidth, v.Name, line.String(), strings.Repeat(" ", padding), len(v.S), cap(v.S))
+	}
+}
+
+// trimLines removes trailing spaces from every line of s.
+func trimLines(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// center pads s with spaces on both sides to width.
+func center(s string, width int) string {
+	left := (width - len(s)) / 2
+	return strings.Repeat(" ", max(left, 0)) + s + strings.Repeat(" ", max(width-len(s)-left, 0))
+}
@@ -0,0 +1,35 @@
+package diagram_test
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"Lets-GO/Slicing/diagram"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/diagrams.golden")
+
+// TestGolden compares WriteLesson, which demoDiagrams in the Slicing lesson
+// prints, with testdata/diagrams.golden. After an intended change,
+// rewrite it with go test ./Slicing/diagram -update.
+func TestGolden(t *testing.T) {
+	var got bytes.Buffer
+	diagram.WriteLesson(&got)
+
+	golden := filepath.Join("testdata", "diagrams.golden")
+	if *update {
+		if err := os.WriteFile(golden, got.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Bytes(), want) {
+		t.Errorf("diagrams differ from %s:\n--- got\n%s--- want\n%s", golden, got.Bytes(), want)
+	}
+}
@@ -0,0 +1,51 @@
+package diagram
+
+import (
+	"fmt"
+	"io"
+)
+
+// WriteLesson draws the Slicing lesson's demoSharedStorage,
+// demoAppendWithSubslice and the x[:2:2] fix step by step, each drawing
+// titled with the statements that led to it.
+func WriteLesson(w io.Writer) {
+	d := New[string]()
+	step := func(title string, views ...View[string]) {
+		fmt.Fprintf(w, "\n-- %s\n", title)
+		fmt.Fprint(w, d.Draw(views...))
+	}
+
+	x := []string{"a", "b", "c", "d"}
+	y := x[:2]
+	z := x[1:]
+	step("x := []string{\"a\", \"b\", \"c\", \"d\"}; y := x[:2]; z := x[1:]",
+		V("x", x), V("y", y), V("z", z))
+
+	x[1] = "y"
+	y[0] = "x"
+	z[1] = "z"
+	step("x[1] = \"y\"; y[0] = \"x\"; z[1] = \"z\"  (one array, three views)",
+		V("x", x), V("y", y), V("z", z))
+
+	x = []string{"a", "b", "c", "d"}
+	y = x[:2]
+	step("x = []string{\"a\", \"b\", \"c\", \"d\"}; y = x[:2]",
+		V("x", x), V("y", y))
+
+	y = append(y, "z")
+	step("y = append(y, \"z\")  (fits in y's capacity: overwrites x[2])",
+		V("x", x), V("y", y))
+
+	y = append(y, "w", "v")
+	step("y = append(y, \"w\", \"v\")  (no room left: y moves to a new array)",
+		V("x", x), V("y", y))
+
+	x = []string{"a", "b", "c", "d"}
+	y = x[:2:2]
+	step("x = []string{\"a\", \"b\", \"c\", \"d\"}; y = x[:2:2]  (cap limited to len)",
+		V("x", x), V("y", y))
+
+	y = append(y, "z")
+	step("y = append(y, \"z\")  (no spare capacity: new array, x untouched)",
+		V("x", x), V("y", y))
+}
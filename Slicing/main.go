@@ -2,6 +2,11 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"os"
+
+	"Lets-GO/Slicing/alias"
+	"Lets-GO/Slicing/diagram"
 )
 
 func main() {
@@ -12,6 +17,7 @@ func main() {
 	demoCopy()
 	demoArraySliceConversion()
 	demoStringByteRune()
+	demoDiagrams(os.Stdout)
 }
 
 // ----------------------------------------
@@ -55,6 +61,9 @@ func demoSharedStorage() {
 	fmt.Println("x:", x) // [x y z d]
 	fmt.Println("y:", y) // [x y]
 	fmt.Println("z:", z) // [y z d]
+
+	// alias.Describe checks the sharing from the slice headers themselves.
+	fmt.Print(alias.Describe("y", y, "z", z))
 	fmt.Println()
 }
 
@@ -69,6 +78,7 @@ func demoAppendWithSubslice() {
 	y := x[:2]                        // len=2, cap=4
 
 	fmt.Println("cap(x), cap(y):", cap(x), cap(y))
+	fmt.Print(alias.Describe("x", x, "y", y))
 
 	// y has capacity 4, so appending will still write into
 	// x's underlying array (no reallocation yet).
@@ -100,6 +110,9 @@ func demoFullSliceExpression() {
 	fmt.Println("x:", x)
 	fmt.Println("len/cap y:", len(y), cap(y))
 	fmt.Println("len/cap z:", len(z), cap(z))
+	fmt.Print(alias.Describe("x", x, "y", y))
+	fmt.Println("y shares x's array, but its capacity ends where its length does:")
+	fmt.Println("append(y) cannot overwrite anything of x.")
 
 	// These appends will *force* new backing arrays,
 	// because y and z are already at full capacity.
@@ -110,6 +123,7 @@ func demoFullSliceExpression() {
 	fmt.Println("x:", x) // still uses original backing array
 	fmt.Println("y:", y) // separate backing array now
 	fmt.Println("z:", z) // separate backing array now
+	fmt.Println("x and y share a backing array:", alias.SharesBacking(x, y))
 	fmt.Println()
 }
 
@@ -231,3 +245,19 @@ func demoStringByteRune() {
 
 	fmt.Println()
 }
+
+// ----------------------------------------
+// 8. Pictures: headers and backing arrays
+// ----------------------------------------
+
+// demoDiagrams replays demoSharedStorage, demoAppendWithSubslice and the
+// x[:2:2] fix as diagrams. A diagram.Diagram marks cells that changed since
+// it last drew them, and arrays it has not seen before.
+//
+// The output contains no addresses, so go test ./Slicing/diagram checks it
+// against a golden file.
+func demoDiagrams(w io.Writer) {
+	fmt.Fprintln(w, "== demoDiagrams ==")
+	diagram.WriteLesson(w)
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,27 @@
+// Command subappend reports appends through a subslice that overwrite the
+// slice it was cut from.
+//
+// Run it on its own:
+//
+//	go run ./Slicing/subappend/cmd/subappend ./...
+//
+// or as a vet tool:
+//
+//	go build -o subappend ./Slicing/subappend/cmd/subappend
+//	go vet -vettool=$(pwd)/subappend ./...
+//
+// On this repository it reports demoAppendWithSubslice and demoDiagrams in
+// Slicing, which overwrite x[2] on purpose.
+//
+// The analyzer's tests run with go test ./Slicing/subappend.
+package main
+
+import (
+	"Lets-GO/Slicing/subappend"
+
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(subappend.Analyzer)
+}
@@ -0,0 +1,323 @@
+// Package subappend defines an analyzer that reports appends through a
+// subslice that overwrite elements of the slice it was cut from.
+//
+// demoAppendWithSubslice in the Slicing lesson shows the bug:
+//
+//	x := []string{"a", "b", "c", "d"}
+//	y := x[:2]          // len 2, but cap 4: x's array
+//	y = append(y, "z")  // fits, so it writes x[2]
+//	fmt.Println(x)      // [a b z d]
+//
+// The pass reports append(y, ...) when y was last assigned a two-index
+// slice expression x[lo:hi] (no capacity limit) whose hi is not len(x),
+// x has not been reassigned since, and x is read after the append. It
+// also reports append(x[lo:hi], ...) written directly. The suggested fix
+// is the full slice expression x[lo:hi:hi], which makes the append copy to
+// a new array instead.
+package subappend
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const doc = `report appends through a subslice that overwrite the parent slice
+
+After y := x[:2], y shares x's backing array and keeps x's spare capacity,
+so append(y, v) writes v into x[2] when it fits. The pass reports such an
+append when x is read afterwards, and suggests x[:2:2], which caps y at
+its length so that append copies instead.`
+
+// Analyzer reports appends through uncapped subslices of slices that are
+// read afterwards.
+var Analyzer = &analysis.Analyzer{
+	Name:     "subappend",
+	Doc:      doc,
+	URL:      "https://go.dev/ref/spec#Slice_expressions",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	nodeFilter := []ast.Node{(*ast.FuncDecl)(nil)}
+	insp.Preorder(nodeFilter, func(n ast.Node) {
+		fd := n.(*ast.FuncDecl)
+		if fd.Body == nil {
+			return
+		}
+		f := collect(pass, fd.Body)
+		for _, call := range f.appends {
+			check(pass, f, call)
+		}
+	})
+	return nil, nil
+}
+
+// assignment is one assignment to a variable. It takes effect at end, after
+// its right-hand side has been evaluated.
+type assignment struct {
+	end token.Pos
+	rhs ast.Expr // nil when the value is not a single expression
+}
+
+// facts are the assignments, reads and appends of one function body,
+// closures included, in source order.
+type facts struct {
+	assigns map[*types.Var][]assignment
+	reads   map[*types.Var][]*ast.Ident
+	appends []*ast.CallExpr
+}
+
+func collect(pass *analysis.Pass, body *ast.BlockStmt) *facts {
+	f := &facts{
+		assigns: map[*types.Var][]assignment{},
+		reads:   map[*types.Var][]*ast.Ident{},
+	}
+	lhs := map[*ast.Ident]bool{}
+	record := func(id *ast.Ident, end token.Pos, rhs ast.Expr) {
+		if v, ok := pass.TypesInfo.ObjectOf(id).(*types.Var); ok {
+			f.assigns[v] = append(f.assigns[v], assignment{end, rhs})
+		}
+	}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for i, l := range n.Lhs {
+				id, ok := ast.Unparen(l).(*ast.Ident)
+				if !ok {
+					continue
+				}
+				lhs[id] = true
+				var rhs ast.Expr // nil for x += ... and for v, ok := f()
+				if (n.Tok == token.ASSIGN || n.Tok == token.DEFINE) && len(n.Lhs) == len(n.Rhs) {
+					rhs = n.Rhs[i]
+				}
+				record(id, n.End(), rhs)
+			}
+		case *ast.ValueSpec:
+			for i, id := range n.Names {
+				lhs[id] = true
+				var rhs ast.Expr
+				if len(n.Names) == len(n.Values) {
+					rhs = n.Values[i]
+				}
+				record(id, n.End(), rhs)
+			}
+		case *ast.RangeStmt:
+			for _, e := range []ast.Expr{n.Key, n.Value} {
+				if id, ok := e.(*ast.Ident); ok {
+					lhs[id] = true
+					record(id, n.X.End(), nil)
+				}
+			}
+		case *ast.CallExpr:
+			if isBuiltin(pass, n.Fun, "append") && len(n.Args) > 0 {
+				f.appends = append(f.appends, n)
+			}
+		case *ast.Ident:
+			if lhs[n] {
+				return true
+			}
+			if v, ok := pass.TypesInfo.Uses[n].(*types.Var); ok {
+				f.reads[v] = append(f.reads[v], n)
+			}
+		}
+		return true
+	})
+	return f
+}
+
+// check reports call if it appends through an uncapped subslice of a slice
+// that is read afterwards.
+func check(pass *analysis.Pass, f *facts, call *ast.CallExpr) {
+	first := ast.Unparen(call.Args[0])
+	via := "" // the variable appended to, if not the slice expression itself
+	se, ok := first.(*ast.SliceExpr)
+	if !ok {
+		id, ok := first.(*ast.Ident)
+		if !ok {
+			return
+		}
+		y, ok := pass.TypesInfo.Uses[id].(*types.Var)
+		if !ok {
+			return
+		}
+		last := lastAssignBefore(f.assigns[y], call.Pos())
+		if last == nil || last.rhs == nil {
+			return
+		}
+		if se, ok = ast.Unparen(last.rhs).(*ast.SliceExpr); !ok {
+			return
+		}
+		via = id.Name
+	}
+	if se.High == nil || se.Slice3 {
+		return
+	}
+	xid, ok := ast.Unparen(se.X).(*ast.Ident)
+	if !ok {
+		return
+	}
+	x, ok := pass.TypesInfo.Uses[xid].(*types.Var)
+	if !ok || !hasBackingArray(x.Type()) || isLenOf(pass, se.High, x) {
+		return
+	}
+
+	// x must still be the same array at the append...
+	for _, a := range f.assigns[x] {
+		if a.end >= se.End() && a.end < call.Pos() {
+			return
+		}
+	}
+	// ...and be read before it is next reassigned.
+	read := firstReadAfter(f, x, call.End())
+	if read == nil {
+		return
+	}
+
+	subject := render(se)
+	if via != "" {
+		subject = via
+	}
+	d := analysis.Diagnostic{
+		Pos: call.Pos(),
+		End: call.End(),
+		Message: fmt.Sprintf("append to %s may overwrite elements of %s: %s has no capacity limit, so it shares %s's spare capacity",
+			subject, xid.Name, render(se), xid.Name),
+		Related: []analysis.RelatedInformation{
+			{Pos: se.Pos(), End: se.End(), Message: "subslice taken here"},
+			{Pos: read.Pos(), End: read.End(), Message: xid.Name + " read here"},
+		},
+	}
+	if isSimple(pass, se.High) {
+		hi := render(se.High)
+		d.SuggestedFixes = []analysis.SuggestedFix{{
+			Message: fmt.Sprintf("Limit the capacity with a full slice expression %s", fullSlice(se, hi)),
+			TextEdits: []analysis.TextEdit{{
+				Pos:     se.Rbrack,
+				End:     se.Rbrack,
+				NewText: []byte(":" + hi),
+			}},
+		}}
+	}
+	pass.Report(d)
+}
+
+// lastAssignBefore returns the last assignment that completed before pos.
+func lastAssignBefore(as []assignment, pos token.Pos) *assignment {
+	var last *assignment
+	for i := range as {
+		if as[i].end <= pos && (last == nil || as[i].end > last.end) {
+			last = &as[i]
+		}
+	}
+	return last
+}
+
+// firstReadAfter returns the first read of x after pos, unless x is
+// reassigned before it.
+func firstReadAfter(f *facts, x *types.Var, pos token.Pos) *ast.Ident {
+	var read *ast.Ident
+	for _, id := range f.reads[x] {
+		if id.Pos() > pos && (read == nil || id.Pos() < read.Pos()) {
+			read = id
+		}
+	}
+	if read == nil {
+		return nil
+	}
+	for _, a := range f.assigns[x] {
+		if a.end > pos && a.end < read.Pos() {
+			return nil
+		}
+	}
+	return read
+}
+
+// hasBackingArray reports whether slicing a value of type t shares its
+// memory: slices, arrays and pointers to arrays.
+func hasBackingArray(t types.Type) bool {
+	switch t := t.Underlying().(type) {
+	case *types.Slice, *types.Array:
+		return true
+	case *types.Pointer:
+		_, ok := t.Elem().Underlying().(*types.Array)
+		return ok
+	}
+	return false
+}
+
+// isLenOf reports whether hi is provably the length of x: len(x) of the
+// same variable, or a constant equal to the length of x's array type.
+// x[lo:len(x)] ends where x does, like x[lo:], so appends cannot touch
+// x's elements.
+func isLenOf(pass *analysis.Pass, hi ast.Expr, x *types.Var) bool {
+	hi = ast.Unparen(hi)
+	if call, ok := hi.(*ast.CallExpr); ok && isBuiltin(pass, call.Fun, "len") && len(call.Args) == 1 {
+		id, ok := ast.Unparen(call.Args[0]).(*ast.Ident)
+		return ok && pass.TypesInfo.Uses[id] == x
+	}
+	tv, ok := pass.TypesInfo.Types[hi]
+	if !ok || tv.Value == nil {
+		return false
+	}
+	t := x.Type().Underlying()
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem().Underlying()
+	}
+	arr, ok := t.(*types.Array)
+	if !ok {
+		return false
+	}
+	n, exact := constant.Int64Val(tv.Value)
+	return exact && n == arr.Len()
+}
+
+// fullSlice renders se with hi added as its capacity limit.
+func fullSlice(se *ast.SliceExpr, hi string) string {
+	lo := ""
+	if se.Low != nil {
+		lo = render(se.Low)
+	}
+	return fmt.Sprintf("%s[%s:%s:%s]", render(se.X), lo, hi, hi)
+}
+
+// isSimple reports whether e can be repeated without side effects: an
+// identifier, a constant, or the built-in len or cap of one.
+func isSimple(pass *analysis.Pass, e ast.Expr) bool {
+	switch e := ast.Unparen(e).(type) {
+	case *ast.Ident, *ast.BasicLit:
+		return true
+	case *ast.BinaryExpr:
+		return isSimple(pass, e.X) && isSimple(pass, e.Y)
+	case *ast.CallExpr:
+		if (isBuiltin(pass, e.Fun, "len") || isBuiltin(pass, e.Fun, "cap")) && len(e.Args) == 1 {
+			return isSimple(pass, e.Args[0])
+		}
+	}
+	return false
+}
+
+func isBuiltin(pass *analysis.Pass, fun ast.Expr, name string) bool {
+	id, ok := ast.Unparen(fun).(*ast.Ident)
+	if !ok {
+		return false
+	}
+	b, ok := pass.TypesInfo.Uses[id].(*types.Builtin)
+	return ok && b.Name() == name
+}
+
+// render returns the source text of e.
+func render(e ast.Expr) string {
+	return types.ExprString(e)
+}
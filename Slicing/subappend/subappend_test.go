@@ -0,0 +1,13 @@
+package subappend_test
+
+import (
+	"testing"
+
+	"Lets-GO/Slicing/subappend"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), subappend.Analyzer, "subappend")
+}
@@ -0,0 +1,59 @@
+package subappend
+
+import "fmt"
+
+// demoAppendWithSubslice from the Slicing lesson: y keeps x's capacity, so
+// the append writes "z" into x[2].
+func appendWithSubslice() {
+	x := []string{"a", "b", "c", "d"}
+	y := x[:2]
+
+	y = append(y, "z") // want `append to y may overwrite elements of x: x\[:2\] has no capacity limit, so it shares x's spare capacity`
+
+	fmt.Println("x:", x)
+	fmt.Println("y:", y)
+}
+
+// The fix from demoFullSliceExpression: y's capacity ends at its length.
+func fullSliceExpression() {
+	x := make([]string, 0, 5)
+	x = append(x, "a", "b", "c", "d")
+
+	y := x[:2:2]
+	z := x[2:4:4]
+	y = append(y, "i", "j", "k")
+	z = append(z, "l")
+
+	fmt.Println(x, y, z)
+}
+
+// Appending to the slice expression directly has the same problem.
+func direct(x []int, n int) []int {
+	head := append(x[1:n], 0) // want `append to x\[1:n\] may overwrite elements of x`
+	fmt.Println(x)
+	return head
+}
+
+// An array variable is overwritten the same way.
+func fromArray() {
+	var arr [4]int
+	s := arr[:1]
+	s = append(s, 9) // want `append to s may overwrite elements of arr`
+	fmt.Println(arr, s)
+}
+
+// Only the first append after the subslice is reported: y is already
+// the append's result afterwards.
+func appendTwice(x []int) {
+	y := x[:1]
+	y = append(y, 1) // want `append to y may overwrite elements of x`
+	y = append(y, 2)
+	fmt.Println(x, y)
+}
+
+// len of a different slice proves nothing.
+func toOtherLen(x, z []int) {
+	y := x[:len(z)]
+	y = append(y, 1) // want `append to y may overwrite elements of x`
+	fmt.Println(x, y)
+}
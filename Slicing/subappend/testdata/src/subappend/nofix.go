@@ -0,0 +1,90 @@
+package subappend
+
+import "fmt"
+
+// A high bound with a side effect cannot be repeated: reported, no fix.
+func highCall(x []int, next func() int) {
+	y := x[:next()]
+	y = append(y, 1) // want `append to y may overwrite elements of x`
+	fmt.Println(x, y)
+}
+
+// A local len is an ordinary call that may have side effects: reported,
+// no fix.
+func shadowedLen(x []int) {
+	len := func(s []int) int { fmt.Println("len"); return 2 }
+	y := x[:len(x)-1]
+	y = append(y, 1) // want `append to y may overwrite elements of x`
+	fmt.Println(x, y)
+}
+
+// x is never read after the append: nothing to overwrite.
+func parentDead(x []int) []int {
+	y := x[:2]
+	y = append(y, 1)
+	return y
+}
+
+// x is reassigned before the append, so y no longer aliases it.
+func parentReplaced(x []int) {
+	y := x[:2]
+	x = make([]int, 4)
+	y = append(y, 1)
+	fmt.Println(x, y)
+}
+
+// x is reassigned after the append, before it is read again.
+func parentReassignedAfter(x []int) {
+	y := x[:2]
+	y = append(y, 1)
+	x = nil
+	fmt.Println(x, y)
+}
+
+// x[lo:] already ends where x does: appends cannot touch x's elements.
+func tail(x []int) {
+	y := x[1:]
+	y = append(y, 1)
+	fmt.Println(x, y)
+}
+
+// x[:len(x)] ends where x does too.
+func toLen(x []int) {
+	y := x[:len(x)]
+	y = append(y, 1)
+	fmt.Println(x, y)
+}
+
+// So does x[:4] of a [4]int.
+func toArrayLen(x *[4]int) {
+	y := x[:4]
+	y = append(y, 1)
+	fmt.Println(x, y)
+}
+
+// y was reset to a fresh slice before the append.
+func reset(x []int) {
+	y := x[:2]
+	fmt.Println(y)
+	y = []int{}
+	y = append(y, 1)
+	fmt.Println(x, y)
+}
+
+// Strings cannot be appended to, and converted bytes are a copy.
+func fromString(s string) {
+	b := []byte(s[:2])
+	b = append(b, '!')
+	fmt.Println(s, string(b))
+}
+
+// Reusing a buffer reslices it onto itself: there is no other slice to
+// overwrite.
+func reuse(rows [][]int) {
+	var buf []int
+	for _, r := range rows {
+		buf = buf[:0]
+		buf = append(buf, r...)
+		fmt.Println(buf)
+	}
+}
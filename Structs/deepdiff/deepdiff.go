@@ -0,0 +1,346 @@
+// Package deepdiff compares two values field by field and says what
+// changed, where == only says whether anything did:
+//
+//	people[2].pet: "cat" -> "dog"
+//	people[3]: added {name:"Eve" age:30 pet:""}
+//	scores["bob"]: removed 7
+//
+// Diff walks structs, arrays, slices, maps, pointers and interfaces, and
+// reports a Change for every leaf that differs, for every element or map
+// entry only one side has, and for every interface whose dynamic type
+// changed. Paths are written as in Go: fields with dots, indexes and map
+// keys in brackets. Pointers are followed without showing up in the path.
+//
+// Unexported fields are compared too, so Diff works on the unexported
+// structs of the lessons; Options can skip them, skip fields by name and
+// treat floats within a tolerance as equal. With no options, Diff reports
+// no changes when reflect.DeepEqual reports true, and at least one when
+// it reports false.
+package deepdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/cmplx"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// Kind says how a value changed.
+type Kind int
+
+const (
+	Modified    Kind = iota // the value is different
+	Added                   // only the new value has the element or map entry
+	Removed                 // only the old value has the element or map entry
+	TypeChanged             // an interface holds a value of a different type
+)
+
+var kindNames = [...]string{
+	Modified:    "modified",
+	Added:       "added",
+	Removed:     "removed",
+	TypeChanged: "type changed",
+}
+
+func (k Kind) String() string { return kindNames[k] }
+
+// MarshalText encodes k as its name, for JSON.
+func (k Kind) MarshalText() ([]byte, error) { return []byte(k.String()), nil }
+
+// Change is one difference between the old and the new value.
+type Change struct {
+	Path string // "" for the values themselves
+	Kind Kind
+	Old  any // nil when Kind is Added
+	New  any // nil when Kind is Removed
+}
+
+// String formats c as one line: "path: old -> new", or "path: added new".
+func (c Change) String() string {
+	path := c.Path
+	if path == "" {
+		path = "(value)"
+	}
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("%s: added %s", path, Format(c.New))
+	case Removed:
+		return fmt.Sprintf("%s: removed %s", path, Format(c.Old))
+	case TypeChanged:
+		return fmt.Sprintf("%s: type changed: %s (%T) -> %s (%T)", path, Format(c.Old), c.Old, Format(c.New), c.New)
+	}
+	return fmt.Sprintf("%s: %s -> %s", path, Format(c.Old), Format(c.New))
+}
+
+// Options controls Diff.
+type Options struct {
+	// IgnoreFields skips struct fields, given as "name" to skip the field
+	// in every struct type or as "Type.name" for one type only.
+	IgnoreFields []string
+
+	// FloatTolerance treats floats (and complex numbers) that differ by at
+	// most this much as equal.
+	FloatTolerance float64
+
+	// IgnoreUnexported skips unexported fields, e.g. caches and mutexes.
+	IgnoreUnexported bool
+}
+
+// Diff compares a with b using no options.
+func Diff(a, b any) []Change { return Options{}.Diff(a, b) }
+
+// Diff returns the changes that turn a into b, in the order of the fields,
+// elements and sorted map keys they are found at.
+func (o Options) Diff(a, b any) []Change {
+	d := &differ{opts: o, visited: map[visit]bool{}}
+	d.diff("", addressable(reflect.ValueOf(a)), addressable(reflect.ValueOf(b)))
+	return d.changes
+}
+
+type differ struct {
+	opts    Options
+	changes []Change
+	visited map[visit]bool
+}
+
+// visit is a pair of pointers, maps or slices being compared. Meeting it
+// again means the values are cyclic; the second meeting adds nothing.
+// Slices of one array with different lengths are different values, so a
+// slice's length is part of its visit.
+type visit struct {
+	a, b       unsafe.Pointer
+	aLen, bLen int
+	typ        reflect.Type
+}
+
+func (d *differ) add(path string, kind Kind, a, b reflect.Value) {
+	c := Change{Path: path, Kind: kind}
+	if a.IsValid() {
+		c.Old = a.Interface()
+	}
+	if b.IsValid() {
+		c.New = b.Interface()
+	}
+	d.changes = append(d.changes, c)
+}
+
+// diff compares a and b, which have the same type or are invalid (from a
+// nil interface), and can be read with Interface even when they were
+// reached through unexported fields.
+func (d *differ) diff(path string, a, b reflect.Value) {
+	switch {
+	case !a.IsValid() || !b.IsValid():
+		if a.IsValid() != b.IsValid() {
+			d.add(path, Modified, a, b)
+		}
+		return
+	case a.Type() != b.Type():
+		d.add(path, TypeChanged, a, b)
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				d.add(path, Modified, a, b)
+			}
+			return
+		}
+		if a.UnsafePointer() == b.UnsafePointer() && (a.Kind() != reflect.Slice || a.Len() == b.Len()) {
+			return // the same memory, as reflect.DeepEqual assumes
+		}
+		v := visit{a: a.UnsafePointer(), b: b.UnsafePointer(), typ: a.Type()}
+		if a.Kind() == reflect.Slice {
+			v.aLen, v.bLen = a.Len(), b.Len()
+		}
+		if d.visited[v] {
+			return
+		}
+		d.visited[v] = true
+	}
+
+	switch a.Kind() {
+	case reflect.Pointer:
+		d.diff(path, a.Elem(), b.Elem())
+
+	case reflect.Interface:
+		ea, eb := a.Elem(), b.Elem()
+		if ea.IsValid() && eb.IsValid() && ea.Type() != eb.Type() {
+			d.add(path, TypeChanged, ea, eb)
+			return
+		}
+		d.diff(path, addressable(ea), addressable(eb))
+
+	case reflect.Struct:
+		t := a.Type()
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if d.ignore(t, f) {
+				continue
+			}
+			d.diff(join(path, f.Name), readable(a.Field(i)), readable(b.Field(i)))
+		}
+
+	case reflect.Array:
+		for i := range a.Len() {
+			d.diff(index(path, i), a.Index(i), b.Index(i))
+		}
+
+	case reflect.Slice:
+		for i := range min(a.Len(), b.Len()) {
+			d.diff(index(path, i), a.Index(i), b.Index(i))
+		}
+		for i := b.Len(); i < a.Len(); i++ {
+			d.add(index(path, i), Removed, a.Index(i), reflect.Value{})
+		}
+		for i := a.Len(); i < b.Len(); i++ {
+			d.add(index(path, i), Added, reflect.Value{}, b.Index(i))
+		}
+
+	case reflect.Map:
+		// Walk the entries rather than look up each key in both maps: a
+		// NaN key is never found, not even in its own map.
+		type entry struct {
+			k, v reflect.Value
+			old  bool // from a
+		}
+		var entries []entry
+		for k, v := range a.Seq2() {
+			entries = append(entries, entry{k, v, true})
+		}
+		for k, v := range b.Seq2() {
+			entries = append(entries, entry{k, v, false})
+		}
+		slices.SortStableFunc(entries, func(x, y entry) int { return strings.Compare(Format(x.k.Interface()), Format(y.k.Interface())) })
+		for _, e := range entries {
+			p := path + "[" + Format(e.k.Interface()) + "]"
+			switch {
+			case e.old:
+				if vb := b.MapIndex(e.k); vb.IsValid() {
+					d.diff(p, addressable(e.v), addressable(vb))
+				} else {
+					d.add(p, Removed, e.v, reflect.Value{})
+				}
+			case !a.MapIndex(e.k).IsValid():
+				d.add(p, Added, reflect.Value{}, e.v)
+			}
+		}
+
+	case reflect.Float32, reflect.Float64:
+		x, y := a.Float(), b.Float()
+		if x != y && !(d.opts.FloatTolerance > 0 && math.Abs(x-y) <= d.opts.FloatTolerance) {
+			d.add(path, Modified, a, b)
+		}
+
+	case reflect.Complex64, reflect.Complex128:
+		x, y := a.Complex(), b.Complex()
+		if x != y && !(d.opts.FloatTolerance > 0 && cmplx.Abs(x-y) <= d.opts.FloatTolerance) {
+			d.add(path, Modified, a, b)
+		}
+
+	case reflect.Func:
+		// Like reflect.DeepEqual: funcs are equal only if both are nil.
+		if !a.IsNil() || !b.IsNil() {
+			d.add(path, Modified, a, b)
+		}
+
+	default:
+		// Booleans, integers, strings, channels and unsafe pointers.
+		if !a.Equal(b) {
+			d.add(path, Modified, a, b)
+		}
+	}
+}
+
+func (d *differ) ignore(t reflect.Type, f reflect.StructField) bool {
+	if d.opts.IgnoreUnexported && !f.IsExported() {
+		return true
+	}
+	return slices.Contains(d.opts.IgnoreFields, f.Name) ||
+		t.Name() != "" && slices.Contains(d.opts.IgnoreFields, t.Name()+"."+f.Name)
+}
+
+// readable returns v, a field of an addressable struct, without the
+// read-only mark reflect puts on unexported fields, so that Interface
+// works on it and on everything reached through it.
+func readable(v reflect.Value) reflect.Value {
+	if v.CanInterface() {
+		return v
+	}
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
+
+// addressable returns a copy of v that is addressable, so that the
+// fields of a struct in a map or an interface can be made readable.
+func addressable(v reflect.Value) reflect.Value {
+	if !v.IsValid() || v.CanAddr() {
+		return v
+	}
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	return c
+}
+
+func join(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+func index(path string, i int) string { return path + "[" + strconv.Itoa(i) + "]" }
+
+// WriteText writes one line per change.
+func WriteText(w io.Writer, changes []Change) error {
+	for _, c := range changes {
+		if _, err := fmt.Fprintln(w, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// changeJSON is the JSON form of a Change.
+type changeJSON struct {
+	Path string `json:"path"`
+	Kind Kind   `json:"kind"`
+	Old  any    `json:"old,omitempty"`
+	New  any    `json:"new,omitempty"`
+}
+
+// WriteJSON writes the changes as an indented JSON array. Booleans,
+// numbers and strings are written as JSON values, everything else as the
+// text Format gives it.
+func WriteJSON(w io.Writer, changes []Change) error {
+	out := []changeJSON{}
+	for _, c := range changes {
+		out = append(out, changeJSON{c.Path, c.Kind, jsonValue(c.Old), jsonValue(c.New)})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func jsonValue(x any) any {
+	if x == nil {
+		return nil
+	}
+	switch reflect.TypeOf(x).Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return x
+	case reflect.Float32, reflect.Float64:
+		if f := reflect.ValueOf(x).Float(); !math.IsNaN(f) && !math.IsInf(f, 0) {
+			return x
+		}
+	}
+	return Format(x)
+}
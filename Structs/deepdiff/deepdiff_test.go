@@ -0,0 +1,185 @@
+package deepdiff
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+type person struct {
+	name string
+	age  int
+	pet  string
+}
+
+type team struct {
+	Name    string
+	people  []person
+	lead    *person
+	average float64
+}
+
+// ring is a circular list.
+type ring struct {
+	value int
+	next  *ring
+}
+
+// newRing returns a circular list holding values.
+func newRing(values ...int) *ring {
+	first := &ring{value: values[0]}
+	last := first
+	for _, v := range values[1:] {
+		last.next = &ring{value: v}
+		last = last.next
+	}
+	last.next = first
+	return first
+}
+
+func TestDiff(t *testing.T) {
+	ann := person{name: "Ann", age: 31, pet: "dog"}
+	y, z := []int{1, 2, 3}, []int{1, 2, 4}
+	selfA := []any{nil}
+	selfA[0] = selfA
+	selfB := []any{nil}
+	selfB[0] = selfB
+
+	tests := []struct {
+		name string
+		opts Options
+		a, b any
+		want []string
+	}{
+		{"equal", Options{}, ann, ann, nil},
+		{"unexported field", Options{}, ann, person{name: "Ann", age: 32, pet: "dog"}, []string{"age: 31 -> 32"}},
+		{"root", Options{}, 1, 2, []string{"(value): 1 -> 2"}},
+		{"nil interface", Options{}, nil, 1, []string{"(value): nil -> 1"}},
+		{"type changed", Options{}, []any{1, "x"}, []any{1.0, "x"}, []string{"[0]: type changed: 1 (int) -> 1 (float64)"}},
+		{"slice grows", Options{}, []int{1}, []int{1, 2}, []string{"[1]: added 2"}},
+		{"slice shrinks", Options{}, []int{1, 2}, []int{1}, []string{"[1]: removed 2"}},
+		{"nil and empty slice", Options{}, []int(nil), []int{}, []string{"(value): nil -> []"}},
+		{
+			"map entries", Options{},
+			map[string]int{"ann": 7, "bob": 7},
+			map[string]int{"ann": 9, "cid": 3},
+			[]string{`["ann"]: 7 -> 9`, `["bob"]: removed 7`, `["cid"]: added 3`},
+		},
+		{
+			"NaN map key", Options{},
+			map[float64]string{math.NaN(): "a", 1: "b"},
+			map[float64]string{1: "b"},
+			[]string{`[NaN]: removed "a"`},
+		},
+		{
+			"NaN map key on both sides", Options{},
+			map[float64]string{math.NaN(): "a"},
+			map[float64]string{math.NaN(): "a"},
+			[]string{`[NaN]: removed "a"`, `[NaN]: added "a"`},
+		},
+		{
+			"slices of one array", Options{},
+			[][]int{y[:2], y[:3]},
+			[][]int{z[:2], z[:3]},
+			[]string{"[1][2]: 3 -> 4"},
+		},
+		{
+			"pointer followed", Options{},
+			team{Name: "otters", lead: &ann},
+			team{Name: "otters", lead: &person{name: "Ann", age: 31, pet: "cat"}},
+			[]string{`lead.pet: "dog" -> "cat"`},
+		},
+		{
+			"ignore field", Options{IgnoreFields: []string{"pet"}},
+			[]person{ann, {name: "Bob", pet: "cat"}},
+			[]person{{name: "Ann", age: 31}, {name: "Bob"}},
+			nil,
+		},
+		{
+			"ignore field of one type", Options{IgnoreFields: []string{"team.lead"}},
+			team{lead: &ann, people: []person{ann}},
+			team{lead: &person{}, people: []person{{name: "Ann", age: 31, pet: "cat"}}},
+			[]string{`people[0].pet: "dog" -> "cat"`},
+		},
+		{
+			"ignore unexported", Options{IgnoreUnexported: true},
+			team{Name: "otters", people: []person{ann}, average: 1},
+			team{Name: "owls", average: 2},
+			[]string{`Name: "otters" -> "owls"`},
+		},
+		{"float within tolerance", Options{FloatTolerance: 1e-6}, 35.0, 35.0000001, nil},
+		{"float beyond tolerance", Options{FloatTolerance: 1e-9}, 35.0, 35.0000001, []string{"(value): 35 -> 35.0000001"}},
+		{"float without tolerance", Options{}, 35.0, 35.0000001, []string{"(value): 35 -> 35.0000001"}},
+		{"complex within tolerance", Options{FloatTolerance: 1e-6}, 1 + 2i, 1 + 2.0000001i, nil},
+		{"NaN", Options{FloatTolerance: 1}, math.NaN(), math.NaN(), []string{"(value): NaN -> NaN"}},
+		{"equal cycles", Options{}, newRing(1, 2, 3), newRing(1, 2, 3), nil},
+		{"cycles", Options{}, newRing(1, 2, 3), newRing(1, 20, 3), []string{"next.value: 2 -> 20"}},
+		{"cycles of different lengths", Options{}, newRing(1, 2), newRing(1, 2, 1, 2), nil},
+		{"slice holding itself", Options{}, selfA, selfB, nil},
+		{"funcs", Options{}, []func(){nil}, []func(){func() {}}, []string{"[0]: nil -> func()"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, c := range tt.opts.Diff(tt.a, tt.b) {
+				got = append(got, c.String())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Diff = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// With no options, Diff finds no changes exactly when reflect.DeepEqual
+// reports true.
+func TestDiffAgreesWithDeepEqual(t *testing.T) {
+	y, z := []int{1, 2, 3}, []int{1, 2, 4}
+	pairs := [][2]any{
+		{person{name: "Ann"}, person{name: "Ann"}},
+		{person{name: "Ann"}, person{name: "Bob"}},
+		{[][]int{y[:2], y[:3]}, [][]int{z[:2], z[:3]}},
+		{[][]int{y[:2], y[:2]}, [][]int{z[:2], z[:2]}},
+		{map[float64]int{math.NaN(): 1}, map[float64]int{math.NaN(): 1}},
+		{newRing(1, 2, 3), newRing(1, 2, 3)},
+		{newRing(1, 2, 3), newRing(1, 2, 4)},
+		{[]int(nil), []int{}},
+		{math.NaN(), math.NaN()},
+	}
+	for _, p := range pairs {
+		equal := reflect.DeepEqual(p[0], p[1])
+		if changes := Diff(p[0], p[1]); (len(changes) == 0) != equal {
+			t.Errorf("Diff(%s, %s) = %v, but DeepEqual = %v", Format(p[0]), Format(p[1]), changes, equal)
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	changes := Diff(
+		map[string]float64{"a": 1, "b": math.Inf(1)},
+		map[string]float64{"a": 2},
+	)
+	var got bytes.Buffer
+	if err := WriteJSON(&got, changes); err != nil {
+		t.Fatal(err)
+	}
+	want := `[
+  {
+    "path": "[\"a\"]",
+    "kind": "modified",
+    "old": 1,
+    "new": 2
+  },
+  {
+    "path": "[\"b\"]",
+    "kind": "removed",
+    "old": "+Inf"
+  }
+]
+`
+	if got.String() != want {
+		t.Errorf("WriteJSON =\n%s\nwant\n%s", got.String(), want)
+	}
+}
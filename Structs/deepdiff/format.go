@@ -0,0 +1,119 @@
+package deepdiff
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// Format prints x in the style of %+v, with two differences that keep
+// output the same from run to run: pointers print what they point to
+// (&{...}) instead of an address, and maps print in sorted key order.
+// Strings are quoted. A pointer met again inside itself prints as &<cycle>.
+func Format(x any) string {
+	var b strings.Builder
+	f := formatter{b: &b, seen: map[unsafe.Pointer]bool{}}
+	f.value(addressable(reflect.ValueOf(x)))
+	return b.String()
+}
+
+type formatter struct {
+	b    *strings.Builder
+	seen map[unsafe.Pointer]bool
+}
+
+func (f *formatter) value(v reflect.Value) {
+	b := f.b
+	if !v.IsValid() {
+		b.WriteString("nil")
+		return
+	}
+	switch v.Kind() {
+	case reflect.String:
+		b.WriteString(strconv.Quote(v.String()))
+
+	case reflect.Pointer:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		p := v.UnsafePointer()
+		if f.seen[p] {
+			b.WriteString("&<cycle>")
+			return
+		}
+		f.seen[p] = true
+		b.WriteString("&")
+		f.value(v.Elem())
+		delete(f.seen, p)
+
+	case reflect.Interface:
+		f.value(addressable(v.Elem()))
+
+	case reflect.Struct:
+		b.WriteString("{")
+		for i := range v.NumField() {
+			if i > 0 {
+				b.WriteString(" ")
+			}
+			b.WriteString(v.Type().Field(i).Name + ":")
+			f.value(readable(v.Field(i)))
+		}
+		b.WriteString("}")
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		b.WriteString("[")
+		for i := range v.Len() {
+			if i > 0 {
+				b.WriteString(" ")
+			}
+			f.value(v.Index(i))
+		}
+		b.WriteString("]")
+
+	case reflect.Map:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		type entry struct{ k, v string }
+		var entries []entry
+		for k, e := range v.Seq2() {
+			entries = append(entries, entry{Format(k.Interface()), f.sub(addressable(e))})
+		}
+		slices.SortFunc(entries, func(x, y entry) int { return strings.Compare(x.k, y.k) })
+		b.WriteString("map[")
+		for i, e := range entries {
+			if i > 0 {
+				b.WriteString(" ")
+			}
+			b.WriteString(e.k + ":" + e.v)
+		}
+		b.WriteString("]")
+
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		b.WriteString(v.Type().String()) // an address would change between runs
+
+	default:
+		fmt.Fprint(b, v.Interface())
+	}
+}
+
+// sub formats v on its own, sharing the cycle check.
+func (f *formatter) sub(v reflect.Value) string {
+	var b strings.Builder
+	g := formatter{b: &b, seen: f.seen}
+	g.value(v)
+	return b.String()
+}
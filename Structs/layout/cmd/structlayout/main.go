@@ -0,0 +1,142 @@
+// Command structlayout prints the memory layout of struct types: size,
+// alignment, the offset of each field and the padding between them, for a
+// target GOARCH:
+//
+//	go run ./Structs/layout/cmd/structlayout -dir Structs
+//	go run ./Structs/layout/cmd/structlayout -arch 386 ./...
+//
+// It also works out the field order with the least padding, and can move
+// the fields there:
+//
+//	-fix          print the reordered source instead of the layouts
+//	-w            write the reordered source back to the files
+//	-check n      print nothing but the structs that waste more than n
+//	              bytes, and exit 1 if there are any (for CI)
+//	-type name    only this struct type
+//	-arch goarch  amd64, arm64, 386, ... (default: this machine's)
+//	-dir dir      load the packages from dir
+//
+// Reordering changes the type: structs built with positional literals are
+// never rewritten, and conversions to a struct type with the old order
+// stop compiling. The tool's tests run with go test ./Structs/layout.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+
+	"Lets-GO/Structs/layout"
+)
+
+func main() {
+	arch := flag.String("arch", runtime.GOARCH, "target GOARCH")
+	check := flag.Int("check", -1, "fail if a struct wastes more than this many bytes (-1: off)")
+	fix := flag.Bool("fix", false, "print the source with fields reordered")
+	write := flag.Bool("w", false, "write the reordered source back to the files")
+	typeName := flag.String("type", "", "only the struct type with this name")
+	dir := flag.String("dir", ".", "directory to load packages from")
+	flag.Parse()
+	if *check < -1 {
+		fmt.Fprintln(os.Stderr, "error: -check must be at least 0, or -1 for off")
+		os.Exit(2)
+	}
+
+	code, err := run(*dir, *arch, flag.Args(), *typeName, *check, *fix || *write, *write)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(code)
+}
+
+func run(dir, arch string, patterns []string, typeName string, check int, fix, write bool) (int, error) {
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	prog, err := layout.Analyze(dir, arch, patterns...)
+	if err != nil {
+		return 0, err
+	}
+	base, err := filepath.Abs(dir)
+	if err != nil {
+		return 0, err
+	}
+	structs := prog.Structs
+	if typeName != "" {
+		structs = slices.DeleteFunc(structs, func(s *layout.Struct) bool { return s.Name != typeName })
+		if len(structs) == 0 {
+			return 0, fmt.Errorf("no struct type %s", typeName)
+		}
+	}
+
+	switch {
+	case check >= 0:
+		bad := 0
+		for _, s := range structs {
+			if s.Waste() > int64(check) {
+				bad++
+				rel, _ := filepath.Rel(base, s.Pos.Filename)
+				fmt.Printf("%s:%d: %s wastes %d bytes on %s: size %d, %d in a better order\n",
+					rel, s.Pos.Line, s.Name, s.Waste(), arch, s.Size, s.OptimalSize)
+			}
+		}
+		if bad > 0 {
+			fmt.Fprintf(os.Stderr, "%d struct(s) waste more than %d bytes\n", bad, check)
+			return 1, nil
+		}
+		return 0, nil
+
+	case fix:
+		var fixable []*layout.Struct
+		for _, s := range structs {
+			if s.Order == nil {
+				continue
+			}
+			if err := s.CanRewrite(); err != nil {
+				fmt.Fprintf(os.Stderr, "not moving %s's fields: %v\n", s.Name, err)
+				continue
+			}
+			fixable = append(fixable, s)
+		}
+		files, err := prog.Rewrite(fixable)
+		if err != nil {
+			return 0, err
+		}
+		names := slices.Sorted(func(yield func(string) bool) {
+			for name := range files {
+				if !yield(name) {
+					return
+				}
+			}
+		})
+		for _, name := range names {
+			if write {
+				if err := os.WriteFile(name, files[name], 0o644); err != nil {
+					return 0, err
+				}
+				fmt.Fprintln(os.Stderr, "rewrote", name)
+				continue
+			}
+			if len(names) > 1 {
+				fmt.Printf("==> %s <==\n", name)
+			}
+			os.Stdout.Write(files[name])
+		}
+		return 0, nil
+
+	default:
+		for i, s := range structs {
+			if i > 0 {
+				fmt.Println()
+			}
+			if err := layout.Write(os.Stdout, s, base); err != nil {
+				return 0, err
+			}
+		}
+		return 0, nil
+	}
+}
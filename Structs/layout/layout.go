@@ -0,0 +1,227 @@
+// Package layout shows how the compiler lays out struct types in memory
+// and how much of that memory is padding.
+//
+// Every field sits at an offset that is a multiple of its alignment, so a
+// bool followed by an int64 leaves seven unused bytes between them:
+//
+//	type account struct {
+//		active  bool   // offset 0, then 7 bytes of padding
+//		balance int64  // offset 8
+//		admin   bool   // offset 16, then 7 bytes of padding
+//	}                  // size 24
+//
+// Ordering the fields by decreasing alignment (balance, active, admin)
+// packs them into 16 bytes. Analyze measures every struct type declared
+// in a package with the sizes of a target GOARCH, as go/types computes
+// them for the gc compiler, and works out such an order. Write prints a
+// layout with its padding, and Program.Rewrite reorders fields in the
+// source.
+//
+// Field order is part of a struct type: positional literals like
+// account{true, 10, false} and conversions to types with the old order
+// break when the fields move. Analyze records positional literals in the
+// loaded packages and Rewrite refuses to touch those types.
+package layout
+
+import (
+	"cmp"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Field is one field of a struct and where it sits in memory.
+type Field struct {
+	Name     string // the type name for embedded fields
+	Type     string
+	Embedded bool
+	Offset   int64
+	Size     int64
+	Align    int64
+}
+
+// Struct is the layout of a named struct type on one architecture.
+type Struct struct {
+	Name   string
+	Pos    token.Position
+	Arch   string
+	Word   int64 // pointer size, used to group bytes when drawing
+	Size   int64
+	Align  int64
+	Fields []Field
+
+	// Order lists field indexes in an order with the least padding, and
+	// OptimalSize is the size in that order. Order is nil when the
+	// declared order is already as small.
+	Order       []int
+	OptimalSize int64
+
+	// Unkeyed lists positional composite literals of the type, which
+	// depend on the field order.
+	Unkeyed []token.Position
+
+	fset *token.FileSet
+	spec *ast.TypeSpec
+}
+
+// Padding returns the number of bytes in the struct that belong to no
+// field.
+func (s *Struct) Padding() int64 {
+	n := s.Size
+	for _, f := range s.Fields {
+		n -= f.Size
+	}
+	return n
+}
+
+// Waste returns how many bytes reordering the fields would save.
+func (s *Struct) Waste() int64 { return s.Size - s.OptimalSize }
+
+// Program is the struct layouts of the loaded packages.
+type Program struct {
+	Fset    *token.FileSet
+	Arch    string
+	Structs []*Struct
+}
+
+// Analyze loads the packages matching patterns, with dir as the working
+// directory and files selected for arch, and measures every struct type
+// declared at package level. Generic types are skipped: their layout
+// depends on the type arguments.
+func Analyze(dir, arch string, patterns ...string) (*Program, error) {
+	sizes := types.SizesFor("gc", arch)
+	if sizes == nil {
+		return nil, fmt.Errorf("unknown GOARCH %q", arch)
+	}
+	fset := token.NewFileSet()
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo,
+		Dir:  dir,
+		Env:  append(os.Environ(), "GOARCH="+arch),
+		Fset: fset,
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	var msgs []string
+	packages.Visit(pkgs, nil, func(p *packages.Package) {
+		for _, e := range p.Errors {
+			msgs = append(msgs, e.Error())
+		}
+	})
+	if len(msgs) > 0 {
+		return nil, fmt.Errorf("loading %s:\n%s", strings.Join(patterns, " "), strings.Join(msgs, "\n"))
+	}
+
+	prog := &Program{Fset: fset, Arch: arch}
+	word := sizes.Sizeof(types.Typ[types.UnsafePointer])
+	for _, pkg := range pkgs {
+		byObj := map[*types.TypeName]*Struct{}
+		for _, file := range pkg.Syntax {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					spec := spec.(*ast.TypeSpec)
+					if _, ok := spec.Type.(*ast.StructType); !ok || spec.TypeParams != nil || spec.Assign.IsValid() {
+						continue
+					}
+					obj := pkg.TypesInfo.Defs[spec.Name].(*types.TypeName)
+					s := measure(obj.Type().Underlying().(*types.Struct), sizes, types.RelativeTo(pkg.Types))
+					s.Name = spec.Name.Name
+					s.Pos = fset.Position(spec.Pos())
+					s.Arch = arch
+					s.Word = word
+					s.fset = fset
+					s.spec = spec
+					byObj[obj] = s
+					prog.Structs = append(prog.Structs, s)
+				}
+			}
+		}
+
+		for _, file := range pkg.Syntax {
+			ast.Inspect(file, func(n ast.Node) bool {
+				lit, ok := n.(*ast.CompositeLit)
+				if !ok || len(lit.Elts) == 0 {
+					return true
+				}
+				if _, keyed := lit.Elts[0].(*ast.KeyValueExpr); keyed {
+					return true
+				}
+				if named, ok := types.Unalias(pkg.TypesInfo.TypeOf(lit)).(*types.Named); ok {
+					if s := byObj[named.Obj()]; s != nil {
+						s.Unkeyed = append(s.Unkeyed, fset.Position(lit.Pos()))
+					}
+				}
+				return true
+			})
+		}
+	}
+	return prog, nil
+}
+
+// measure lays out st and finds its best field order.
+func measure(st *types.Struct, sizes types.Sizes, qf types.Qualifier) *Struct {
+	vars := slices.Collect(st.Fields())
+	offsets := sizes.Offsetsof(vars)
+	s := &Struct{
+		Size:  sizes.Sizeof(st),
+		Align: sizes.Alignof(st),
+	}
+	for i, v := range vars {
+		s.Fields = append(s.Fields, Field{
+			Name:     v.Name(),
+			Type:     types.TypeString(v.Type(), qf),
+			Embedded: v.Embedded(),
+			Offset:   offsets[i],
+			Size:     sizes.Sizeof(v.Type()),
+			Align:    sizes.Alignof(v.Type()),
+		})
+	}
+
+	// Every size is a multiple of its alignment, so fields in decreasing
+	// alignment order need no padding between them. Zero-size fields go
+	// first: at the end they would get padding of their own, so that a
+	// pointer to them does not point past the struct. The sort is stable
+	// to move as few fields as possible.
+	order := make([]int, len(vars))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(i, j int) int {
+		fi, fj := s.Fields[i], s.Fields[j]
+		return cmp.Or(
+			cmp.Compare(nonZero(fi), nonZero(fj)),
+			cmp.Compare(fj.Align, fi.Align),
+		)
+	})
+	reordered := make([]*types.Var, len(vars))
+	for i, j := range order {
+		reordered[i] = vars[j]
+	}
+	s.OptimalSize = sizes.Sizeof(types.NewStruct(reordered, nil))
+	if s.OptimalSize < s.Size {
+		s.Order = order
+	} else {
+		s.OptimalSize = s.Size
+	}
+	return s
+}
+
+// nonZero is 0 for zero-size fields and 1 for the rest.
+func nonZero(f Field) int {
+	if f.Size == 0 {
+		return 0
+	}
+	return 1
+}
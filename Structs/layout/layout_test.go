@@ -0,0 +1,118 @@
+package layout
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"unsafe"
+
+	"Lets-GO/Structs/layout/testdata/src/sample"
+)
+
+var update = flag.Bool("update", false, "rewrite the fixture's golden files in testdata")
+
+// compare checks got against testdata/golden, or rewrites it with -update.
+func compare(t *testing.T, got []byte, golden string) {
+	t.Helper()
+	golden = filepath.Join("testdata", golden)
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s:\n--- got\n%s--- want\n%s", golden, got, want)
+	}
+}
+
+// The fixture in testdata/src/fixture must lay out on amd64 and 386
+// exactly as testdata/fixture.amd64.golden and fixture.386.golden.
+func TestGolden(t *testing.T) {
+	dir, err := filepath.Abs(filepath.Join("testdata", "src", "fixture"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, arch := range []string{"amd64", "386"} {
+		t.Run(arch, func(t *testing.T) {
+			prog, err := Analyze(dir, arch, ".")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got bytes.Buffer
+			for i, s := range prog.Structs {
+				if i > 0 {
+					got.WriteString("\n")
+				}
+				if err := Write(&got, s, dir); err != nil {
+					t.Fatal(err)
+				}
+			}
+			compare(t, got.Bytes(), "fixture."+arch+".golden")
+		})
+	}
+}
+
+// Reordering the fixture's structs on amd64 must produce
+// testdata/fixture.rewrite.golden.
+func TestRewrite(t *testing.T) {
+	dir, err := filepath.Abs(filepath.Join("testdata", "src", "fixture"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	prog, err := Analyze(dir, "amd64", ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixable []*Struct
+	for _, s := range prog.Structs {
+		if s.CanRewrite() == nil {
+			fixable = append(fixable, s)
+		}
+	}
+	files, err := prog.Rewrite(fixable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compare(t, files[filepath.Join(dir, "fixture.go")], "fixture.rewrite.golden")
+}
+
+// The layout of sample.Sample computed for this machine's GOARCH must
+// match what the compiler did.
+func TestMatchesCompiler(t *testing.T) {
+	prog, err := Analyze(filepath.Join("testdata", "src", "sample"), runtime.GOARCH, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prog.Structs) != 1 {
+		t.Fatalf("found %d structs, want 1", len(prog.Structs))
+	}
+	s := prog.Structs[0]
+
+	var v sample.Sample
+	want := []uintptr{
+		unsafe.Offsetof(v.Flag),
+		unsafe.Offsetof(v.Count),
+		unsafe.Offsetof(v.Small),
+		unsafe.Offsetof(v.Ptr),
+		unsafe.Offsetof(v.Word),
+		unsafe.Offsetof(v.Tail),
+	}
+	if s.Size != int64(unsafe.Sizeof(v)) || s.Align != int64(unsafe.Alignof(v)) {
+		t.Errorf("size %d align %d, compiler says %d and %d", s.Size, s.Align, unsafe.Sizeof(v), unsafe.Alignof(v))
+	}
+	if len(s.Fields) != len(want) {
+		t.Fatalf("found %d fields, want %d", len(s.Fields), len(want))
+	}
+	for i, f := range s.Fields {
+		if f.Offset != int64(want[i]) {
+			t.Errorf("%s: offset %d, compiler says %d", f.Name, f.Offset, want[i])
+		}
+	}
+}
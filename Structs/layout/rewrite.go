@@ -0,0 +1,143 @@
+package layout
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// CanRewrite reports why Rewrite would not reorder s, or nil if it would.
+func (s *Struct) CanRewrite() error {
+	switch {
+	case s.Order == nil:
+		return errors.New("already in the best order")
+	case len(s.Unkeyed) > 0:
+		pos := s.Unkeyed[0]
+		return fmt.Errorf("positional literals depend on the field order, e.g. at %s:%d", filepath.Base(pos.Filename), pos.Line)
+	}
+	st := s.spec.Type.(*ast.StructType)
+	line := s.fset.Position(st.Fields.Opening).Line
+	for _, f := range st.Fields.List {
+		start, end := s.fset.Position(fieldStart(f)).Line, s.fset.Position(fieldEnd(f)).Line
+		if start <= line {
+			return errors.New("fields share a line with each other or with the braces")
+		}
+		line = end
+	}
+	if s.fset.Position(st.Fields.Closing).Line <= line {
+		return errors.New("the last field shares a line with the closing brace")
+	}
+	return nil
+}
+
+// Rewrite moves the fields of structs into their better order in the
+// source files and returns the new contents of every changed file. A
+// field's doc comment, its line comment and any comment lines above it
+// move with it; a field declaring several names (a, b int) stays one
+// declaration. The result is gofmt'ed. Structs that CanRewrite rejects
+// are an error.
+func (p *Program) Rewrite(structs []*Struct) (map[string][]byte, error) {
+	type edit struct {
+		start, end int
+		text       []byte
+	}
+	edits := map[string][]edit{}
+	for _, s := range structs {
+		if err := s.CanRewrite(); err != nil {
+			return nil, fmt.Errorf("%s: %s: %v", s.Pos, s.Name, err)
+		}
+		src, err := os.ReadFile(s.Pos.Filename)
+		if err != nil {
+			return nil, err
+		}
+		st := s.spec.Type.(*ast.StructType)
+		file := p.Fset.File(st.Pos())
+
+		// Each declaration's text runs from the line after the previous
+		// one to the end of its own last line.
+		var chunks [][]byte
+		var blankBefore []bool
+		from := file.Offset(file.LineStart(file.Line(st.Fields.Opening) + 1))
+		for _, f := range st.Fields.List {
+			line := file.Line(fieldEnd(f))
+			to := len(src)
+			if line < file.LineCount() {
+				to = file.Offset(file.LineStart(line + 1))
+			}
+			chunk := bytes.TrimLeft(src[from:to], "\n")
+			chunks = append(chunks, chunk)
+			blankBefore = append(blankBefore, len(chunk) < to-from)
+			from = to
+		}
+
+		// A declaration that was set off by a blank line stays set off.
+		var text []byte
+		for n, i := range declOrder(st, s.Order) {
+			if n > 0 && blankBefore[i] {
+				text = append(text, '\n')
+			}
+			text = append(text, chunks[i]...)
+		}
+		start := file.Offset(file.LineStart(file.Line(st.Fields.Opening) + 1))
+		edits[s.Pos.Filename] = append(edits[s.Pos.Filename], edit{start, from, text})
+	}
+
+	out := map[string][]byte{}
+	for name, es := range edits {
+		src, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		slices.SortFunc(es, func(a, b edit) int { return cmp.Compare(b.start, a.start) })
+		buf := slices.Clone(src)
+		for _, e := range es {
+			buf = slices.Concat(buf[:e.start], e.text, buf[e.end:])
+		}
+		formatted, err := format.Source(buf)
+		if err != nil {
+			return nil, fmt.Errorf("%s: rewritten source does not parse: %v", name, err)
+		}
+		out[name] = formatted
+	}
+	return out, nil
+}
+
+// declOrder turns an order of fields into an order of the field
+// declarations in st. A declaration of several names has fields of one
+// type, which the stable sort keeps together.
+func declOrder(st *ast.StructType, order []int) []int {
+	var decl []int // field index -> declaration index
+	for i, f := range st.Fields.List {
+		for range max(len(f.Names), 1) {
+			decl = append(decl, i)
+		}
+	}
+	var out []int
+	for _, i := range order {
+		if !slices.Contains(out, decl[i]) {
+			out = append(out, decl[i])
+		}
+	}
+	return out
+}
+
+func fieldStart(f *ast.Field) token.Pos {
+	if f.Doc != nil {
+		return f.Doc.Pos()
+	}
+	return f.Pos()
+}
+
+func fieldEnd(f *ast.Field) token.Pos {
+	if f.Comment != nil {
+		return f.Comment.End()
+	}
+	return f.End()
+}
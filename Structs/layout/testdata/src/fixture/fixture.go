@@ -0,0 +1,71 @@
+// Package fixture is the input of layout's golden tests.
+package fixture
+
+// account pads after each bool.
+type account struct {
+	// active is false for closed accounts.
+	active  bool
+	balance int64 // in cents
+	admin   bool
+}
+
+// packed has no padding.
+type packed struct {
+	id   int64
+	name string
+	ok   bool
+}
+
+// coords declares two fields at once; they move together.
+type coords struct {
+	visible bool
+	id      int64
+
+	// position
+	x, y   int32
+	hidden bool
+}
+
+// zeroTail ends with a zero-size field, which gets padding of its own.
+type zeroTail struct {
+	n   int32
+	end struct{}
+}
+
+type base struct {
+	id int32
+}
+
+// withBase embeds base between two fields.
+type withBase struct {
+	ok bool
+	base
+	total int64
+	done  bool
+}
+
+// point is built with a positional literal below, so its fields must not
+// move.
+type point struct {
+	valid bool
+	x     float64
+	seen  bool
+}
+
+var origin = point{true, 0, false}
+
+// buffer is larger than the byte map draws.
+type buffer struct {
+	dirty bool
+	data  [200]byte
+	n     int64
+}
+
+// pair is generic: its layout depends on the type arguments.
+type pair[K comparable, V any] struct {
+	key K
+	val V
+}
+
+var _ = origin
+var _ pair[int, bool]
@@ -0,0 +1,13 @@
+// Package sample holds a struct that layout's tests measure both with
+// Analyze and, through unsafe, as the compiler laid it out.
+package sample
+
+// Sample's fields are in a wasteful order on purpose.
+type Sample struct {
+	Flag  bool
+	Count int64
+	Small int16
+	Ptr   *int
+	Word  uintptr
+	Tail  struct{}
+}
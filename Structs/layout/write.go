@@ -0,0 +1,113 @@
+package layout
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// maxBytes is how many bytes of a struct the byte map draws.
+const maxBytes = 64
+
+// Write prints the layout of s: a table of fields and padding, a map of
+// its bytes and, when reordering would help, the better order. File names
+// are printed relative to base.
+//
+//	account  fixture.go:9  (amd64)
+//	  size 24, align 8: 14 bytes of padding, 8 saved by reordering
+//	  offset  size  align  field
+//	       0     1      1  active bool
+//	       1     7         (padding)
+//	       8     8      8  balance int64
+//	      16     1      1  admin bool
+//	      17     7         (padding)
+//	  bytes  a....... bbbbbbbb c.......
+//	  better order: balance, active, admin (size 16)
+//
+// In the byte map each field is a letter and each padding byte a dot, in
+// groups of the pointer size.
+func Write(w io.Writer, s *Struct, base string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s  %s  (%s)\n", s.Name, relPos(s, base), s.Arch)
+	fmt.Fprintf(&b, "  size %d, align %d: ", s.Size, s.Align)
+	switch pad := s.Padding(); {
+	case pad == 0:
+		b.WriteString("no padding\n")
+	case s.Order == nil:
+		fmt.Fprintf(&b, "%d bytes of padding, no better order\n", pad)
+	default:
+		fmt.Fprintf(&b, "%d bytes of padding, %d saved by reordering\n", pad, s.Waste())
+	}
+
+	b.WriteString("  offset  size  align  field\n")
+	end := int64(0)
+	for _, f := range s.Fields {
+		if f.Offset > end {
+			fmt.Fprintf(&b, "  %6d  %4d         (padding)\n", end, f.Offset-end)
+		}
+		decl := f.Name + " " + f.Type
+		if f.Embedded {
+			decl = f.Type + " (embedded)"
+		}
+		fmt.Fprintf(&b, "  %6d  %4d  %5d  %s\n", f.Offset, f.Size, f.Align, decl)
+		end = f.Offset + f.Size
+	}
+	if s.Size > end {
+		fmt.Fprintf(&b, "  %6d  %4d         (padding)\n", end, s.Size-end)
+	}
+
+	if s.Size > 0 {
+		fmt.Fprintf(&b, "  bytes  %s\n", byteMap(s))
+	}
+	if s.Order != nil {
+		var names []string
+		for _, i := range s.Order {
+			names = append(names, s.Fields[i].Name)
+		}
+		fmt.Fprintf(&b, "  better order: %s (size %d)\n", strings.Join(names, ", "), s.OptimalSize)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// byteMap draws one character per byte of s: a letter for each field, a
+// dot for padding.
+func byteMap(s *Struct) string {
+	n := min(s.Size, maxBytes)
+	cells := []byte(strings.Repeat(".", int(n)))
+	for i, f := range s.Fields {
+		for off := f.Offset; off < f.Offset+f.Size && off < n; off++ {
+			cells[off] = letter(i)
+		}
+	}
+	var b strings.Builder
+	for i, c := range cells {
+		if i > 0 && int64(i)%s.Word == 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteByte(c)
+	}
+	if s.Size > n {
+		fmt.Fprintf(&b, " ... (%d more bytes)", s.Size-n)
+	}
+	return b.String()
+}
+
+func letter(i int) byte {
+	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	if i < len(letters) {
+		return letters[i]
+	}
+	return '#'
+}
+
+// relPos returns the position of s with its file name relative to base
+// when possible.
+func relPos(s *Struct, base string) string {
+	pos := s.Pos
+	if rel, err := filepath.Rel(base, pos.Filename); err == nil && !strings.HasPrefix(rel, "..") {
+		pos.Filename = rel
+	}
+	return fmt.Sprintf("%s:%d", pos.Filename, pos.Line)
+}
@@ -2,9 +2,18 @@ package main
 
 import (
 	"fmt"
+	"os"
+
+	"Lets-GO/Structs/deepdiff"
 )
 
 // Named struct type
+//
+// person takes 40 bytes on amd64: two 16-byte string headers and an 8-byte
+// int, with no padding between them. See the layout of every struct here,
+// for any GOARCH, with:
+//
+//	go run ./Structs/layout/cmd/structlayout -dir Structs
 type person struct {
 	name string
 	age  int
@@ -43,6 +52,7 @@ func main() {
 	demoFieldAccess()
 	demoAnonymousStructs()
 	demoCompareAndConvert()
+	demoDeepDiff()
 }
 
 // ---------------------------------------------------
@@ -160,7 +170,10 @@ func demoCompareAndConvert() {
 	s2 := secondPerson(f)
 	fmt.Println("s2 (secondPerson converted from f):", s2)
 
-	// Conversions that are NOT allowed (examples shown as comments):
+	// Conversions that are NOT allowed (examples shown as comments).
+	// To see which field breaks each one, run:
+	//
+	//	go run ./Structs/typerel/cmd/typerel -dir Structs firstPerson thirdPerson
 
 	// 1) Different field order:
 	// type thirdPerson struct {
@@ -199,3 +212,79 @@ func demoCompareAndConvert() {
 
 	fmt.Println()
 }
+
+// ---------------------------------------------------
+// 5. What changed? Deep diffs instead of ==
+// ---------------------------------------------------
+
+type team struct {
+	name    string
+	people  []person
+	scores  map[string]int
+	lead    *person
+	average float64
+}
+
+// ring is a circular list: comparing two rings field by field would never
+// end without cycle detection.
+type ring struct {
+	value int
+	next  *ring
+}
+
+// demoDeepDiff compares values that == either cannot compare (slices and
+// maps inside) or can only answer with true or false. deepdiff.Diff walks
+// both values and reports every difference with its path.
+func demoDeepDiff() {
+	fmt.Println("== demoDeepDiff ==")
+
+	before := team{
+		name: "otters",
+		people: []person{
+			{name: "Ann", age: 31, pet: "dog"},
+			{name: "Bob", age: 50},
+			{name: "Cid", age: 24, pet: "cat"},
+		},
+		scores:  map[string]int{"ann": 7, "bob": 7},
+		average: 35.0,
+	}
+	before.lead = &before.people[0]
+
+	// A deep copy, then a few edits.
+	after := before
+	after.people = append([]person(nil), before.people...)
+	after.scores = map[string]int{"ann": 9, "cid": 3}
+	after.people[2].pet = "dog"
+	after.people = append(after.people, person{name: "Eve", age: 30})
+	after.lead = &person{name: "Ann", age: 32, pet: "dog"}
+	after.average = 35.0000001
+
+	// before == after does not compile: team holds a slice and a map.
+	fmt.Println("\nDiff(before, after):")
+	changes := deepdiff.Diff(before, after)
+	deepdiff.WriteText(os.Stdout, changes)
+
+	// Options: skip fields and ignore float noise.
+	opts := deepdiff.Options{IgnoreFields: []string{"pet", "team.lead"}, FloatTolerance: 1e-6}
+	fmt.Println("\nignoring pet and team.lead, floats within 1e-6:")
+	deepdiff.WriteText(os.Stdout, opts.Diff(before, after))
+
+	// The first person only, as JSON.
+	fmt.Println("\nDiff(before.people[0], *after.lead) as JSON:")
+	deepdiff.WriteJSON(os.Stdout, deepdiff.Diff(before.people[0], *after.lead))
+
+	// Cycles: a ring of three against a ring of three with one change.
+	a3 := &ring{value: 3}
+	a := &ring{value: 1, next: &ring{value: 2, next: a3}}
+	a3.next = a
+	b3 := &ring{value: 3}
+	b := &ring{value: 1, next: &ring{value: 20, next: b3}}
+	b3.next = b
+	fmt.Println("\ntwo circular lists:")
+	deepdiff.WriteText(os.Stdout, deepdiff.Diff(a, b))
+
+	// Interfaces holding different types.
+	fmt.Println("\ninterface values:")
+	deepdiff.WriteText(os.Stdout, deepdiff.Diff([]any{1, "x", nil}, []any{1.0, "x", 2}))
+	fmt.Println()
+}
@@ -0,0 +1,69 @@
+// Command typerel explains whether two types are identical, assignable,
+// convertible and comparable, and why not:
+//
+//	go run ./Structs/typerel/cmd/typerel -dir Structs firstPerson thirdPerson
+//
+// Types are looked up in the package in -dir (or the one matching -pkg
+// there). Any type expression works, so anonymous structs and pointers can
+// be compared too:
+//
+//	go run ./Structs/typerel/cmd/typerel -dir Structs firstPerson 'struct{ name string; age int }'
+//	go run ./Structs/typerel/cmd/typerel -dir Structs '*firstPerson' '*thirdPerson'
+//
+// More than two types explains every pair of neighbours: X Y Z explains X
+// vs Y and Y vs Z.
+//
+// The explanations' tests run with go test ./Structs/typerel.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/types"
+	"os"
+
+	"Lets-GO/Structs/typerel"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "directory to load the package from")
+	pattern := flag.String("pkg", ".", "package pattern, relative to -dir")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: typerel [-dir dir] [-pkg pattern] X Y [Z...]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() < 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*dir, *pattern, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(dir, pattern string, exprs []string) error {
+	pkg, err := typerel.Load(dir, pattern)
+	if err != nil {
+		return err
+	}
+	var ts []types.Type
+	for _, e := range exprs {
+		t, err := pkg.Lookup(e)
+		if err != nil {
+			return err
+		}
+		ts = append(ts, t)
+	}
+	for i := 1; i < len(ts); i++ {
+		if i > 1 {
+			fmt.Println()
+		}
+		if err := typerel.Explain(ts[i-1], ts[i], pkg.Qualifier()).Write(os.Stdout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Package people is the fixture for typerel's tests: the struct types
+// from the Structs lesson plus tags, embedding, incomparable fields and
+// interfaces.
+package people
+
+import "fmt"
+
+type firstPerson struct {
+	name string
+	age  int
+}
+
+type secondPerson struct {
+	name string
+	age  int
+}
+
+type thirdPerson struct {
+	age  int
+	name string
+}
+
+type fourthPerson struct {
+	firstName string
+	age       int
+}
+
+type fifthPerson struct {
+	name          string
+	age           int
+	favoriteColor string
+}
+
+// Same fields as firstPerson, with tags: conversions ignore them.
+type taggedPerson struct {
+	name string `json:"name"`
+	age  int    `json:"age"`
+}
+
+type base struct {
+	id int
+}
+
+type embedsBase struct {
+	base
+	name string
+}
+
+type namesBase struct {
+	base base
+	name string
+}
+
+// A slice field makes a struct incomparable.
+type petOwner struct {
+	name string
+	pets []string
+}
+
+type otherPetOwner struct {
+	name string
+	pets []string
+}
+
+type named interface {
+	Name() string
+}
+
+func (p firstPerson) Name() string { return p.name }
+
+func (p *secondPerson) Name() string { return p.name }
+
+// personAlias is another name for firstPerson, not a new type.
+type personAlias = firstPerson
+
+// fmt is imported so that fmt.Stringer can be looked up.
+var _ fmt.Stringer
@@ -0,0 +1,508 @@
+// Package typerel explains how two Go types relate. The Structs lesson
+// converts firstPerson to secondPerson but not to thirdPerson, fourthPerson
+// or fifthPerson, and the compiler's only explanation is "cannot convert".
+// Explain answers four questions about a pair of types X and Y:
+//
+//   - identical:   are X and Y the same type?
+//   - assignable:  can a value of type X be assigned to a variable of type Y?
+//   - convertible: is Y(x) allowed for a value x of type X?
+//   - comparable:  does x == y compile for values of the two types?
+//
+// Each "no" comes with the reason, and when both types are structs (or
+// unnamed pointers to structs) with a field-by-field diff that points at
+// the first mismatch:
+//
+//	firstPerson vs thirdPerson
+//	  identical    no   firstPerson and thirdPerson are different named types
+//	  assignable   no   underlying types differ: field 0: names differ (name vs age)
+//	  convertible  no   underlying types differ: field 0: names differ (name vs age)
+//	  comparable   no   neither type is assignable to the other, so == does not compile
+//
+//	  field  firstPerson  thirdPerson
+//	  0      name string  age int      names differ (name vs age)  <- first mismatch
+//	  1      age int      name string  names differ (age vs name)
+//	  same fields in a different order: the order is part of a struct type
+//
+// Load and Package.Lookup find the types in a package; the answers
+// themselves come from go/types, so they are exactly the compiler's.
+package typerel
+
+import (
+	"cmp"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Relation is one of the questions Explain answers.
+type Relation int
+
+const (
+	Identical Relation = iota
+	Assignable
+	Convertible
+	Comparable
+)
+
+var relationNames = [...]string{
+	Identical:   "identical",
+	Assignable:  "assignable",
+	Convertible: "convertible",
+	Comparable:  "comparable",
+}
+
+func (r Relation) String() string { return relationNames[r] }
+
+// Answer is the verdict for one relation.
+type Answer struct {
+	Relation Relation
+	OK       bool
+	Why      string
+}
+
+// Field is one row of a field-by-field diff of two struct types.
+type Field struct {
+	Index   int
+	X, Y    string // "name string", "embedded Base", or "" when the struct has no field Index
+	Problem string // "" when the fields match
+	TagOnly bool   // the fields differ only in struct tags, which conversions ignore
+}
+
+// Report is everything Explain found out about a pair of types.
+type Report struct {
+	X, Y    types.Type
+	Answers [4]Answer // indexed by Relation
+	Fields  []Field   // nil unless both types are structs or pointers to structs
+	First   int       // index into Fields of the first mismatch, -1 if none
+	Note    string    // a hint about the diff as a whole, e.g. reordered fields
+	qf      types.Qualifier
+}
+
+// Explain reports how x relates to y. Assignability and convertibility are
+// from x to y. qf controls how type names are printed; nil qualifies them
+// with their full package path.
+func Explain(x, y types.Type, qf types.Qualifier) *Report {
+	r := &Report{X: x, Y: y, First: -1, qf: qf}
+	if sx, sy, ok := structs(x, y); ok {
+		r.Fields, r.Note = diffFields(sx, sy, r.name(x), r.name(y), qf)
+		r.First = slices.IndexFunc(r.Fields, func(f Field) bool { return f.Problem != "" })
+	}
+	r.Answers[Identical] = r.identical()
+	r.Answers[Assignable] = r.assignable()
+	r.Answers[Convertible] = r.convertible()
+	r.Answers[Comparable] = r.comparable()
+	return r
+}
+
+func (r *Report) name(t types.Type) string { return types.TypeString(t, r.qf) }
+
+func (r *Report) identical() Answer {
+	a := Answer{Relation: Identical}
+	x, y := r.X, r.Y
+	switch {
+	case types.Identical(x, y):
+		a.OK, a.Why = true, fmt.Sprintf("%s and %s are the same type", r.name(x), r.name(y))
+	default:
+		a.Why = r.differ(x, y, r.name(x), r.name(y), true)
+	}
+	return a
+}
+
+func (r *Report) assignable() Answer {
+	a := Answer{Relation: Assignable}
+	x, y := r.X, r.Y
+	xn, yn := r.name(x), r.name(y)
+	ux, uy := x.Underlying(), y.Underlying()
+	if types.AssignableTo(x, y) {
+		a.OK = true
+		switch {
+		case types.Identical(x, y):
+			a.Why = "identical types"
+		case types.IsInterface(y):
+			a.Why = fmt.Sprintf("%s implements %s", r.name(x), r.name(y))
+		case types.Identical(ux, uy):
+			a.Why = "identical underlying types, and at least one of the two is not a named type"
+		default:
+			a.Why = "allowed by Go's assignability rules"
+		}
+		return a
+	}
+	switch {
+	case types.IsInterface(y):
+		iface := uy.(*types.Interface)
+		m, wrongType := types.MissingMethod(x, iface, true)
+		switch {
+		case m == nil:
+			a.Why = fmt.Sprintf("%s does not satisfy %s", r.name(x), r.name(y))
+		case !isPointer(x) && types.Implements(types.NewPointer(x), iface):
+			a.Why = fmt.Sprintf("method %s has a pointer receiver, so only *%s implements %s", m.Name(), r.name(x), r.name(y))
+		case wrongType:
+			a.Why = fmt.Sprintf("%s does not implement %s (method %s has the wrong signature)", r.name(x), r.name(y), m.Name())
+		default:
+			a.Why = fmt.Sprintf("%s does not implement %s (missing method %s)", r.name(x), r.name(y), m.Name())
+		}
+	case types.Identical(ux, uy):
+		a.Why = fmt.Sprintf("both are named types; the underlying types are identical, so convert with %s(v)", r.name(y))
+	case !isNamed(x) && !isNamed(y):
+		a.Why = r.differ(x, y, xn, yn, true)
+	default:
+		a.Why = "underlying types differ: " + r.differ(ux, uy, xn, yn, true)
+	}
+	return a
+}
+
+func (r *Report) convertible() Answer {
+	a := Answer{Relation: Convertible}
+	x, y := r.X, r.Y
+	xn, yn := r.name(x), r.name(y)
+	ux, uy := x.Underlying(), y.Underlying()
+	if types.ConvertibleTo(x, y) {
+		a.OK = true
+		switch {
+		case types.AssignableTo(x, y):
+			a.Why = "assignable, so convertible"
+		case types.IdenticalIgnoreTags(ux, uy):
+			a.Why = "identical underlying types, ignoring struct tags"
+		case isPointer(x) && isPointer(y):
+			a.Why = "unnamed pointers to types with identical underlying types, ignoring struct tags"
+		default:
+			a.Why = "allowed by Go's conversion rules"
+		}
+		return a
+	}
+	if isPointer(x) && isPointer(y) {
+		bx := ux.(*types.Pointer).Elem()
+		by := uy.(*types.Pointer).Elem()
+		a.Why = "the pointed-to types' underlying types differ: " + r.differ(bx.Underlying(), by.Underlying(), r.name(bx), r.name(by), false)
+		return a
+	}
+	a.Why = "underlying types differ: " + r.differ(ux, uy, xn, yn, false)
+	return a
+}
+
+func (r *Report) comparable() Answer {
+	a := Answer{Relation: Comparable}
+	x, y := r.X, r.Y
+	for _, t := range []types.Type{x, y} {
+		if !types.Comparable(t) {
+			a.Why = fmt.Sprintf("%s is not comparable: %s", r.name(t), r.incomparable(t))
+			return a
+		}
+	}
+	switch {
+	case types.AssignableTo(x, y) || types.AssignableTo(y, x):
+		a.OK, a.Why = true, "both types are comparable and one is assignable to the other"
+	case types.ConvertibleTo(x, y):
+		conv := r.name(y)
+		if isPointer(y) {
+			conv = "(" + conv + ")"
+		}
+		a.Why = fmt.Sprintf("neither type is assignable to the other, so == does not compile; compare %s(x) == y", conv)
+	default:
+		a.Why = "neither type is assignable to the other, so == does not compile"
+	}
+	return a
+}
+
+// differ describes how x and y, which are not identical, differ: the
+// first mismatching field of two structs, or the part of two composite
+// types that differs. tags says whether struct tags count. xName and yName
+// name x and y in the description.
+func (r *Report) differ(x, y types.Type, xName, yName string, tags bool) string {
+	elem := func(what string, ex, ey types.Type) string {
+		return what + " types differ: " + r.differ(ex, ey, r.name(ex), r.name(ey), tags)
+	}
+	switch {
+	case isNamed(x) && isNamed(y):
+		return fmt.Sprintf("%s and %s are different named types", xName, yName)
+	case isNamed(x):
+		return fmt.Sprintf("%s is a named type and %s is not", xName, yName)
+	case isNamed(y):
+		return fmt.Sprintf("%s is not a named type and %s is", xName, yName)
+	}
+	switch x := types.Unalias(x).(type) {
+	case *types.Pointer:
+		if y, ok := types.Unalias(y).(*types.Pointer); ok {
+			return elem("pointed-to", x.Elem(), y.Elem())
+		}
+	case *types.Slice:
+		if y, ok := types.Unalias(y).(*types.Slice); ok {
+			return elem("element", x.Elem(), y.Elem())
+		}
+	case *types.Array:
+		if y, ok := types.Unalias(y).(*types.Array); ok {
+			if x.Len() != y.Len() {
+				return fmt.Sprintf("array lengths differ (%d vs %d)", x.Len(), y.Len())
+			}
+			return elem("element", x.Elem(), y.Elem())
+		}
+	case *types.Map:
+		if y, ok := types.Unalias(y).(*types.Map); ok {
+			if !identical(x.Key(), y.Key(), tags) {
+				return elem("key", x.Key(), y.Key())
+			}
+			return elem("value", x.Elem(), y.Elem())
+		}
+	case *types.Struct:
+		if y, ok := types.Unalias(y).(*types.Struct); ok {
+			fields, _ := diffFields(x, y, xName, yName, r.qf)
+			for _, f := range fields {
+				if f.Problem != "" && (tags || !f.TagOnly) {
+					return fmt.Sprintf("field %d: %s", f.Index, f.Problem)
+				}
+			}
+		}
+	}
+	return fmt.Sprintf("%s vs %s", r.name(x), r.name(y))
+}
+
+func identical(x, y types.Type, tags bool) bool {
+	if tags {
+		return types.Identical(x, y)
+	}
+	return types.IdenticalIgnoreTags(x, y)
+}
+
+// incomparable says why t, which is not comparable, is not.
+func (r *Report) incomparable(t types.Type) string {
+	switch u := t.Underlying().(type) {
+	case *types.Slice:
+		return "slices are not comparable"
+	case *types.Map:
+		return "maps are not comparable"
+	case *types.Signature:
+		return "funcs are not comparable"
+	case *types.Array:
+		return fmt.Sprintf("its elements are %s, which is not comparable", r.name(u.Elem()))
+	case *types.Struct:
+		for f := range u.Fields() {
+			if !types.Comparable(f.Type()) {
+				return fmt.Sprintf("field %s has type %s, which is not comparable", f.Name(), r.name(f.Type()))
+			}
+		}
+	}
+	return "Go's comparability rules forbid it"
+}
+
+// structs returns the struct types to diff for x and y: their underlying
+// types, or for two unnamed pointers the underlying types of what they
+// point to, as the conversion rules compare those.
+func structs(x, y types.Type) (sx, sy *types.Struct, ok bool) {
+	if px, ok := types.Unalias(x).(*types.Pointer); ok {
+		if py, ok := types.Unalias(y).(*types.Pointer); ok {
+			x, y = px.Elem(), py.Elem()
+		}
+	}
+	sx, okx := x.Underlying().(*types.Struct)
+	sy, oky := y.Underlying().(*types.Struct)
+	return sx, sy, okx && oky
+}
+
+// diffFields compares two struct types field by field. xName and yName
+// are used in the descriptions of fields only one side has.
+func diffFields(sx, sy *types.Struct, xName, yName string, qf types.Qualifier) (fields []Field, note string) {
+	for i := range max(sx.NumFields(), sy.NumFields()) {
+		f := Field{Index: i}
+		var fx, fy *types.Var
+		var tx, ty string
+		if i < sx.NumFields() {
+			fx, tx = sx.Field(i), sx.Tag(i)
+			f.X = describe(fx, tx, qf)
+		}
+		if i < sy.NumFields() {
+			fy, ty = sy.Field(i), sy.Tag(i)
+			f.Y = describe(fy, ty, qf)
+		}
+		switch {
+		case fy == nil:
+			f.Problem = fmt.Sprintf("only %s has field %s", xName, fx.Name())
+		case fx == nil:
+			f.Problem = fmt.Sprintf("only %s has field %s", yName, fy.Name())
+		case fx.Embedded() != fy.Embedded():
+			embedded, plain := xName, yName
+			if fy.Embedded() {
+				embedded, plain = yName, xName
+			}
+			f.Problem = fmt.Sprintf("%s embeds %s, %s has a named field", embedded, fx.Name(), plain)
+		case fx.Name() != fy.Name():
+			f.Problem = fmt.Sprintf("names differ (%s vs %s)", fx.Name(), fy.Name())
+		case !fx.Exported() && fx.Pkg() != fy.Pkg():
+			f.Problem = fmt.Sprintf("unexported field %s is declared in different packages", fx.Name())
+		case !types.IdenticalIgnoreTags(fx.Type(), fy.Type()):
+			f.Problem = fmt.Sprintf("types differ (%s vs %s)", types.TypeString(fx.Type(), qf), types.TypeString(fy.Type(), qf))
+		case tx != ty:
+			f.Problem = fmt.Sprintf("tags differ (%q vs %q)", tx, ty)
+			f.TagOnly = true
+		case !types.Identical(fx.Type(), fy.Type()):
+			f.Problem = "tags inside the field types differ"
+			f.TagOnly = true
+		}
+		fields = append(fields, f)
+	}
+	if reordered(sx, sy) {
+		note = "same fields in a different order: the order is part of a struct type"
+	}
+	return fields, note
+}
+
+// reordered reports whether sx and sy have the same fields, but not in the
+// same order.
+func reordered(sx, sy *types.Struct) bool {
+	if sx.NumFields() != sy.NumFields() {
+		return false
+	}
+	same := true
+	for i := range sx.NumFields() {
+		fx := sx.Field(i)
+		j := slices.IndexFunc(slices.Collect(sy.Fields()), func(fy *types.Var) bool {
+			return fx.Name() == fy.Name() && fx.Embedded() == fy.Embedded() && types.Identical(fx.Type(), fy.Type())
+		})
+		if j < 0 {
+			return false
+		}
+		same = same && i == j
+	}
+	return !same
+}
+
+func describe(f *types.Var, tag string, qf types.Qualifier) string {
+	s := f.Name() + " " + types.TypeString(f.Type(), qf)
+	if f.Embedded() {
+		s = "embedded " + types.TypeString(f.Type(), qf)
+	}
+	if tag != "" {
+		s += " `" + tag + "`"
+	}
+	return s
+}
+
+func isNamed(t types.Type) bool {
+	switch types.Unalias(t).(type) {
+	case *types.Named, *types.Basic, *types.TypeParam:
+		return true
+	}
+	return false
+}
+
+func isPointer(t types.Type) bool {
+	_, ok := types.Unalias(t).(*types.Pointer)
+	return ok
+}
+
+// Write prints the report: one line per relation, then the field diff if
+// there is one and the types are not identical.
+func (r *Report) Write(w io.Writer) error {
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s vs %s\n", r.name(r.X), r.name(r.Y))
+	for _, a := range r.Answers {
+		verdict := "no"
+		if a.OK {
+			verdict = "yes"
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", a.Relation, verdict, a.Why)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if r.Fields != nil && !r.Answers[Identical].OK {
+		r.writeFields(&b)
+	}
+
+	// tabwriter pads every cell, including the last one on a line.
+	for line := range strings.Lines(b.String()) {
+		if _, err := io.WriteString(w, strings.TrimRight(line, " \n")+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Report) writeFields(b *strings.Builder) {
+	b.WriteString("\n")
+	tw := tabwriter.NewWriter(b, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "  field\t%s\t%s\t\n", r.name(r.X), r.name(r.Y))
+	for i, f := range r.Fields {
+		problem := f.Problem
+		if i == r.First {
+			problem += "  <- first mismatch"
+		}
+		fmt.Fprintf(tw, "  %d\t%s\t%s\t%s\n", f.Index, dash(f.X), dash(f.Y), problem)
+	}
+	tw.Flush()
+	if r.Note != "" {
+		fmt.Fprintf(b, "  %s\n", r.Note)
+	}
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// Package is a type-checked package to look types up in.
+type Package struct {
+	Fset  *token.FileSet
+	Types *types.Package
+	Files []*ast.File
+}
+
+// Load type-checks the package matching pattern, with dir as the working
+// directory.
+func Load(dir, pattern string) (*Package, error) {
+	fset := token.NewFileSet()
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo,
+		Dir:  dir,
+		Fset: fset,
+	}
+	pkgs, err := packages.Load(cfg, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("%s matches %d packages, want 1", pattern, len(pkgs))
+	}
+	pkg := pkgs[0]
+	if len(pkg.Errors) > 0 {
+		var msgs []string
+		for _, e := range pkg.Errors {
+			msgs = append(msgs, e.Error())
+		}
+		return nil, fmt.Errorf("loading %s:\n%s", pattern, strings.Join(msgs, "\n"))
+	}
+	return &Package{fset, pkg.Types, pkg.Syntax}, nil
+}
+
+// Lookup evaluates a type expression: a type name such as firstPerson, a
+// type from an imported package such as fmt.Stringer, or any type literal
+// built from those, such as *firstPerson or struct{ name string; age int }.
+// Imports are those of the package's files; the first file that resolves
+// expr wins.
+func (p *Package) Lookup(expr string) (types.Type, error) {
+	var first error
+	for _, f := range p.Files {
+		tv, err := types.Eval(p.Fset, p.Types, f.Package, expr)
+		if err != nil {
+			first = cmp.Or(first, err)
+			continue
+		}
+		if !tv.IsType() {
+			return nil, fmt.Errorf("%s is not a type", expr)
+		}
+		return tv.Type, nil
+	}
+	return nil, cmp.Or(first, fmt.Errorf("%s: package %s has no files", expr, p.Types.Path()))
+}
+
+// Qualifier prints the package's own types unqualified.
+func (p *Package) Qualifier() types.Qualifier { return types.RelativeTo(p.Types) }
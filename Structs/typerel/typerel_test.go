@@ -0,0 +1,109 @@
+package typerel
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/people.golden")
+
+// pairs are the type pairs people.golden explains.
+var pairs = [][2]string{
+	{"firstPerson", "secondPerson"},
+	{"firstPerson", "thirdPerson"},
+	{"firstPerson", "fourthPerson"},
+	{"firstPerson", "fifthPerson"},
+	{"fifthPerson", "firstPerson"},
+	{"firstPerson", "struct{ name string; age int }"},
+	{"firstPerson", "personAlias"},
+	{"firstPerson", "taggedPerson"},
+	{"*firstPerson", "*taggedPerson"},
+	{"*firstPerson", "*thirdPerson"},
+	{"embedsBase", "namesBase"},
+	{"petOwner", "otherPetOwner"},
+	{"firstPerson", "named"},
+	{"secondPerson", "named"},
+	{"*secondPerson", "named"},
+	{"firstPerson", "fmt.Stringer"},
+}
+
+// Explaining pairs in the fixture package testdata/src/people must print
+// exactly testdata/people.golden.
+func TestGolden(t *testing.T) {
+	pkg, err := Load(filepath.Join("testdata", "src", "people"), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got bytes.Buffer
+	for i, p := range pairs {
+		x, err := pkg.Lookup(p[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		y, err := pkg.Lookup(p[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i > 0 {
+			got.WriteString("\n")
+		}
+		if err := Explain(x, y, pkg.Qualifier()).Write(&got); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	golden := filepath.Join("testdata", "people.golden")
+	if *update {
+		if err := os.WriteFile(golden, got.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Bytes(), want) {
+		t.Errorf("explanations differ from %s:\n--- got\n%s--- want\n%s", golden, got.Bytes(), want)
+	}
+}
+
+// The Structs lesson's own types must relate the way its comments say:
+// firstPerson converts to secondPerson and to the anonymous struct, but
+// not to thirdPerson, fourthPerson or fifthPerson.
+func TestLesson(t *testing.T) {
+	pkg, err := Load("..", ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := pkg.Lookup("firstPerson")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		y           string
+		convertible bool
+		first       int // index of the first mismatching field, -1 for none
+	}{
+		{"secondPerson", true, -1},
+		{"struct{ name string; age int }", true, -1},
+		{"thirdPerson", false, 0},
+		{"fourthPerson", false, 0},
+		{"fifthPerson", false, 2},
+	}
+	for _, tt := range tests {
+		y, err := pkg.Lookup(tt.y)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := Explain(x, y, pkg.Qualifier())
+		if got := r.Answers[Convertible].OK; got != tt.convertible {
+			t.Errorf("firstPerson to %s: convertible = %v, want %v", tt.y, got, tt.convertible)
+		}
+		if r.First != tt.first {
+			t.Errorf("firstPerson vs %s: first mismatch at field %d, want %d", tt.y, r.First, tt.first)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Package stats holds the few statistical helpers the lessons' analysis
+// tools share: chi-square goodness-of-fit terms and p-values.
+package stats
+
+import (
+	"math"
+	"strconv"
+)
+
+// maxIterations bounds the series and continued fraction below. Both
+// converge in O(sqrt(a)) steps, so this covers millions of degrees of
+// freedom.
+const maxIterations = 100000
+
+// ChiTerm returns (observed-expected)²/expected.
+func ChiTerm(observed, expected float64) float64 {
+	if expected == 0 {
+		return 0
+	}
+	d := observed - expected
+	return d * d / expected
+}
+
+// ChiSquareP returns P(X >= x) for a chi-square distribution with df
+// degrees of freedom.
+func ChiSquareP(x float64, df int) float64 {
+	if df <= 0 {
+		return math.NaN()
+	}
+	return upperIncompleteGamma(float64(df)/2, x/2)
+}
+
+// upperIncompleteGamma returns the regularized upper incomplete gamma
+// function Q(a, x), using the series for x < a+1 and a continued fraction
+// otherwise (Numerical Recipes, section 6.2).
+func upperIncompleteGamma(a, x float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+	lg, _ := math.Lgamma(a)
+	if x < a+1 {
+		sum, term := 1/a, 1/a
+		for n := 1.0; n < maxIterations; n++ {
+			term *= x / (a + n)
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*1e-15 {
+				break
+			}
+		}
+		return 1 - sum*math.Exp(-x+a*math.Log(x)-lg)
+	}
+
+	const tiny = 1e-300
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1.0; i < maxIterations; i++ {
+		an := -i * (i - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < 1e-15 {
+			break
+		}
+	}
+	return math.Exp(-x+a*math.Log(x)-lg) * h
+}
+
+// FormatP formats a p-value for a report column.
+func FormatP(p float64) string {
+	switch {
+	case math.IsNaN(p):
+		return "-"
+	case p < 1e-6:
+		return "<1e-6"
+	default:
+		return strconv.FormatFloat(p, 'f', 4, 64)
+	}
+}